@@ -8,6 +8,7 @@ import (
 	"github.com/lib/pq"
 	db "github.com/malcolmmaima/maimabank/db/sqlc"
 	"github.com/malcolmmaima/maimabank/token"
+	"github.com/malcolmmaima/maimabank/util"
 )
 
 type createAccountRequest struct {
@@ -29,7 +30,7 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		Balance: 0,
 	}
 
-	account, err := server.store.CreateAccount(ctx, arg)
+	account, err := server.store.CreateAccountTx(ctx, arg)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
@@ -60,24 +61,35 @@ func (server *Server) getAccount(ctx *gin.Context) {
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
 		} 
 
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if account.Owner != authPayload.Username {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if !server.authorizeAccount(ctx, account, util.AccountPermissionView, 0) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	pots, err := server.store.ListPots(ctx, account.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accountResponse{Account: account, Pots: pots})
+}
+
+// an account with the pots money has been set aside in, which isn't part of its balance
+type accountResponse struct {
+	db.Account
+	Pots []db.Pot `json:"pots"`
 }
 
 type listAccountsRequest struct {
-	PageID  int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+	pageRequest
+	Currency string `form:"currency" binding:"omitempty,currency"`
 }
 
 func (server *Server) listAccounts(ctx *gin.Context) {
@@ -87,11 +99,19 @@ func (server *Server) listAccounts(ctx *gin.Context) {
 		return
 	}
 
+	page, err := req.page()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	arg := db.ListAccountsParams{
-		Owner: authPayload.Username,
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+		Username:   authPayload.Username,
+		Currency:   req.Currency,
+		AfterID:    page.AfterID,
+		Descending: page.Descending,
+		PageLimit:  page.limit(),
 	}
 
 	accounts, err := server.store.ListAccounts(ctx, arg)
@@ -100,5 +120,7 @@ func (server *Server) listAccounts(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	n, nextCursor := page.next(len(accounts), func(i int) int64 { return accounts[i].ID })
+	setNextCursor(ctx, nextCursor)
+	ctx.JSON(http.StatusOK, accounts[:n])
 }
\ No newline at end of file
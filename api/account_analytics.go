@@ -0,0 +1,114 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// analytics compare a period with the one before it, so they read up to twice as far back
+const maxAnalyticsDays = 366
+
+// counterparties listed unless the client asks for a number
+const defaultTopCounterparties = 10
+
+type getAccountAnalyticsRequest struct {
+	StartDate time.Time `form:"start_date" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	// inclusive
+	EndDate time.Time `form:"end_date" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	// converted totals are in this currency, the account's own when left out
+	Currency          string `form:"currency" binding:"omitempty,currency"`
+	TopCounterparties int32  `form:"top_counterparties" binding:"omitempty,min=1,max=50"`
+}
+
+// where the money of an account went and came from over a period, per category, counterparty
+// and month, with the change against the period of the same length before it
+func (server *Server) getAccountAnalytics(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req getAccountAnalyticsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.StartDate.After(req.EndDate) {
+		err := errors.New("start_date cannot be greater than end_date")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	until := req.EndDate.AddDate(0, 0, 1)
+	if until.After(req.StartDate.AddDate(0, 0, maxAnalyticsDays)) {
+		err := fmt.Errorf("analytics can cover at most %d days", maxAnalyticsDays)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, util.AccountPermissionView, 0) {
+		return
+	}
+
+	currency := req.Currency
+	if currency == "" {
+		currency = account.Currency
+	}
+
+	// the queries convert with exchange_rates themselves, a missing rate would make every converted amount 0
+	if currency != account.Currency {
+		_, err := server.store.GetExchangeRate(ctx, db.GetExchangeRateParams{
+			BaseCurrency:   account.Currency,
+			TargetCurrency: currency,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				err := errors.New("exchange rate not found")
+				ctx.JSON(http.StatusNotFound, errorResponse(err))
+				return
+			}
+
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	topCounterparties := req.TopCounterparties
+	if topCounterparties == 0 {
+		topCounterparties = defaultTopCounterparties
+	}
+
+	analytics, err := server.store.AccountAnalytics(ctx, db.AccountAnalyticsParams{
+		AccountID:         account.ID,
+		Currency:          currency,
+		Since:             req.StartDate,
+		Until:             until,
+		TopCounterparties: topCounterparties,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, analytics)
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/malcolmmaima/maimabank/db/mock"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountAnalyticsAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	account := createRandomAccount(user1.Username)
+	account.Currency = util.USD
+
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	analytics := db.AccountAnalytics{
+		AccountID:      account.ID,
+		Currency:       util.USD,
+		ReportCurrency: util.USD,
+		Since:          since,
+		Until:          until,
+		PreviousSince:  time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC),
+		Totals:         db.GetAnalyticsTotalsRow{Incoming: 100, Outgoing: 67, PreviousOutgoing: 30, Movements: 4},
+		Categories: []db.ListCategoryAnalyticsRow{
+			{Category: util.CategoryRent, Direction: "debit", Amount: 50, PreviousAmount: 30, Change: 20, Movements: 1},
+		},
+		Counterparties: []db.ListCounterpartyAnalyticsRow{},
+		Months:         []db.ListMonthlyAnalyticsRow{{Month: since, Incoming: 100, Outgoing: 67, Movements: 4}},
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user1.Username,
+			query:    "start_date=2024-03-01&end_date=2024-03-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(0)
+				arg := db.AccountAnalyticsParams{
+					AccountID:         account.ID,
+					Currency:          util.USD,
+					Since:             since,
+					Until:             until,
+					TopCounterparties: defaultTopCounterparties,
+				}
+				store.EXPECT().AccountAnalytics(gomock.Any(), gomock.Eq(arg)).Times(1).Return(analytics, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.AccountAnalytics
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, analytics.Totals, got.Totals)
+				require.Equal(t, analytics.Categories, got.Categories)
+				require.Len(t, got.Months, 1)
+			},
+		},
+		{
+			name:     "ReportCurrency",
+			username: user1.Username,
+			query:    "start_date=2024-03-01&end_date=2024-03-31&currency=KES&top_counterparties=3",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				rate := db.GetExchangeRateParams{BaseCurrency: util.USD, TargetCurrency: util.KES}
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(rate)).Times(1).
+					Return(db.ExchangeRate{BaseCurrency: util.USD, TargetCurrency: util.KES, ExchangeRate: "130.50"}, nil)
+				arg := db.AccountAnalyticsParams{
+					AccountID:         account.ID,
+					Currency:          util.KES,
+					Since:             since,
+					Until:             until,
+					TopCounterparties: 3,
+				}
+				store.EXPECT().AccountAnalytics(gomock.Any(), gomock.Eq(arg)).Times(1).Return(analytics, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "ExchangeRateNotFound",
+			username: user1.Username,
+			query:    "start_date=2024-03-01&end_date=2024-03-31&currency=KES",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(1).Return(db.ExchangeRate{}, sql.ErrNoRows)
+				store.EXPECT().AccountAnalytics(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "NotMember",
+			username: user2.Username,
+			query:    "start_date=2024-03-01&end_date=2024-03-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().AccountAnalytics(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "PeriodTooLong",
+			username: user1.Username,
+			query:    "start_date=2023-01-01&end_date=2024-03-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidCurrency",
+			username: user1.Username,
+			query:    "start_date=2024-03-01&end_date=2024-03-31&currency=XYZ",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			username: user1.Username,
+			query:    "start_date=2024-03-01&end_date=2024-03-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().AccountAnalytics(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountAnalytics{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/analytics?%s", account.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+type getAccountBalanceRequest struct {
+	// the balance at the end of this day (UTC), the current balance if empty
+	AsOf time.Time `form:"as_of" time_format:"2006-01-02" time_utc:"1"`
+}
+
+type accountBalanceResponse struct {
+	AccountID int64     `json:"account_id"`
+	Currency  string    `json:"currency"`
+	Balance   int64     `json:"balance"`
+	AsOf      time.Time `json:"as_of"`
+}
+
+// the balance of an account at the end of a day, worked out from its entries
+func (server *Server) getAccountBalance(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req getAccountBalanceRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, util.AccountPermissionView, 0) {
+		return
+	}
+
+	rsp := accountBalanceResponse{
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		Balance:   account.Balance,
+		AsOf:      time.Now(),
+	}
+
+	if !req.AsOf.IsZero() {
+		if req.AsOf.After(time.Now()) {
+			err := errors.New("as_of cannot be in the future")
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		endOfDay := req.AsOf.AddDate(0, 0, 1)
+
+		rsp.Balance, err = server.store.GetAccountBalanceAt(ctx, db.GetAccountBalanceAtParams{
+			AccountID: account.ID,
+			At:        endOfDay,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		rsp.AsOf = endOfDay
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/token"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// authorizeAccount makes sure the authenticated user can use account for permission, as its owner
+// or as a member whose role allows it. Payments by a member are also held to their payment limit.
+// Every handler that acts on an account on its owner's behalf goes through here.
+func (server *Server) authorizeAccount(ctx *gin.Context, account db.Account, permission string, amount int64) bool {
+	status, err := server.accountAccess(ctx, account, permission, amount)
+	if err != nil {
+		ctx.JSON(status, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+// accountAccess is the check behind authorizeAccount, returning the status and error to respond
+// with instead of responding, for handlers that accept more than one account
+func (server *Server) accountAccess(ctx *gin.Context, account db.Account, permission string, amount int64) (int, error) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	member, err := db.AccountMembership(ctx, server.store, account, authPayload.Username)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	if member.Role == "" {
+		return http.StatusUnauthorized, fmt.Errorf("account [%d] doesn't belong to the authenticated user", account.ID)
+	}
+
+	if !util.AccountRoleAllows(member.Role, permission) {
+		return http.StatusUnauthorized, fmt.Errorf("a %s of account [%d] doesn't have %s access", member.Role, account.ID, permission)
+	}
+
+	if permission == util.AccountPermissionPay && account.OrganizationID.Valid {
+		return http.StatusForbidden, errBusinessAccountPayment
+	}
+
+	if permission == util.AccountPermissionPay && member.PaymentLimit > 0 && amount > member.PaymentLimit {
+		return http.StatusForbidden, fmt.Errorf("amount exceeds the payment limit of %d %s on account [%d]", member.PaymentLimit, account.Currency, account.ID)
+	}
+
+	return http.StatusOK, nil
+}
+
+// invite a user to an account, the payment limit only applies to payers
+type inviteAccountMemberRequest struct {
+	Username     string `json:"username" binding:"required,alphanum"`
+	Role         string `json:"role" binding:"required,account_role"`
+	PaymentLimit int64  `json:"payment_limit" binding:"min=0"`
+}
+
+func (server *Server) inviteAccountMember(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req inviteAccountMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.PaymentLimit > 0 && req.Role != util.AccountRolePayer {
+		err := errors.New("only payers can have a payment limit")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.getAccountByID(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, util.AccountPermissionManage, 0) {
+		return
+	}
+
+	if req.Username == account.Owner {
+		err := errors.New("the owner is already a member of the account")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	member, err := server.store.CreateAccountMember(ctx, db.CreateAccountMemberParams{
+		AccountID:    account.ID,
+		Username:     req.Username,
+		Role:         req.Role,
+		PaymentLimit: req.PaymentLimit,
+		InvitedBy:    authPayload.Username,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				err := errors.New("user is already a member of or invited to the account")
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			case "foreign_key_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+func (server *Server) listAccountMembers(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.getAccountByID(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, util.AccountPermissionView, 0) {
+		return
+	}
+
+	members, err := server.store.ListAccountMembers(ctx, account.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, members)
+}
+
+// list the accounts the authenticated user has been invited to and not answered yet
+func (server *Server) listAccountInvitations(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	invitations, err := server.store.ListAccountInvitations(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, invitations)
+}
+
+func (server *Server) acceptAccountInvitation(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	member, err := server.store.AcceptAccountMember(ctx, db.AcceptAccountMemberParams{
+		AccountID: uri.ID,
+		Username:  authPayload.Username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err := errors.New("no pending invitation to the account")
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+type accountMemberURI struct {
+	ID       int64  `uri:"id" binding:"required,min=1"`
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+// remove a member or withdraw an invitation, members can also leave or decline on their own
+func (server *Server) removeAccountMember(ctx *gin.Context) {
+	var uri accountMemberURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.getAccountByID(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if uri.Username != authPayload.Username && !server.authorizeAccount(ctx, account, util.AccountPermissionManage, 0) {
+		return
+	}
+
+	_, err := server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  uri.Username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	err = server.store.DeleteAccountMember(ctx, db.DeleteAccountMemberParams{
+		AccountID: account.ID,
+		Username:  uri.Username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "member removed"})
+}
+
+// getAccountByID loads an account, answering 404 if it doesn't exist
+func (server *Server) getAccountByID(ctx *gin.Context, id int64) (db.Account, bool) {
+	account, err := server.store.GetAccount(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return account, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
+	}
+
+	return account, true
+}
@@ -3,30 +3,40 @@ package api
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/malcolmmaima/maimabank/db/sqlc"
-	"github.com/malcolmmaima/maimabank/token"
+	"github.com/malcolmmaima/maimabank/util"
 )
 
 type getAccountTransfersRequest struct {
-	AccountID    int64     `form:"account_id" binding:"required,min=1"`
-	PageID       int32     `form:"page_id" binding:"required,min=1"`
-	PageSize     int32     `form:"page_size" binding:"required,min=5,max=10"`
-	StartDate    time.Time `form:"start_date" time_format:"2006-01-02"`
-	EndDate      time.Time `form:"end_date" time_format:"2006-01-02"`
+	pageRequest
+	AccountID int64     `form:"account_id" binding:"required,min=1"`
+	StartDate time.Time `form:"start_date" time_format:"2006-01-02"`
+	// inclusive
+	EndDate time.Time `form:"end_date" time_format:"2006-01-02"`
+	// searches the memo, the reference and the account holder's own description
+	Query    string `form:"q" binding:"max=100"`
+	Category string `form:"category" binding:"omitempty,category"`
+	// debit lists the transfers out of the account, credit the ones into it
+	Direction             string `form:"direction" binding:"omitempty,oneof=debit credit"`
+	CounterpartyAccountID int64  `form:"counterparty_account_id" binding:"omitempty,min=1"`
+	MinAmount             int64  `form:"min_amount" binding:"omitempty,min=1"`
+	MaxAmount             int64  `form:"max_amount" binding:"omitempty,min=1"`
 }
 
 func newTransferResponse(transfers []db.Transfer, userAccountID int64) []db.Transfer {
-	var transferResponses []db.Transfer
+	transferResponses := make([]db.Transfer, 0, len(transfers))
 
 	for _, transfer := range transfers {
 		amount := transfer.Amount
 		if transfer.FromAccountID == userAccountID {
-			// Treat it as a debit, so append a negative sign to the amount
-			amount = -amount
+			// Treat it as a debit of what the account was charged, in its own currency,
+			// so append a negative sign to the amount
+			amount = -transfer.FromAmount
 		} else {
 			// Treat it as a credit, so keep the amount positive
 			amount = transfer.Amount
@@ -39,6 +49,18 @@ func newTransferResponse(transfers []db.Transfer, userAccountID int64) []db.Tran
 			ToAccountID:   transfer.ToAccountID,
 			Amount:        amount,
 			CreatedAt:     transfer.CreatedAt,
+			Memo:          transfer.Memo,
+			Reference:     transfer.Reference,
+		}
+
+		// each party only sees the description and category they set themselves
+		if transfer.FromAccountID == userAccountID {
+			transferResponse.SenderDescription = transfer.SenderDescription
+			transferResponse.SenderCategory = transfer.SenderCategory
+		}
+		if transfer.ToAccountID == userAccountID {
+			transferResponse.RecipientDescription = transfer.RecipientDescription
+			transferResponse.RecipientCategory = transfer.RecipientCategory
 		}
 		transferResponses = append(transferResponses, transferResponse)
 	}
@@ -48,7 +70,8 @@ func newTransferResponse(transfers []db.Transfer, userAccountID int64) []db.Tran
 
 
 
-// user account statement by ListTransfers query where from_account_id = account_id
+// the transfers of an account, a page at a time, filtered by date, direction, counterparty,
+// amount, category and text
 func (server *Server) listTransfers(ctx *gin.Context) {
 	var req getAccountTransfersRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
@@ -56,87 +79,134 @@ func (server *Server) listTransfers(ctx *gin.Context) {
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	page, err := req.page()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.StartDate.IsZero() != req.EndDate.IsZero() {
+		err := errors.New("both start_date and end_date must be provided")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.StartDate.After(req.EndDate) {
+		err := errors.New("start_date cannot be greater than end_date")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.MaxAmount != 0 && req.MinAmount > req.MaxAmount {
+		err := errors.New("min_amount cannot be greater than max_amount")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
 
-	// Check if user owns account
 	account, err := server.store.GetAccount(ctx, req.AccountID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
 			return
 		}
 
-		if req.AccountID != account.ID {
-			err := errors.New("account does not belong to authenticated user")
-			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-			return
-		}
-		
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	if account.Owner != authPayload.Username {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if !server.authorizeAccount(ctx, account, util.AccountPermissionView, 0) {
 		return
 	}
 
-	listTransfersParams := db.ListTransfersParams{
-		FromAccountID: req.AccountID,
-		ToAccountID: req.AccountID,
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+	arg := db.ListAccountTransfersParams{
+		AccountID:             account.ID,
+		Direction:             req.Direction,
+		CounterpartyAccountID: req.CounterpartyAccountID,
+		MinAmount:             req.MinAmount,
+		MaxAmount:             req.MaxAmount,
+		Query:                 req.Query,
+		Category:              req.Category,
+		AfterID:               page.AfterID,
+		Descending:            page.Descending,
+		PageLimit:             page.limit(),
+	}
+	if !req.StartDate.IsZero() {
+		arg.StartDate = sql.NullTime{Time: req.StartDate, Valid: true}
+		arg.EndDate = sql.NullTime{Time: req.EndDate.AddDate(0, 0, 1), Valid: true}
 	}
 
-	listTransfersByDateParams := db.ListTransfersByDateParams{
-		FromAccountID: req.AccountID,
-		ToAccountID: req.AccountID,
-		CreatedAt: req.StartDate,
-		CreatedAt_2: req.EndDate,
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
-
+	transfers, err := server.store.ListAccountTransfers(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
 	}
 
-	var transfers []db.Transfer
-	if req.StartDate.IsZero() && req.EndDate.IsZero() {
-		transfers, err = server.store.ListTransfers(ctx, listTransfersParams)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-	} 
+	n, nextCursor := page.next(len(transfers), func(i int) int64 { return transfers[i].ID })
+	setNextCursor(ctx, nextCursor)
+	ctx.JSON(http.StatusOK, newTransferResponse(transfers[:n], account.ID))
+}
 
-	// if either is dates are empty, return error
-	if req.StartDate.IsZero() && !req.EndDate.IsZero() || !req.StartDate.IsZero() && req.EndDate.IsZero() {
-		err := errors.New("both start_date and end_date must be provided")
+// statements are built in one go, longer periods can be exported instead
+const maxStatementDays = 366
+
+type getAccountStatementRequest struct {
+	StartDate time.Time `form:"start_date" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	// inclusive
+	EndDate time.Time `form:"end_date" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+}
+
+// the statement of an account over a period with opening, running and closing balances,
+// built from its entries so fees, pot moves and interest appear as well as transfers
+func (server *Server) getAccountStatement(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	// if both dates are provided, use ListTransfersByDate query
-	if !req.StartDate.IsZero() && !req.EndDate.IsZero() {
-		transfers, err = server.store.ListTransfersByDate(ctx, listTransfersByDateParams)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
+	var req getAccountStatementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
 	}
 
-	// if start_date is greater than end_date, return error
 	if req.StartDate.After(req.EndDate) {
 		err := errors.New("start_date cannot be greater than end_date")
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	// if no transfers are found, return empty array
-	if len(transfers) == 0 {
-		ctx.JSON(http.StatusOK, []db.Transfer{})
+	until := req.EndDate.AddDate(0, 0, 1)
+	if until.After(req.StartDate.AddDate(0, 0, maxStatementDays)) {
+		err := fmt.Errorf("a statement can cover at most %d days", maxStatementDays)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, newTransferResponse(transfers, req.AccountID))
-}
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
 
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
 
+	if !server.authorizeAccount(ctx, account, util.AccountPermissionView, 0) {
+		return
+	}
+
+	statement, err := server.store.AccountStatement(ctx, db.AccountStatementParams{
+		AccountID: account.ID,
+		Since:     req.StartDate,
+		Until:     until,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statement)
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -12,12 +14,11 @@ import (
 	mockdb "github.com/malcolmmaima/maimabank/db/mock"
 	db "github.com/malcolmmaima/maimabank/db/sqlc"
 	"github.com/malcolmmaima/maimabank/token"
+	"github.com/malcolmmaima/maimabank/util"
 	"github.com/stretchr/testify/require"
 )
 
 func TestListTransfersAPI(t *testing.T) {
-	const pageSize = 10
-
 	user1, _ := randomUser(t)
 	user2, _ := randomUser(t)
 
@@ -29,17 +30,34 @@ func TestListTransfersAPI(t *testing.T) {
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
 		Amount:        100,
+		FromAmount:    100,
 		CreatedAt:     time.Now(),
 	}
 
+	// a multi currency transfer, the sender is debited in their own currency
 	transfer2 := db.Transfer{
 		ID:            2,
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
 		Amount:        200,
+		FromAmount:    220,
 		CreatedAt:     time.Now(),
 	}
 
+	transfer3 := db.Transfer{
+		ID:                   3,
+		FromAccountID:        account1.ID,
+		ToAccountID:          account2.ID,
+		Amount:               300,
+		CreatedAt:            time.Now(),
+		Memo:                 "March rent",
+		Reference:            util.RandomString(12),
+		SenderDescription:    "Rent to landlord",
+		RecipientDescription: "Rent from tenant",
+		SenderCategory:       util.CategoryRent,
+		RecipientCategory:    util.CategorySalary,
+	}
+
 	testCases := []struct {
 		name          string
 		authUsername  string
@@ -51,103 +69,287 @@ func TestListTransfersAPI(t *testing.T) {
 		{
 			name:         "OK",
 			authUsername: user1.Username,
-			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10) + "&page_id=1&page_size=" + strconv.FormatInt(pageSize, 10),
+			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10),
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				listTransfersParams := db.ListTransfersParams{
-					FromAccountID: account1.ID,
-					ToAccountID:   account1.ID,
-					Limit:         pageSize,
-					Offset:        0,
-				}
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
-				store.EXPECT().ListTransfers(gomock.Any(), gomock.Eq(listTransfersParams)).Times(1).Return([]db.Transfer{transfer1, transfer2}, nil)
+				arg := db.ListAccountTransfersParams{
+					AccountID: account1.ID,
+					PageLimit: defaultPageSize + 1,
+				}
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Transfer{transfer1, transfer2}, nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 
-				var responseTransfers []db.Transfer
-				err := json.Unmarshal(recorder.Body.Bytes(), &responseTransfers)
-				require.NoError(t, err)
-
-				require.Len(t, responseTransfers, 2)
-				require.Equal(t, -transfer1.Amount, responseTransfers[0].Amount)
-				require.Equal(t, -transfer2.Amount, responseTransfers[1].Amount)
+				transfers := requireBodyMatchTransfers(t, recorder)
+				require.Len(t, transfers, 2)
+				require.Equal(t, -transfer1.FromAmount, transfers[0].Amount)
+				require.Equal(t, -transfer2.FromAmount, transfers[1].Amount)
+				require.Empty(t, recorder.Header().Get(nextCursorHeader))
 			},
 		},
 		{
 			name:         "Unauthorized",
 			authUsername: user1.Username,
-			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10) + "&page_id=1&page_size=" + strconv.FormatInt(pageSize, 10),
+			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10),
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				// For the "Unauthorized" test case, we won't add any authorization token
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				// We don't expect any calls to the database (GetAccount or ListTransfers) for the "Unauthorized" test case
+				// We don't expect any calls to the database (GetAccount or ListAccountTransfers) for the "Unauthorized" test case
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			name:         "AccountNotFound",
+			authUsername: user1.Username,
+			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
 		{
 			name:         "NoTransfers",
 			authUsername: user1.Username,
-			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10) + "&page_id=1&page_size=" + strconv.FormatInt(pageSize, 10),
+			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10),
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				listTransfersParams := db.ListTransfersParams{
-					FromAccountID: account1.ID,
-					ToAccountID:   account1.ID,
-					Limit:         pageSize,
-					Offset:        0,
-				}
-				// Return an empty list of transfers to simulate no transfers for the account
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
-				store.EXPECT().ListTransfers(gomock.Any(), gomock.Eq(listTransfersParams)).Times(1).Return([]db.Transfer{}, nil)
+				arg := db.ListAccountTransfersParams{
+					AccountID: account1.ID,
+					PageLimit: defaultPageSize + 1,
+				}
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Transfer{}, nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 
-				var responseTransfers []db.Transfer
-				err := json.Unmarshal(recorder.Body.Bytes(), &responseTransfers)
-				require.NoError(t, err)
-
-				require.Len(t, responseTransfers, 0)
+				// an empty page is an empty list, not null
+				require.Equal(t, "[]", recorder.Body.String())
 			},
 		},
 		{
 			name:         "Pagination",
 			authUsername: user1.Username,
-			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10) + "&page_id=1&page_size=5",
+			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10) + "&page_size=1",
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				listTransfersParams := db.ListTransfersParams{
-					FromAccountID: account1.ID,
-					ToAccountID:   account1.ID,
-					Limit:         5,
-					Offset:        0,
-				}
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
-				store.EXPECT().ListTransfers(gomock.Any(), gomock.Eq(listTransfersParams)).Times(1).Return([]db.Transfer{transfer1}, nil)
+				arg := db.ListAccountTransfersParams{
+					AccountID: account1.ID,
+					PageLimit: 2,
+				}
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Transfer{transfer1, transfer2}, nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 
-				var responseTransfers []db.Transfer
-				err := json.Unmarshal(recorder.Body.Bytes(), &responseTransfers)
+				transfers := requireBodyMatchTransfers(t, recorder)
+				require.Len(t, transfers, 1)
+				require.Equal(t, -transfer1.FromAmount, transfers[0].Amount)
+
+				cursor, err := decodeCursor(recorder.Header().Get(nextCursorHeader))
 				require.NoError(t, err)
+				require.Equal(t, pageCursor{AfterID: transfer1.ID, Sort: sortAscending}, cursor)
+			},
+		},
+		{
+			name:         "Cursor",
+			authUsername: user1.Username,
+			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10) + "&page_size=5&cursor=" + encodeCursor(pageCursor{AfterID: 3, Sort: sortDescending}),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				arg := db.ListAccountTransfersParams{
+					AccountID:  account1.ID,
+					AfterID:    3,
+					Descending: true,
+					PageLimit:  6,
+				}
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Transfer{transfer2, transfer1}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				transfers := requireBodyMatchTransfers(t, recorder)
+				require.Len(t, transfers, 2)
+				require.Equal(t, transfer2.ID, transfers[0].ID)
+				require.Empty(t, recorder.Header().Get(nextCursorHeader))
+			},
+		},
+		{
+			name:         "Filters",
+			authUsername: user1.Username,
+			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10) + "&direction=debit&counterparty_account_id=" + strconv.FormatInt(account2.ID, 10) + "&min_amount=100&max_amount=250&sort=desc",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				arg := db.ListAccountTransfersParams{
+					AccountID:             account1.ID,
+					Direction:             "debit",
+					CounterpartyAccountID: account2.ID,
+					MinAmount:             100,
+					MaxAmount:             250,
+					Descending:            true,
+					PageLimit:             defaultPageSize + 1,
+				}
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Transfer{transfer2, transfer1}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
 
-				require.Len(t, responseTransfers, 1)
-				require.Equal(t, -transfer1.Amount, responseTransfers[0].Amount)
+				transfers := requireBodyMatchTransfers(t, recorder)
+				require.Len(t, transfers, 2)
 			},
 		},
+		{
+			name:         "Search",
+			authUsername: user1.Username,
+			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10) + "&page_size=5&q=rent&category=" + util.CategoryRent,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				arg := db.ListAccountTransfersParams{
+					AccountID: account1.ID,
+					Query:     "rent",
+					Category:  util.CategoryRent,
+					PageLimit: 6,
+				}
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Transfer{transfer3}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
 
+				// the recipient's own description and category are hidden from the sender
+				transfers := requireBodyMatchTransfers(t, recorder)
+				require.Len(t, transfers, 1)
+				require.Equal(t, transfer3.Memo, transfers[0].Memo)
+				require.Equal(t, transfer3.Reference, transfers[0].Reference)
+				require.Equal(t, transfer3.SenderCategory, transfers[0].SenderCategory)
+				require.Equal(t, transfer3.SenderDescription, transfers[0].SenderDescription)
+				require.Empty(t, transfers[0].RecipientCategory)
+				require.Empty(t, transfers[0].RecipientDescription)
+			},
+		},
+		{
+			name:         "SearchByDate",
+			authUsername: user1.Username,
+			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10) + "&page_size=5&q=rent&start_date=2023-03-01&end_date=2023-03-31",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				arg := db.ListAccountTransfersParams{
+					AccountID: account1.ID,
+					Query:     "rent",
+					StartDate: sql.NullTime{Time: time.Date(2023, 3, 1, 0, 0, 0, 0, time.Local), Valid: true},
+					EndDate:   sql.NullTime{Time: time.Date(2023, 4, 1, 0, 0, 0, 0, time.Local), Valid: true},
+					PageLimit: 6,
+				}
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Transfer{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:         "MissingEndDate",
+			authUsername: user1.Username,
+			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10) + "&q=rent&start_date=2023-03-01",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:         "InvalidCategory",
+			authUsername: user1.Username,
+			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10) + "&category=lottery",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:         "InvalidDirection",
+			authUsername: user1.Username,
+			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10) + "&direction=sideways",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:         "MinAmountAboveMaxAmount",
+			authUsername: user1.Username,
+			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10) + "&min_amount=500&max_amount=100",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:         "InvalidCursor",
+			authUsername: user1.Username,
+			queryParams:  "account_id=" + strconv.FormatInt(account1.ID, 10) + "&cursor=" + encodeCursor(pageCursor{AfterID: -1, Sort: sortAscending}),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -173,3 +375,139 @@ func TestListTransfersAPI(t *testing.T) {
 		})
 	}
 }
+
+func requireBodyMatchTransfers(t *testing.T, recorder *httptest.ResponseRecorder) []db.Transfer {
+	var transfers []db.Transfer
+	err := json.Unmarshal(recorder.Body.Bytes(), &transfers)
+	require.NoError(t, err)
+	return transfers
+}
+
+func TestGetAccountStatementAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	account := createRandomAccount(user1.Username)
+
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	statement := db.AccountStatement{
+		AccountID:      account.ID,
+		Currency:       account.Currency,
+		Since:          since,
+		Until:          until,
+		OpeningBalance: 500,
+		TotalCredits:   100,
+		TotalDebits:    35,
+		ClosingBalance: 565,
+		Lines: []db.StatementLine{
+			{ListStatementEntriesRow: db.ListStatementEntriesRow{ID: 1, Amount: 100, LineType: util.TransferKindTransfer}, Balance: 600},
+			{ListStatementEntriesRow: db.ListStatementEntriesRow{ID: 2, Amount: -30, LineType: util.TransferKindTransfer}, Balance: 570},
+			{ListStatementEntriesRow: db.ListStatementEntriesRow{ID: 3, Amount: -5, LineType: util.StatementLineFee}, Balance: 565},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user1.Username,
+			query:    "start_date=2024-03-01&end_date=2024-03-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				arg := db.AccountStatementParams{
+					AccountID: account.ID,
+					Since:     since,
+					Until:     until,
+				}
+				store.EXPECT().AccountStatement(gomock.Any(), gomock.Eq(arg)).Times(1).Return(statement, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.AccountStatement
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, statement.OpeningBalance, got.OpeningBalance)
+				require.Equal(t, statement.ClosingBalance, got.ClosingBalance)
+				require.Equal(t, statement.Lines, got.Lines)
+			},
+		},
+		{
+			name:     "MissingEndDate",
+			username: user1.Username,
+			query:    "start_date=2024-03-01",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().AccountStatement(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "StartAfterEnd",
+			username: user1.Username,
+			query:    "start_date=2024-04-01&end_date=2024-03-01",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().AccountStatement(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "PeriodTooLong",
+			username: user1.Username,
+			query:    "start_date=2022-01-01&end_date=2024-03-01",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().AccountStatement(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NotOwner",
+			username: user2.Username,
+			query:    "start_date=2024-03-01&end_date=2024-03-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().AccountStatement(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/statement?%s", account.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
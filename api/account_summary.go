@@ -0,0 +1,215 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/token"
+)
+
+// accounts are read this many at a time when totalling them up
+const summaryPageSize = 100
+
+type accountSummaryRequest struct {
+	Currency string `form:"currency" binding:"required,currency"`
+}
+
+// exchangeRateLeg is one stored rate a conversion went through. Inverted means the rate is
+// stored the other way round and was divided by rather than multiplied by.
+type exchangeRateLeg struct {
+	BaseCurrency   string    `json:"base_currency"`
+	TargetCurrency string    `json:"target_currency"`
+	ExchangeRate   string    `json:"exchange_rate"`
+	Inverted       bool      `json:"inverted"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type accountSummaryItem struct {
+	AccountID int64  `json:"account_id"`
+	Currency  string `json:"currency"`
+	Balance   int64  `json:"balance"`
+	// money set aside in the account's open pots, which isn't part of its balance
+	PotsBalance int64 `json:"pots_balance"`
+	// the balance and pots together in the summary's currency
+	ConvertedBalance int64             `json:"converted_balance"`
+	ExchangeRate     string            `json:"exchange_rate"`
+	Rates            []exchangeRateLeg `json:"rates"`
+}
+
+type accountSummaryResponse struct {
+	Currency string               `json:"currency"`
+	Total    int64                `json:"total"`
+	Accounts []accountSummaryItem `json:"accounts"`
+}
+
+// getAccountSummary totals the balances and open pots of every account the user owns in one currency,
+// converting each at the current exchange rates
+func (server *Server) getAccountSummary(ctx *gin.Context) {
+	var req accountSummaryRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	accounts, err := server.listOwnerAccounts(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	potTotals, err := server.store.ListPotTotals(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	potsBalances := make(map[int64]int64, len(potTotals))
+	for _, total := range potTotals {
+		potsBalances[total.AccountID] = total.Balance
+	}
+
+	rates, err := server.store.ListExchangeRates(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	book := newRateBook(rates)
+
+	response := accountSummaryResponse{
+		Currency: req.Currency,
+		Accounts: []accountSummaryItem{},
+	}
+	for _, account := range accounts {
+		rate, legs, found := book.conversion(account.Currency, req.Currency)
+		if !found {
+			err := fmt.Errorf("exchange rate not found from %s to %s", account.Currency, req.Currency)
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		potsBalance := potsBalances[account.ID]
+		converted := int64(math.Round(float64(account.Balance+potsBalance) * rate))
+		response.Total += converted
+		response.Accounts = append(response.Accounts, accountSummaryItem{
+			AccountID:        account.ID,
+			Currency:         account.Currency,
+			Balance:          account.Balance,
+			PotsBalance:      potsBalance,
+			ConvertedBalance: converted,
+			ExchangeRate:     strconv.FormatFloat(rate, 'f', -1, 64),
+			Rates:            legs,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// listOwnerAccounts reads all of owner's accounts, a page at a time
+func (server *Server) listOwnerAccounts(ctx *gin.Context, owner string) ([]db.Account, error) {
+	var accounts []db.Account
+	arg := db.ListAccountsParams{
+		Username:  owner,
+		PageLimit: summaryPageSize,
+	}
+	for {
+		page, err := server.store.ListAccounts(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, page...)
+		if len(page) < summaryPageSize {
+			return accounts, nil
+		}
+		arg.AfterID = page[len(page)-1].ID
+	}
+}
+
+// rateBook looks up stored exchange rates by currency pair
+type rateBook struct {
+	rates      map[[2]string]db.ExchangeRate
+	currencies []string
+}
+
+func newRateBook(rates []db.ExchangeRate) rateBook {
+	book := rateBook{rates: make(map[[2]string]db.ExchangeRate, len(rates))}
+	seen := make(map[string]bool)
+	for _, rate := range rates {
+		book.rates[[2]string{rate.BaseCurrency, rate.TargetCurrency}] = rate
+		for _, currency := range []string{rate.BaseCurrency, rate.TargetCurrency} {
+			if !seen[currency] {
+				seen[currency] = true
+				book.currencies = append(book.currencies, currency)
+			}
+		}
+	}
+	// pivots are tried in a fixed order so the same rates always give the same path
+	sort.Strings(book.currencies)
+	return book
+}
+
+// leg converts between two currencies with a single stored rate, preferring the rate stored in
+// that direction over the inverse of the opposite one
+func (book rateBook) leg(from string, to string) (float64, exchangeRateLeg, bool) {
+	if rate, ok := book.rates[[2]string{from, to}]; ok {
+		value, err := strconv.ParseFloat(rate.ExchangeRate, 64)
+		if err == nil && value > 0 {
+			return value, newExchangeRateLeg(rate, false), true
+		}
+	}
+
+	if rate, ok := book.rates[[2]string{to, from}]; ok {
+		value, err := strconv.ParseFloat(rate.ExchangeRate, 64)
+		if err == nil && value > 0 {
+			return 1 / value, newExchangeRateLeg(rate, true), true
+		}
+	}
+
+	return 0, exchangeRateLeg{}, false
+}
+
+// conversion finds the rate from one currency to another, going through a third currency when
+// there is no rate between the two
+func (book rateBook) conversion(from string, to string) (float64, []exchangeRateLeg, bool) {
+	if from == to {
+		return 1, []exchangeRateLeg{}, true
+	}
+
+	if rate, leg, ok := book.leg(from, to); ok {
+		return rate, []exchangeRateLeg{leg}, true
+	}
+
+	for _, pivot := range book.currencies {
+		if pivot == from || pivot == to {
+			continue
+		}
+
+		firstRate, first, ok := book.leg(from, pivot)
+		if !ok {
+			continue
+		}
+		secondRate, second, ok := book.leg(pivot, to)
+		if !ok {
+			continue
+		}
+		return firstRate * secondRate, []exchangeRateLeg{first, second}, true
+	}
+
+	return 0, nil, false
+}
+
+func newExchangeRateLeg(rate db.ExchangeRate, inverted bool) exchangeRateLeg {
+	return exchangeRateLeg{
+		BaseCurrency:   rate.BaseCurrency,
+		TargetCurrency: rate.TargetCurrency,
+		ExchangeRate:   rate.ExchangeRate,
+		Inverted:       inverted,
+		UpdatedAt:      rate.UpdatedAt,
+	}
+}
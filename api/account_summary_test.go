@@ -0,0 +1,241 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/malcolmmaima/maimabank/db/mock"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountSummaryAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	newAccount := func(id int64, currency string, balance int64) db.Account {
+		account := createRandomAccount(user.Username)
+		account.ID = id
+		account.Currency = currency
+		account.Balance = balance
+		return account
+	}
+	accounts := []db.Account{
+		newAccount(1, util.USD, 1000),
+		newAccount(2, util.EUR, 500),
+		newAccount(3, util.KES, 2000),
+		newAccount(4, util.GBP, 100),
+	}
+
+	updatedAt := time.Now().Truncate(time.Second).UTC()
+	rates := []db.ExchangeRate{
+		{ID: 1, BaseCurrency: util.EUR, TargetCurrency: util.USD, ExchangeRate: "1.25", UpdatedAt: updatedAt},
+		{ID: 2, BaseCurrency: util.KES, TargetCurrency: util.GBP, ExchangeRate: "0.005", UpdatedAt: updatedAt},
+		{ID: 3, BaseCurrency: util.USD, TargetCurrency: util.KES, ExchangeRate: "130", UpdatedAt: updatedAt},
+	}
+
+	firstPage := db.ListAccountsParams{Username: user.Username, PageLimit: summaryPageSize}
+
+	testCases := []struct {
+		name          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "?currency=KES",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(firstPage)).Times(1).Return(accounts, nil)
+				store.EXPECT().ListPotTotals(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return([]db.ListPotTotalsRow{}, nil)
+				store.EXPECT().ListExchangeRates(gomock.Any()).Times(1).Return(rates, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got accountSummaryResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, util.KES, got.Currency)
+				require.Len(t, got.Accounts, 4)
+
+				// direct rate
+				require.Equal(t, int64(130000), got.Accounts[0].ConvertedBalance)
+				require.Equal(t, "130", got.Accounts[0].ExchangeRate)
+				require.Equal(t, []exchangeRateLeg{
+					{BaseCurrency: util.USD, TargetCurrency: util.KES, ExchangeRate: "130", UpdatedAt: updatedAt},
+				}, got.Accounts[0].Rates)
+
+				// triangulated through USD
+				require.Equal(t, int64(81250), got.Accounts[1].ConvertedBalance)
+				require.Equal(t, "162.5", got.Accounts[1].ExchangeRate)
+				require.Len(t, got.Accounts[1].Rates, 2)
+				require.Equal(t, util.EUR, got.Accounts[1].Rates[0].BaseCurrency)
+				require.Equal(t, util.USD, got.Accounts[1].Rates[1].BaseCurrency)
+
+				// same currency
+				require.Equal(t, int64(2000), got.Accounts[2].ConvertedBalance)
+				require.Equal(t, "1", got.Accounts[2].ExchangeRate)
+				require.Empty(t, got.Accounts[2].Rates)
+
+				// rate stored the other way round
+				require.Equal(t, int64(20000), got.Accounts[3].ConvertedBalance)
+				require.Len(t, got.Accounts[3].Rates, 1)
+				require.True(t, got.Accounts[3].Rates[0].Inverted)
+
+				require.Equal(t, int64(233250), got.Total)
+			},
+		},
+		{
+			name:  "FundedPot",
+			query: "?currency=USD",
+			buildStubs: func(store *mockdb.MockStore) {
+				potTotals := []db.ListPotTotalsRow{
+					{AccountID: accounts[0].ID, Balance: 300},
+					{AccountID: accounts[1].ID, Balance: 100},
+				}
+
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(firstPage)).Times(1).Return(accounts[:2], nil)
+				store.EXPECT().ListPotTotals(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(potTotals, nil)
+				store.EXPECT().ListExchangeRates(gomock.Any()).Times(1).Return(rates, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got accountSummaryResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got.Accounts, 2)
+
+				// money in a pot is still the user's, it is converted along with the balance
+				require.Equal(t, int64(1000), got.Accounts[0].Balance)
+				require.Equal(t, int64(300), got.Accounts[0].PotsBalance)
+				require.Equal(t, int64(1300), got.Accounts[0].ConvertedBalance)
+
+				require.Equal(t, int64(500), got.Accounts[1].Balance)
+				require.Equal(t, int64(100), got.Accounts[1].PotsBalance)
+				require.Equal(t, int64(750), got.Accounts[1].ConvertedBalance)
+
+				require.Equal(t, int64(2050), got.Total)
+			},
+		},
+		{
+			name:  "ManyAccounts",
+			query: "?currency=USD",
+			buildStubs: func(store *mockdb.MockStore) {
+				page := make([]db.Account, summaryPageSize)
+				for i := range page {
+					page[i] = newAccount(int64(i+1), util.USD, 10)
+				}
+				nextPage := firstPage
+				nextPage.AfterID = summaryPageSize
+
+				gomock.InOrder(
+					store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(firstPage)).Times(1).Return(page, nil),
+					store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(nextPage)).Times(1).Return(accounts[:1], nil),
+				)
+				store.EXPECT().ListPotTotals(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return([]db.ListPotTotalsRow{}, nil)
+				store.EXPECT().ListExchangeRates(gomock.Any()).Times(1).Return(rates, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got accountSummaryResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got.Accounts, summaryPageSize+1)
+				require.Equal(t, int64(summaryPageSize*10+1000), got.Total)
+			},
+		},
+		{
+			name:  "NoAccounts",
+			query: "?currency=USD",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(firstPage)).Times(1).Return([]db.Account{}, nil)
+				store.EXPECT().ListPotTotals(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return([]db.ListPotTotalsRow{}, nil)
+				store.EXPECT().ListExchangeRates(gomock.Any()).Times(1).Return(rates, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got accountSummaryResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.NotNil(t, got.Accounts)
+				require.Empty(t, got.Accounts)
+				require.Zero(t, got.Total)
+			},
+		},
+		{
+			name:  "ExchangeRateNotFound",
+			query: "?currency=GBP",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(1).Return(accounts, nil)
+				store.EXPECT().ListPotTotals(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return([]db.ListPotTotalsRow{}, nil)
+				store.EXPECT().ListExchangeRates(gomock.Any()).Times(1).Return(rates[2:], nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "MissingCurrency",
+			query: "",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidCurrency",
+			query: "?currency=XYZ",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "?currency=KES",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(1).Return([]db.Account{}, sql.ErrConnDone)
+				store.EXPECT().ListPotTotals(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListExchangeRates(gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/accounts/summary"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
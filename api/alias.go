@@ -0,0 +1,394 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/token"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// wrong codes allowed before a new one has to be requested
+const maxCodeAttempts = 5
+
+type aliasResponse struct {
+	ID        int64     `json:"id"`
+	AliasType string    `json:"alias_type"`
+	Value     string    `json:"value"`
+	Verified  bool      `json:"verified"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newAliasResponse(alias db.Alias) aliasResponse {
+	return aliasResponse{
+		ID:        alias.ID,
+		AliasType: alias.AliasType,
+		Value:     alias.Value,
+		Verified:  alias.VerifiedAt.Valid,
+		CreatedAt: alias.CreatedAt,
+	}
+}
+
+// register an email address or phone number, a code is sent to it to prove the user controls it
+type createAliasRequest struct {
+	Alias string `json:"alias" binding:"required"`
+}
+
+func (server *Server) createAlias(ctx *gin.Context) {
+	var req createAliasRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	aliasType, value, err := util.ParseAlias(req.Alias)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if aliasType == util.AliasTypeUsername {
+		err := errors.New("usernames are always an alias of their owner and don't need to be registered")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	verifiedAlias, err := server.store.GetVerifiedAlias(ctx, db.GetVerifiedAliasParams{
+		AliasType: aliasType,
+		Value:     value,
+	})
+	if err == nil {
+		err := errors.New("alias is already taken")
+		if verifiedAlias.Owner == authPayload.Username {
+			err = errors.New("alias is already verified")
+		}
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+	if err != sql.ErrNoRows {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	code, err := newVerificationCode()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	hashedCode, err := util.HashPassword(code)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// registering the same alias again sends a new code
+	alias, err := server.store.CreateAlias(ctx, db.CreateAliasParams{
+		Owner:         authPayload.Username,
+		AliasType:     aliasType,
+		Value:         value,
+		HashedCode:    hashedCode,
+		CodeExpiresAt: time.Now().Add(server.config.AliasCodeDuration),
+	})
+	if err != nil {
+		// verified by the user in the meantime, there is nothing left to update
+		if err == sql.ErrNoRows {
+			err := errors.New("alias is already verified")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	message := fmt.Sprintf("Your maimabank verification code is %s, it expires in %s", code, server.config.AliasCodeDuration)
+	err = server.sender.Send(ctx, alias.AliasType, alias.Value, message)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAliasResponse(alias))
+}
+
+type aliasURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type verifyAliasRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+func (server *Server) verifyAlias(ctx *gin.Context) {
+	var uri aliasURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req verifyAliasRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	alias, valid := server.ownAlias(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	if alias.VerifiedAt.Valid {
+		err := errors.New("alias is already verified")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	if alias.FailedAttempts >= maxCodeAttempts || time.Now().After(alias.CodeExpiresAt) {
+		err := errors.New("verification code has expired, register the alias again for a new one")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	err := util.CheckPassword(req.Code, alias.HashedCode)
+	if err != nil {
+		_, err = server.store.AddAliasFailedAttempt(ctx, alias.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		err := errors.New("wrong verification code")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	alias, err = server.store.VerifyAlias(ctx, alias.ID)
+	if err != nil {
+		// someone else verified the same alias first
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			err := errors.New("alias is already taken")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAliasResponse(alias))
+}
+
+func (server *Server) listAliases(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	aliases, err := server.store.ListAliases(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := []aliasResponse{}
+	for _, alias := range aliases {
+		response = append(response, newAliasResponse(alias))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (server *Server) deleteAlias(ctx *gin.Context) {
+	var uri aliasURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	alias, valid := server.ownAlias(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	err := server.store.DeleteAlias(ctx, alias.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "alias deleted"})
+}
+
+// ownAlias makes sure an alias exists and belongs to the authenticated user
+func (server *Server) ownAlias(ctx *gin.Context, id int64) (db.Alias, bool) {
+	alias, err := server.store.GetAlias(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return alias, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return alias, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if alias.Owner != authPayload.Username {
+		err := errors.New("alias doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return alias, false
+	}
+
+	return alias, true
+}
+
+// look up who an alias pays before sending money to it, the name is masked so the
+// directory can't be used to find out who owns a phone number or email address
+type lookupAliasRequest struct {
+	Alias    string `form:"alias" binding:"required"`
+	Currency string `form:"currency" binding:"required,currency"`
+}
+
+type lookupAliasResponse struct {
+	Alias         string `json:"alias"`
+	AliasType     string `json:"alias_type"`
+	Currency      string `json:"currency"`
+	RecipientName string `json:"recipient_name"`
+}
+
+func (server *Server) lookupAlias(ctx *gin.Context) {
+	var req lookupAliasRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.resolveAlias(ctx, req.Alias, req.Currency)
+	if !valid {
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, account.Owner)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	aliasType, value, _ := util.ParseAlias(req.Alias)
+	ctx.JSON(http.StatusOK, lookupAliasResponse{
+		Alias:         value,
+		AliasType:     aliasType,
+		Currency:      account.Currency,
+		RecipientName: util.MaskName(user.FullName),
+	})
+}
+
+// resolveAlias finds the account a username or verified email or phone number is paid into in currency
+func (server *Server) resolveAlias(ctx *gin.Context, alias string, currency string) (db.Account, bool) {
+	owner, value, valid := server.aliasOwner(ctx, alias)
+	if !valid {
+		return db.Account{}, false
+	}
+
+	account, err := server.store.GetDefaultAccount(ctx, db.GetDefaultAccountParams{
+		Owner:    owner,
+		Currency: currency,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err := fmt.Errorf("%s can't be paid in %s", value, currency)
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return account, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
+	}
+
+	return account, true
+}
+
+// aliasOwner finds the username behind a username or verified email or phone number,
+// along with the normalized alias
+func (server *Server) aliasOwner(ctx *gin.Context, alias string) (string, string, bool) {
+	aliasType, value, err := util.ParseAlias(alias)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return "", "", false
+	}
+
+	if aliasType == util.AliasTypeUsername {
+		return value, value, true
+	}
+
+	verifiedAlias, err := server.store.GetVerifiedAlias(ctx, db.GetVerifiedAliasParams{
+		AliasType: aliasType,
+		Value:     value,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err := fmt.Errorf("%s isn't linked to any user", value)
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return "", "", false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return "", "", false
+	}
+
+	return verifiedAlias.Owner, value, true
+}
+
+// choose the account payments to the user's aliases go to for its currency
+func (server *Server) setDefaultAccount(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, util.AccountPermissionManage, 0) {
+		return
+	}
+
+	// the default account is where payments to the owner's aliases go, so a co-owner of the
+	// account can't choose it for them
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username {
+		err := errors.New("only the account owner can make it the default account")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	defaultAccount, err := server.store.SetDefaultAccount(ctx, db.SetDefaultAccountParams{
+		Owner:     account.Owner,
+		Currency:  account.Currency,
+		AccountID: account.ID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, defaultAccount)
+}
+
+// newVerificationCode returns a random 6 digit code
+func newVerificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
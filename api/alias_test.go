@@ -0,0 +1,510 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	mockdb "github.com/malcolmmaima/maimabank/db/mock"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+type sentMessage struct {
+	channel string
+	address string
+	message string
+}
+
+// recordingSender keeps the messages it is asked to send so tests can read the codes in them
+type recordingSender struct {
+	sent []sentMessage
+}
+
+func (sender *recordingSender) Send(ctx context.Context, channel string, address string, message string) error {
+	sender.sent = append(sender.sent, sentMessage{channel, address, message})
+	return nil
+}
+
+func TestCreateAliasAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+
+	var hashedCode string
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder, sender *recordingSender)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"alias": "Jane.Doe@Example.com"},
+			buildStubs: func(store *mockdb.MockStore) {
+				aliasArg := db.GetVerifiedAliasParams{
+					AliasType: util.AliasTypeEmail,
+					Value:     "jane.doe@example.com",
+				}
+				store.EXPECT().GetVerifiedAlias(gomock.Any(), gomock.Eq(aliasArg)).Times(1).Return(db.Alias{}, sql.ErrNoRows)
+				store.EXPECT().CreateAlias(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateAliasParams) (db.Alias, error) {
+						require.Equal(t, user.Username, arg.Owner)
+						require.Equal(t, util.AliasTypeEmail, arg.AliasType)
+						require.Equal(t, "jane.doe@example.com", arg.Value)
+						require.WithinDuration(t, time.Now().Add(10*time.Minute), arg.CodeExpiresAt, time.Second)
+						hashedCode = arg.HashedCode
+
+						return db.Alias{
+							ID:            1,
+							Owner:         arg.Owner,
+							AliasType:     arg.AliasType,
+							Value:         arg.Value,
+							HashedCode:    arg.HashedCode,
+							CodeExpiresAt: arg.CodeExpiresAt,
+						}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *recordingSender) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var alias aliasResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &alias)
+				require.NoError(t, err)
+				require.Equal(t, "jane.doe@example.com", alias.Value)
+				require.False(t, alias.Verified)
+				require.NotContains(t, recorder.Body.String(), "code")
+
+				// the code goes to the alias itself and only its hash is stored
+				require.Len(t, sender.sent, 1)
+				require.Equal(t, util.AliasTypeEmail, sender.sent[0].channel)
+				require.Equal(t, "jane.doe@example.com", sender.sent[0].address)
+				code := regexp.MustCompile(`[0-9]{6}`).FindString(sender.sent[0].message)
+				require.NoError(t, util.CheckPassword(code, hashedCode))
+			},
+		},
+		{
+			name: "TakenByAnotherUser",
+			body: gin.H{"alias": "+254712345678"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetVerifiedAlias(gomock.Any(), gomock.Any()).Times(1).Return(db.Alias{Owner: other.Username}, nil)
+				store.EXPECT().CreateAlias(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *recordingSender) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.Empty(t, sender.sent)
+			},
+		},
+		{
+			name: "Username",
+			body: gin.H{"alias": user.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetVerifiedAlias(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAlias(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *recordingSender) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAlias",
+			body: gin.H{"alias": "0712345678"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAlias(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder, sender *recordingSender) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			sender := &recordingSender{}
+			server := newTestServer(t, store)
+			server.sender = sender
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/aliases", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder, sender)
+		})
+	}
+}
+
+func TestVerifyAliasAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+
+	code := "123456"
+	hashedCode, err := util.HashPassword(code)
+	require.NoError(t, err)
+
+	alias := db.Alias{
+		ID:            util.RandomInt(1, 1000),
+		Owner:         user.Username,
+		AliasType:     util.AliasTypePhone,
+		Value:         "+254712345678",
+		HashedCode:    hashedCode,
+		CodeExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		code          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			code:     code,
+			buildStubs: func(store *mockdb.MockStore) {
+				verified := alias
+				verified.VerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.EXPECT().GetAlias(gomock.Any(), gomock.Eq(alias.ID)).Times(1).Return(alias, nil)
+				store.EXPECT().VerifyAlias(gomock.Any(), gomock.Eq(alias.ID)).Times(1).Return(verified, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotAlias aliasResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotAlias)
+				require.NoError(t, err)
+				require.True(t, gotAlias.Verified)
+			},
+		},
+		{
+			name:     "WrongCode",
+			username: user.Username,
+			code:     "654321",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAlias(gomock.Any(), gomock.Eq(alias.ID)).Times(1).Return(alias, nil)
+				store.EXPECT().AddAliasFailedAttempt(gomock.Any(), gomock.Eq(alias.ID)).Times(1).Return(alias, nil)
+				store.EXPECT().VerifyAlias(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "TooManyAttempts",
+			username: user.Username,
+			code:     code,
+			buildStubs: func(store *mockdb.MockStore) {
+				locked := alias
+				locked.FailedAttempts = maxCodeAttempts
+				store.EXPECT().GetAlias(gomock.Any(), gomock.Eq(alias.ID)).Times(1).Return(locked, nil)
+				store.EXPECT().VerifyAlias(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "CodeExpired",
+			username: user.Username,
+			code:     code,
+			buildStubs: func(store *mockdb.MockStore) {
+				expired := alias
+				expired.CodeExpiresAt = time.Now().Add(-time.Second)
+				store.EXPECT().GetAlias(gomock.Any(), gomock.Eq(alias.ID)).Times(1).Return(expired, nil)
+				store.EXPECT().VerifyAlias(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "VerifiedByAnotherUserFirst",
+			username: user.Username,
+			code:     code,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAlias(gomock.Any(), gomock.Eq(alias.ID)).Times(1).Return(alias, nil)
+				store.EXPECT().VerifyAlias(gomock.Any(), gomock.Eq(alias.ID)).Times(1).Return(db.Alias{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotOwner",
+			username: other.Username,
+			code:     code,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAlias(gomock.Any(), gomock.Eq(alias.ID)).Times(1).Return(alias, nil)
+				store.EXPECT().VerifyAlias(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidCode",
+			username: user.Username,
+			code:     "12ab",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAlias(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"code": tc.code})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/aliases/%d/verify", alias.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestLookupAliasAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	recipient, _ := randomUser(t)
+	recipient.FullName = "Jane Doe"
+
+	account := createRandomAccount(recipient.Username)
+	account.Currency = util.KES
+
+	testCases := []struct {
+		name          string
+		alias         string
+		currency      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Username",
+			alias:    recipient.Username,
+			currency: util.KES,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.GetDefaultAccountParams{
+					Owner:    recipient.Username,
+					Currency: util.KES,
+				}
+				store.EXPECT().GetVerifiedAlias(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetDefaultAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response lookupAliasResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, "J*** D**", response.RecipientName)
+				require.Equal(t, util.AliasTypeUsername, response.AliasType)
+				require.Equal(t, util.KES, response.Currency)
+				require.NotContains(t, recorder.Body.String(), "account_id")
+			},
+		},
+		{
+			name:     "Email",
+			alias:    "Jane@Example.com",
+			currency: util.KES,
+			buildStubs: func(store *mockdb.MockStore) {
+				aliasArg := db.GetVerifiedAliasParams{
+					AliasType: util.AliasTypeEmail,
+					Value:     "jane@example.com",
+				}
+				store.EXPECT().GetVerifiedAlias(gomock.Any(), gomock.Eq(aliasArg)).Times(1).Return(db.Alias{Owner: recipient.Username}, nil)
+				store.EXPECT().GetDefaultAccount(gomock.Any(), gomock.Any()).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(recipient.Username)).Times(1).Return(recipient, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NoAccountInCurrency",
+			alias:    recipient.Username,
+			currency: util.USD,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetDefaultAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "UnverifiedPhone",
+			alias:    "+254712345678",
+			currency: util.KES,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetVerifiedAlias(gomock.Any(), gomock.Any()).Times(1).Return(db.Alias{}, sql.ErrNoRows)
+				store.EXPECT().GetDefaultAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidCurrency",
+			alias:    recipient.Username,
+			currency: "XYZ",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetDefaultAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			query := url.Values{"alias": {tc.alias}, "currency": {tc.currency}}
+			request, err := http.NewRequest(http.MethodGet, "/aliases/lookup?"+query.Encode(), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestSetDefaultAccountAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+	account := createRandomAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SetDefaultAccountParams{
+					Owner:     user.Username,
+					Currency:  account.Currency,
+					AccountID: account.ID,
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().SetDefaultAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.DefaultAccount{
+					Owner:     arg.Owner,
+					Currency:  arg.Currency,
+					AccountID: arg.AccountID,
+				}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NotOwner",
+			username: other.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().SetDefaultAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "CoOwner",
+			username: other.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{
+					AccountID: account.ID,
+					Username:  other.Username,
+					Role:      util.AccountRoleCoOwner,
+				}, nil)
+				store.EXPECT().SetDefaultAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/default", account.ID)
+			request, err := http.NewRequest(http.MethodPut, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,409 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/token"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// save someone the user pays regularly, by account or by alias, transfers to them are trusted
+// once the cooling-off period is over
+type createBeneficiaryRequest struct {
+	Nickname   string `json:"nickname" binding:"required,max=50"`
+	AccountID  int64  `json:"account_id" binding:"required_without=Alias,min=0"`
+	Alias      string `json:"alias" binding:"required_without=AccountID"`
+	Currency   string `json:"currency" binding:"required,currency"`
+	DailyLimit int64  `json:"daily_limit" binding:"min=0"`
+}
+
+func (server *Server) createBeneficiary(ctx *gin.Context) {
+	var req createBeneficiaryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.AccountID != 0 && req.Alias != "" {
+		err := errors.New("provide either account_id or alias, not both")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	accountID := req.AccountID
+	alias := ""
+	if req.Alias != "" {
+		account, valid := server.resolveAlias(ctx, req.Alias, req.Currency)
+		if !valid {
+			return
+		}
+		accountID = account.ID
+		_, alias, _ = util.ParseAlias(req.Alias)
+	}
+
+	account, valid := server.validAccount(ctx, accountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner == authPayload.Username {
+		err := errors.New("transfers between the user's own accounts are always trusted")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateBeneficiaryParams{
+		Owner:      authPayload.Username,
+		Nickname:   req.Nickname,
+		AccountID:  account.ID,
+		Alias:      alias,
+		Currency:   account.Currency,
+		DailyLimit: req.DailyLimit,
+		TrustedAt:  time.Now().Add(server.config.BeneficiaryCoolingOff),
+	}
+
+	beneficiary, err := server.store.CreateBeneficiary(ctx, arg)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				err := errors.New("beneficiary is already saved or the nickname is taken")
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			case "foreign_key_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, beneficiary)
+}
+
+type beneficiaryURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getBeneficiary(ctx *gin.Context) {
+	var uri beneficiaryURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	beneficiary, valid := server.ownBeneficiary(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, beneficiary)
+}
+
+type listBeneficiariesRequest struct {
+	pageRequest
+}
+
+func (server *Server) listBeneficiaries(ctx *gin.Context) {
+	var req listBeneficiariesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	page, err := req.page()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	beneficiaries, err := server.store.ListBeneficiaries(ctx, db.ListBeneficiariesParams{
+		Owner:      authPayload.Username,
+		AfterID:    page.AfterID,
+		Descending: page.Descending,
+		PageLimit:  page.limit(),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	n, nextCursor := page.next(len(beneficiaries), func(i int) int64 { return beneficiaries[i].ID })
+	setNextCursor(ctx, nextCursor)
+	ctx.JSON(http.StatusOK, beneficiaries[:n])
+}
+
+// rename a beneficiary or change its daily limit, the account it pays can't be changed
+type updateBeneficiaryRequest struct {
+	Nickname   string `json:"nickname" binding:"required,max=50"`
+	DailyLimit int64  `json:"daily_limit" binding:"min=0"`
+}
+
+func (server *Server) updateBeneficiary(ctx *gin.Context) {
+	var uri beneficiaryURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateBeneficiaryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	beneficiary, valid := server.ownBeneficiary(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	beneficiary, err := server.store.UpdateBeneficiary(ctx, db.UpdateBeneficiaryParams{
+		ID:         beneficiary.ID,
+		Nickname:   req.Nickname,
+		DailyLimit: req.DailyLimit,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			err := errors.New("nickname is already taken")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, beneficiary)
+}
+
+func (server *Server) deleteBeneficiary(ctx *gin.Context) {
+	var uri beneficiaryURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	beneficiary, valid := server.ownBeneficiary(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	err := server.store.DeleteBeneficiary(ctx, beneficiary.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "beneficiary deleted"})
+}
+
+// ownBeneficiary makes sure a beneficiary exists and was saved by the authenticated user
+func (server *Server) ownBeneficiary(ctx *gin.Context, id int64) (db.Beneficiary, bool) {
+	beneficiary, err := server.store.GetBeneficiary(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return beneficiary, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return beneficiary, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if beneficiary.Owner != authPayload.Username {
+		err := errors.New("beneficiary doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return beneficiary, false
+	}
+
+	return beneficiary, true
+}
+
+// resolveRecipient fills in the account a transfer request sent to an alias or a saved beneficiary goes to
+func (server *Server) resolveRecipient(ctx *gin.Context, req *transferRequest) bool {
+	provided := 0
+	for _, set := range []bool{req.ToAccountID != 0, req.ToAlias != "", req.BeneficiaryID != 0} {
+		if set {
+			provided++
+		}
+	}
+	if provided > 1 {
+		err := errors.New("provide only one of to_account_id, to_alias or beneficiary_id")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return false
+	}
+
+	switch {
+	case req.ToAlias != "":
+		account, valid := server.resolveAlias(ctx, req.ToAlias, req.Currency)
+		if !valid {
+			return false
+		}
+		req.ToAccountID = account.ID
+	case req.BeneficiaryID != 0:
+		beneficiary, valid := server.ownBeneficiary(ctx, req.BeneficiaryID)
+		if !valid {
+			return false
+		}
+		req.ToAccountID = beneficiary.AccountID
+	}
+
+	return true
+}
+
+// checkRecipient lets transfers, scheduled transfers and holds to the user's own accounts and to trusted
+// beneficiaries through. Anyone else, including beneficiaries still in their cooling-off period, has to be
+// confirmed with a code so a mistyped account can't be paid. A beneficiary's daily limit is checked by the
+// store along with the user's own limits.
+func (server *Server) checkRecipient(ctx *gin.Context, username string, req transferRequest, toAccount db.Account) bool {
+	trusted, err := server.trustedRecipient(ctx, username, toAccount)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+	if !trusted {
+		return server.confirmTransfer(ctx, username, req)
+	}
+
+	return true
+}
+
+// trustedRecipient tells whether toAccount is one of the user's own accounts or a beneficiary past its cooling-off period
+func (server *Server) trustedRecipient(ctx *gin.Context, username string, toAccount db.Account) (bool, error) {
+	if toAccount.Owner == username {
+		return true, nil
+	}
+
+	beneficiary, err := server.store.GetBeneficiaryByAccount(ctx, db.GetBeneficiaryByAccountParams{
+		Owner:     username,
+		AccountID: toAccount.ID,
+	})
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return !time.Now().Before(beneficiary.TrustedAt), nil
+}
+
+// confirmTransfer sends a code for an untrusted transfer to the user's first verified alias, the transfer
+// goes through once it is sent again with the code
+func (server *Server) confirmTransfer(ctx *gin.Context, username string, req transferRequest) bool {
+	if req.ConfirmationCode == "" {
+		server.sendTransferConfirmation(ctx, username, req)
+		return false
+	}
+
+	confirmation, err := server.store.GetTransferConfirmation(ctx, db.GetTransferConfirmationParams{
+		Username:      username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err := errors.New("no confirmation code was sent for this transfer, send it without one to get a code")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if confirmation.FailedAttempts >= maxCodeAttempts || time.Now().After(confirmation.ExpiresAt) {
+		err := errors.New("confirmation code has expired, send the transfer without one to get a new code")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return false
+	}
+
+	err = util.CheckPassword(req.ConfirmationCode, confirmation.HashedCode)
+	if err != nil {
+		_, err = server.store.AddTransferConfirmationFailedAttempt(ctx, confirmation.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return false
+		}
+
+		err := errors.New("wrong confirmation code")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return false
+	}
+
+	// a code only confirms one transfer
+	err = server.store.DeleteTransferConfirmation(ctx, confirmation.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+func (server *Server) sendTransferConfirmation(ctx *gin.Context, username string, req transferRequest) {
+	aliases, err := server.store.ListAliases(ctx, username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var verifiedAlias *db.Alias
+	for i := range aliases {
+		if aliases[i].VerifiedAt.Valid {
+			verifiedAlias = &aliases[i]
+			break
+		}
+	}
+	if verifiedAlias == nil {
+		err := errors.New("recipient isn't a trusted beneficiary, save them as one and wait for the cooling-off period or verify an email or phone number to confirm the transfer")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	code, err := newVerificationCode()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	hashedCode, err := util.HashPassword(code)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.CreateTransferConfirmation(ctx, db.CreateTransferConfirmationParams{
+		Username:      username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		HashedCode:    hashedCode,
+		ExpiresAt:     time.Now().Add(server.config.TransferConfirmationDuration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	message := fmt.Sprintf("Your maimabank code to send %d %s to account %d is %s, it expires in %s", req.Amount, req.Currency, req.ToAccountID, code, server.config.TransferConfirmationDuration)
+	err = server.sender.Send(ctx, verifiedAlias.AliasType, verifiedAlias.Value, message)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusForbidden, gin.H{
+		"error":                 fmt.Sprintf("recipient isn't a trusted beneficiary, a confirmation code was sent to your %s", verifiedAlias.AliasType),
+		"confirmation_required": true,
+	})
+}
@@ -127,7 +127,7 @@ func (server *Server) createExchangeRate(ctx *gin.Context) {
 	}
 
 	// Create exchange rate
-	exchangeRate, err := server.store.CreateExchangeRate(ctx, arg)
+	exchangeRate, err := server.store.CreateExchangeRateTx(ctx, arg)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
@@ -179,7 +179,7 @@ func (server *Server) updateExchangeRate(ctx *gin.Context) {
 	}
 
 	// Update exchange rate
-	exchangeRate, err := server.store.UpdateExchangeRate(ctx, arg)
+	exchangeRate, err := server.store.UpdateExchangeRateTx(ctx, arg)
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
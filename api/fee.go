@@ -0,0 +1,188 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/token"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// create a fee schedule, several schedules with different min_amount make up a tiered schedule
+type createFeeScheduleRequest struct {
+	TransferType  string `json:"transfer_type" binding:"required,transfer_type"`
+	Currency      string `json:"currency" binding:"required,currency"`
+	MinAmount     int64  `json:"min_amount" binding:"min=0"`
+	FlatFee       int64  `json:"flat_fee" binding:"min=0"`
+	PercentageBps int64  `json:"percentage_bps" binding:"min=0,max=10000"`
+	MinFee        int64  `json:"min_fee" binding:"min=0"`
+	MaxFee        int64  `json:"max_fee" binding:"min=0"`
+}
+
+func (server *Server) createFeeSchedule(ctx *gin.Context) {
+	var req createFeeScheduleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != "admin" {
+		err := errors.New("only admin can create fee schedules")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if req.MaxFee > 0 && req.MaxFee < req.MinFee {
+		err := errors.New("max fee cannot be less than min fee")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateFeeScheduleParams{
+		TransferType:  req.TransferType,
+		Currency:      req.Currency,
+		MinAmount:     req.MinAmount,
+		FlatFee:       req.FlatFee,
+		PercentageBps: req.PercentageBps,
+		MinFee:        req.MinFee,
+		MaxFee:        req.MaxFee,
+	}
+
+	schedule, err := server.store.CreateFeeSchedule(ctx, arg)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				err := errors.New("fee schedule already exists for this tier")
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedule)
+}
+
+// list fee schedules
+func (server *Server) listFeeSchedules(ctx *gin.Context) {
+	schedules, err := server.store.ListFeeSchedules(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedules)
+}
+
+type deleteFeeRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) deleteFeeSchedule(ctx *gin.Context) {
+	var req deleteFeeRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != "admin" {
+		err := errors.New("only admin can delete fee schedules")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	err := server.store.DeleteFeeSchedule(ctx, req.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "fee schedule deleted"})
+}
+
+// waive fees of a transfer type for every account of an account type
+type createFeeWaiverRequest struct {
+	AccountType  string `json:"account_type" binding:"required"`
+	TransferType string `json:"transfer_type" binding:"required,transfer_type"`
+}
+
+func (server *Server) createFeeWaiver(ctx *gin.Context) {
+	var req createFeeWaiverRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != "admin" {
+		err := errors.New("only admin can create fee waivers")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if !util.IsSupportedAccountType(req.AccountType) {
+		err := errors.New("account type not supported")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	waiver, err := server.store.CreateFeeWaiver(ctx, db.CreateFeeWaiverParams{
+		AccountType:  req.AccountType,
+		TransferType: req.TransferType,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				err := errors.New("fee waiver already exists")
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, waiver)
+}
+
+// list fee waivers
+func (server *Server) listFeeWaivers(ctx *gin.Context) {
+	waivers, err := server.store.ListFeeWaivers(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, waivers)
+}
+
+func (server *Server) deleteFeeWaiver(ctx *gin.Context) {
+	var req deleteFeeRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != "admin" {
+		err := errors.New("only admin can delete fee waivers")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	err := server.store.DeleteFeeWaiver(ctx, req.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "fee waiver deleted"})
+}
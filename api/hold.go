@@ -0,0 +1,240 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/token"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// place a hold on the sender's available balance, the money only moves once the hold is captured
+type createHoldRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+	// seconds until the hold expires, defaults to the configured hold duration
+	ExpiresIn int64 `json:"expires_in" binding:"min=0"`
+	// code sent to the user to confirm a hold for a recipient that isn't trusted yet
+	ConfirmationCode string `json:"confirmation_code" binding:"omitempty,len=6,numeric"`
+}
+
+func (server *Server) createHold(ctx *gin.Context) {
+	var req createHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, fromAccount, util.AccountPermissionPay, req.Amount) {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if fromAccount.ID == req.ToAccountID {
+		err := errors.New("from account cannot be equal to to account")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	toAccount, valid := server.validAccount(ctx, req.ToAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	expiresIn := server.config.HoldDuration
+	if req.ExpiresIn > 0 {
+		expiresIn = time.Duration(req.ExpiresIn) * time.Second
+	}
+	if expiresIn > server.config.HoldDuration {
+		err := errors.New("hold cannot last longer than " + server.config.HoldDuration.String())
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// a captured hold pays the recipient like a transfer, so it is confirmed like one
+	if !server.checkRecipient(ctx, authPayload.Username, transferRequest{
+		FromAccountID:    req.FromAccountID,
+		ToAccountID:      req.ToAccountID,
+		Amount:           req.Amount,
+		Currency:         req.Currency,
+		ConfirmationCode: req.ConfirmationCode,
+	}, toAccount) {
+		return
+	}
+
+	quote, valid := server.quoteFee(ctx, fromAccount, util.TransferTypeTransfer, req.Amount)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.CreateHoldTx(ctx, db.CreateHoldTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Fee:           quote.Fee,
+		ExpiresAt:     time.Now().Add(expiresIn),
+		LimitUsername: authPayload.Username,
+	})
+	if err != nil {
+		transferError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type holdURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getHold(ctx *gin.Context) {
+	var uri holdURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, valid := server.holdParty(ctx, uri.ID, util.AccountPermissionView, util.AccountPermissionView)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+}
+
+// capture all or part of a hold, an amount of 0 captures the whole hold
+type captureHoldRequest struct {
+	Amount int64 `json:"amount" binding:"min=0"`
+}
+
+func (server *Server) captureHold(ctx *gin.Context) {
+	var uri holdURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// the request body is optional
+	var req captureHoldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// capturing moves the sender's money, so only someone who can pay from the sending account can
+	hold, valid := server.holdParty(ctx, uri.ID, util.AccountPermissionPay, "")
+	if !valid {
+		return
+	}
+
+	result, err := server.store.CaptureHoldTx(ctx, db.CaptureHoldTxParams{
+		HoldID: hold.ID,
+		Amount: req.Amount,
+	})
+	if err != nil {
+		holdErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) voidHold(ctx *gin.Context) {
+	var uri holdURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// the sender can call off a payment, the recipient can turn one down
+	hold, valid := server.holdParty(ctx, uri.ID, util.AccountPermissionPay, util.AccountPermissionManage)
+	if !valid {
+		return
+	}
+
+	hold, err := server.store.VoidHoldTx(ctx, hold.ID)
+	if err != nil {
+		holdErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, hold)
+}
+
+// holdParty makes sure the authenticated user has fromPermission on the sending account or
+// toPermission on the receiving account of a hold, an empty permission rules that account out.
+// Paying from the sending account is held to the member's payment limit for the whole hold.
+func (server *Server) holdParty(ctx *gin.Context, holdID int64, fromPermission string, toPermission string) (db.Hold, bool) {
+	hold, err := server.store.GetHold(ctx, holdID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return hold, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return hold, false
+	}
+
+	parties := []struct {
+		accountID  int64
+		permission string
+	}{
+		{hold.FromAccountID, fromPermission},
+		{hold.ToAccountID, toPermission},
+	}
+
+	// the sending account's error is reported when neither account allows it
+	status, deniedErr := http.StatusUnauthorized, errors.New("hold doesn't belong to the authenticated user")
+	for i, party := range parties {
+		if party.permission == "" {
+			continue
+		}
+
+		account, err := server.store.GetAccount(ctx, party.accountID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return hold, false
+		}
+
+		accessStatus, err := server.accountAccess(ctx, account, party.permission, hold.Amount)
+		if err == nil {
+			return hold, true
+		}
+		if accessStatus == http.StatusInternalServerError {
+			ctx.JSON(accessStatus, errorResponse(err))
+			return hold, false
+		}
+		if i == 0 {
+			status, deniedErr = accessStatus, err
+		}
+	}
+
+	ctx.JSON(status, errorResponse(deniedErr))
+	return hold, false
+}
+
+func holdErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	case errors.Is(err, db.ErrHoldNotPending),
+		errors.Is(err, db.ErrHoldExpired),
+		errors.Is(err, db.ErrCaptureAmountTooLarge):
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+	default:
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	}
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/token"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// ops staff can see the bank's chart of accounts with their balances
+func (server *Server) listLedgerAccounts(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !server.requireRole(ctx, authPayload.Username, util.OpsRole) {
+		return
+	}
+
+	accounts, err := server.store.ListInternalAccounts(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accounts)
+}
+
+// ops staff can check the ledger against itself, discrepancies are reported rather than treated as errors.
+// Seal signatures are only checked when the server has the ledger signing key.
+func (server *Server) verifyLedger(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !server.requireRole(ctx, authPayload.Username, util.OpsRole) {
+		return
+	}
+
+	report, err := server.store.VerifyLedger(ctx, server.ledgerPublicKey)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+type listLedgerSealsResponse struct {
+	PublicKey string          `json:"public_key,omitempty"`
+	Seals     []db.LedgerSeal `json:"seals"`
+}
+
+// ops staff can list the daily ledger seals along with the public key to check their signatures with
+func (server *Server) listLedgerSeals(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !server.requireRole(ctx, authPayload.Username, util.OpsRole) {
+		return
+	}
+
+	seals, err := server.store.ListLedgerSeals(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, listLedgerSealsResponse{
+		PublicKey: hex.EncodeToString(server.ledgerPublicKey),
+		Seals:     seals,
+	})
+}
+
+// post a journal by hand, e.g. interest or an adjustment through suspense.
+// Its lines have to sum to zero in every currency.
+type journalLineRequest struct {
+	AccountID int64 `json:"account_id" binding:"required,min=1"`
+	// positive credits the account, negative debits it
+	Amount int64 `json:"amount" binding:"required"`
+}
+
+type postJournalRequest struct {
+	Kind  string               `json:"kind" binding:"required,journal_kind"`
+	Memo  string               `json:"memo" binding:"max=200"`
+	Lines []journalLineRequest `json:"lines" binding:"required,min=2,max=20,dive"`
+}
+
+func (server *Server) postJournal(ctx *gin.Context) {
+	var req postJournalRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !server.requireRole(ctx, authPayload.Username, util.OpsRole) {
+		return
+	}
+
+	arg := db.PostJournalTxParams{
+		Kind:      req.Kind,
+		Memo:      req.Memo,
+		CreatedBy: authPayload.Username,
+	}
+	for _, line := range req.Lines {
+		arg.Lines = append(arg.Lines, db.JournalLine{
+			AccountID: line.AccountID,
+			Amount:    line.Amount,
+		})
+	}
+
+	result, err := server.store.PostJournalTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrUnbalancedJournal) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation", "check_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type getJournalRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// ops staff can look at any journal with all of its entries
+func (server *Server) getJournal(ctx *gin.Context) {
+	var req getJournalRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !server.requireRole(ctx, authPayload.Username, util.OpsRole) {
+		return
+	}
+
+	journal, err := server.store.GetJournal(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	entries, err := server.store.ListJournalEntries(ctx, sql.NullInt64{Int64: journal.ID, Valid: true})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, db.JournalResult{
+		Journal: journal,
+		Entries: entries,
+	})
+}
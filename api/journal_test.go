@@ -0,0 +1,366 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/malcolmmaima/maimabank/db/mock"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostJournalAPI(t *testing.T) {
+	opsUser, _ := randomUser(t)
+	opsUser.Role = util.OpsRole
+	customer, _ := randomUser(t)
+	customer.Role = util.CustomerRole
+
+	lines := []gin.H{
+		{"account_id": 1, "amount": 5},
+		{"account_id": 2, "amount": -5},
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: opsUser.Username,
+			body:     gin.H{"kind": util.JournalKindInterest, "memo": "monthly interest", "lines": lines},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(opsUser.Username)).Times(1).Return(opsUser, nil)
+
+				arg := db.PostJournalTxParams{
+					Kind:      util.JournalKindInterest,
+					Memo:      "monthly interest",
+					CreatedBy: opsUser.Username,
+					Lines: []db.JournalLine{
+						{AccountID: 1, Amount: 5},
+						{AccountID: 2, Amount: -5},
+					},
+				}
+				store.EXPECT().PostJournalTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.JournalResult{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Unbalanced",
+			username: opsUser.Username,
+			body:     gin.H{"kind": util.JournalKindAdjustment, "lines": lines},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(opsUser.Username)).Times(1).Return(opsUser, nil)
+				store.EXPECT().PostJournalTx(gomock.Any(), gomock.Any()).Times(1).Return(db.JournalResult{}, db.ErrUnbalancedJournal)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotOps",
+			username: customer.Username,
+			body:     gin.H{"kind": util.JournalKindAdjustment, "lines": lines},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(customer.Username)).Times(1).Return(customer, nil)
+				store.EXPECT().PostJournalTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "TransferKind",
+			username: opsUser.Username,
+			body:     gin.H{"kind": util.TransferKindTransfer, "lines": lines},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().PostJournalTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "SingleLine",
+			username: opsUser.Username,
+			body:     gin.H{"kind": util.JournalKindAdjustment, "lines": lines[:1]},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().PostJournalTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "ZeroAmount",
+			username: opsUser.Username,
+			body: gin.H{"kind": util.JournalKindAdjustment, "lines": []gin.H{
+				{"account_id": 1, "amount": 0},
+				{"account_id": 2, "amount": 0},
+			}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().PostJournalTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/journals", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestGetJournalAPI(t *testing.T) {
+	opsUser, _ := randomUser(t)
+	opsUser.Role = util.OpsRole
+
+	journal := db.Journal{
+		ID:   util.RandomInt(1, 1000),
+		Kind: util.TransferKindTransfer,
+	}
+	entries := []db.Entry{
+		{ID: 1, AccountID: 1, Amount: -10, JournalID: sql.NullInt64{Int64: journal.ID, Valid: true}},
+		{ID: 2, AccountID: 2, Amount: 10, JournalID: sql.NullInt64{Int64: journal.ID, Valid: true}},
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(opsUser.Username)).Times(1).Return(opsUser, nil)
+				store.EXPECT().GetJournal(gomock.Any(), gomock.Eq(journal.ID)).Times(1).Return(journal, nil)
+				store.EXPECT().ListJournalEntries(gomock.Any(), gomock.Eq(sql.NullInt64{Int64: journal.ID, Valid: true})).Times(1).Return(entries, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.JournalResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &result)
+				require.NoError(t, err)
+				require.Equal(t, journal.ID, result.Journal.ID)
+				require.Equal(t, entries, result.Entries)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(opsUser.Username)).Times(1).Return(opsUser, nil)
+				store.EXPECT().GetJournal(gomock.Any(), gomock.Eq(journal.ID)).Times(1).Return(db.Journal{}, sql.ErrNoRows)
+				store.EXPECT().ListJournalEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/journals/%d", journal.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, opsUser.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestVerifyLedgerAPI(t *testing.T) {
+	opsUser, _ := randomUser(t)
+	opsUser.Role = util.OpsRole
+	customer, _ := randomUser(t)
+	customer.Role = util.CustomerRole
+
+	report := db.LedgerReport{
+		AccountBalances: []db.ListAccountBalanceMismatchesRow{
+			{AccountID: 1, Currency: util.USD, Balance: 100, EntriesBalance: 90},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: opsUser,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(opsUser.Username)).Times(1).Return(opsUser, nil)
+				store.EXPECT().VerifyLedger(gomock.Any(), gomock.Any()).Times(1).Return(report, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.LedgerReport
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.False(t, got.Consistent)
+				require.Equal(t, report.AccountBalances, got.AccountBalances)
+			},
+		},
+		{
+			name: "NotOps",
+			user: customer,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(customer.Username)).Times(1).Return(customer, nil)
+				store.EXPECT().VerifyLedger(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/ledger/verify", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestListLedgerSealsAPI(t *testing.T) {
+	opsUser, _ := randomUser(t)
+	opsUser.Role = util.OpsRole
+	customer, _ := randomUser(t)
+	customer.Role = util.CustomerRole
+
+	seals := []db.LedgerSeal{
+		{
+			Day:           time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			EntryCount:    4,
+			TransferCount: 1,
+			MerkleRoot:    []byte(util.RandomString(32)),
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(server *Server, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: opsUser,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(opsUser.Username)).Times(1).Return(opsUser, nil)
+				store.EXPECT().ListLedgerSeals(gomock.Any()).Times(1).Return(seals, nil)
+			},
+			checkResponse: func(server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got listLedgerSealsResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, hex.EncodeToString(server.ledgerPublicKey), got.PublicKey)
+				require.Len(t, got.Seals, 1)
+				require.Equal(t, seals[0].MerkleRoot, got.Seals[0].MerkleRoot)
+			},
+		},
+		{
+			name: "NotOps",
+			user: customer,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(customer.Username)).Times(1).Return(customer, nil)
+				store.EXPECT().ListLedgerSeals(gomock.Any()).Times(0)
+			},
+			checkResponse: func(server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/ledger/seals", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(server, recorder)
+		})
+	}
+}
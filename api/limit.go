@@ -0,0 +1,238 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/token"
+)
+
+// checkTransferLimit makes sure username can still send amount in currency today and this month
+func (server *Server) checkTransferLimit(ctx *gin.Context, username string, currency string, amount int64) bool {
+	allowance, err := server.store.GetTransferAllowance(ctx, db.GetTransferAllowanceParams{
+		Username: username,
+		Currency: currency,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if err := allowance.Check(amount); err != nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+// transferError responds with the error a transfer failed with, one over the user's limits
+// or the sender's available balance is forbidden
+func transferError(ctx *gin.Context, err error) {
+	if errors.Is(err, db.ErrTransferLimitExceeded) || errors.Is(err, db.ErrInsufficientAvailableBalance) {
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+}
+
+// get the authenticated user's limits and remaining allowance in a currency
+type getTransferLimitsRequest struct {
+	Currency string `form:"currency" binding:"required,currency"`
+}
+
+func (server *Server) getTransferLimits(ctx *gin.Context) {
+	var req getTransferLimitsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	allowance, err := server.store.GetTransferAllowance(ctx, db.GetTransferAllowanceParams{
+		Username: authPayload.Username,
+		Currency: req.Currency,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, allowance)
+}
+
+// set the limits of a tier in a currency, 0 means no limit
+type upsertTransferLimitRequest struct {
+	Tier           string `json:"tier" binding:"required,tier"`
+	Currency       string `json:"currency" binding:"required,currency"`
+	PerTransaction int64  `json:"per_transaction" binding:"min=0"`
+	Daily          int64  `json:"daily" binding:"min=0"`
+	Monthly        int64  `json:"monthly" binding:"min=0"`
+}
+
+func (server *Server) upsertTransferLimit(ctx *gin.Context) {
+	var req upsertTransferLimitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != "admin" {
+		err := errors.New("only admin can set transfer limits")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	limit, err := server.store.UpsertTransferLimit(ctx, db.UpsertTransferLimitParams{
+		Tier:           req.Tier,
+		Currency:       req.Currency,
+		PerTransaction: req.PerTransaction,
+		Daily:          req.Daily,
+		Monthly:        req.Monthly,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, limit)
+}
+
+// list the limits of every tier
+func (server *Server) listTransferLimits(ctx *gin.Context) {
+	limits, err := server.store.ListTransferLimits(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, limits)
+}
+
+// override the tier limits of a single user in a currency
+type upsertTransferLimitOverrideRequest struct {
+	Username       string `json:"username" binding:"required,alphanum"`
+	Currency       string `json:"currency" binding:"required,currency"`
+	PerTransaction int64  `json:"per_transaction" binding:"min=0"`
+	Daily          int64  `json:"daily" binding:"min=0"`
+	Monthly        int64  `json:"monthly" binding:"min=0"`
+}
+
+func (server *Server) upsertTransferLimitOverride(ctx *gin.Context) {
+	var req upsertTransferLimitOverrideRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != "admin" {
+		err := errors.New("only admin can override transfer limits")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	override, err := server.store.UpsertTransferLimitOverride(ctx, db.UpsertTransferLimitOverrideParams{
+		Username:       req.Username,
+		Currency:       req.Currency,
+		PerTransaction: req.PerTransaction,
+		Daily:          req.Daily,
+		Monthly:        req.Monthly,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation":
+				err := errors.New("user not found")
+				ctx.JSON(http.StatusNotFound, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, override)
+}
+
+// remove a user's override so the tier limits apply again
+type deleteTransferLimitOverrideRequest struct {
+	Username string `form:"username" binding:"required,alphanum"`
+	Currency string `form:"currency" binding:"required,currency"`
+}
+
+func (server *Server) deleteTransferLimitOverride(ctx *gin.Context) {
+	var req deleteTransferLimitOverrideRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != "admin" {
+		err := errors.New("only admin can remove transfer limit overrides")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	err := server.store.DeleteTransferLimitOverride(ctx, db.DeleteTransferLimitOverrideParams{
+		Username: req.Username,
+		Currency: req.Currency,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "transfer limit override removed"})
+}
+
+// move a user to another tier
+type updateUserTierURI struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+type updateUserTierRequest struct {
+	Tier string `json:"tier" binding:"required,tier"`
+}
+
+func (server *Server) updateUserTier(ctx *gin.Context) {
+	var uri updateUserTierURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateUserTierRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != "admin" {
+		err := errors.New("only admin can change user tiers")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.UpdateUserTier(ctx, db.UpdateUserTierParams{
+		Username: uri.Username,
+		Tier:     req.Tier,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
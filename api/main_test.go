@@ -7,17 +7,24 @@ import (
 
 	"github.com/gin-gonic/gin"
 	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/notify"
 	"github.com/malcolmmaima/maimabank/util"
 	"github.com/stretchr/testify/require"
 )
 
 func newTestServer(t *testing.T, store db.Store) *Server {
 	config := util.Config{
-		TokenSymmetricKey:   util.RandomString(32),
-		AccessTokenDuration: time.Minute,
+		TokenSymmetricKey:            util.RandomString(32),
+		AccessTokenDuration:          time.Minute,
+		HoldDuration:                 time.Hour,
+		AliasCodeDuration:            10 * time.Minute,
+		BeneficiaryCoolingOff:        24 * time.Hour,
+		TransferConfirmationDuration: 5 * time.Minute,
+		PaymentRequestDuration:       24 * time.Hour,
+		LedgerSigningKey:             util.RandomString(32),
 	}
 
-	server, err := NewServer(config, store)
+	server, err := NewServer(config, store, notify.NewLogSender())
 	require.NoError(t, err)
 
 	return server
@@ -0,0 +1,565 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/token"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// errBusinessAccountPayment is returned when a business account is used to pay outside its organization's approvals
+var errBusinessAccountPayment = errors.New("payments from a business account have to go through its organization's transfers")
+
+type createOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	// transfers above this amount wait for approval, 0 means every transfer does
+	ApprovalThreshold int64 `json:"approval_threshold" binding:"min=0"`
+	RequiredApprovals int32 `json:"required_approvals" binding:"required,min=1"`
+}
+
+func (server *Server) createOrganization(ctx *gin.Context) {
+	var req createOrganizationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	organization, err := server.store.CreateOrganizationTx(ctx, db.CreateOrganizationTxParams{
+		Name:              req.Name,
+		ApprovalThreshold: req.ApprovalThreshold,
+		RequiredApprovals: req.RequiredApprovals,
+		CreatedBy:         authPayload.Username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, organization)
+}
+
+func (server *Server) listOrganizations(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	organizations, err := server.store.ListOrganizations(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, organizations)
+}
+
+type organizationURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getOrganization(ctx *gin.Context) {
+	var uri organizationURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.organizationMember(ctx, uri.ID, ""); !valid {
+		return
+	}
+
+	organization, err := server.store.GetOrganization(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, organization)
+}
+
+type addOrganizationMemberRequest struct {
+	Username string `json:"username" binding:"required,alphanum"`
+	Role     string `json:"role" binding:"required,organization_role"`
+}
+
+func (server *Server) addOrganizationMember(ctx *gin.Context) {
+	var uri organizationURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req addOrganizationMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.organizationMember(ctx, uri.ID, util.OrganizationPermissionManage); !valid {
+		return
+	}
+
+	member, err := server.store.CreateOrganizationMember(ctx, db.CreateOrganizationMemberParams{
+		OrganizationID: uri.ID,
+		Username:       req.Username,
+		Role:           req.Role,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				err := errors.New("user is already a member of the organization")
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			case "foreign_key_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+func (server *Server) listOrganizationMembers(ctx *gin.Context) {
+	var uri organizationURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.organizationMember(ctx, uri.ID, ""); !valid {
+		return
+	}
+
+	members, err := server.store.ListOrganizationMembers(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, members)
+}
+
+type organizationMemberURI struct {
+	ID       int64  `uri:"id" binding:"required,min=1"`
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+// remove a member, admins can remove anyone and members can leave on their own
+func (server *Server) removeOrganizationMember(ctx *gin.Context) {
+	var uri organizationMemberURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	permission := util.OrganizationPermissionManage
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if uri.Username == authPayload.Username {
+		permission = ""
+	}
+	if _, valid := server.organizationMember(ctx, uri.ID, permission); !valid {
+		return
+	}
+
+	err := server.store.DeleteOrganizationMember(ctx, db.DeleteOrganizationMemberParams{
+		OrganizationID: uri.ID,
+		Username:       uri.Username,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "member removed"})
+}
+
+// open a business account for the organization, the admin opening it is its owner
+type createOrganizationAccountRequest struct {
+	Currency string `json:"currency" binding:"required,currency"`
+}
+
+func (server *Server) createOrganizationAccount(ctx *gin.Context) {
+	var uri organizationURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req createOrganizationAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	member, valid := server.organizationMember(ctx, uri.ID, util.OrganizationPermissionManage)
+	if !valid {
+		return
+	}
+
+	account, err := server.store.CreateOrganizationAccountTx(ctx, db.CreateOrganizationAccountParams{
+		Owner:          member.Username,
+		Currency:       req.Currency,
+		OrganizationID: sql.NullInt64{Int64: uri.ID, Valid: true},
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, account)
+}
+
+func (server *Server) listOrganizationAccounts(ctx *gin.Context) {
+	var uri organizationURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.organizationMember(ctx, uri.ID, ""); !valid {
+		return
+	}
+
+	accounts, err := server.store.ListOrganizationAccounts(ctx, sql.NullInt64{Int64: uri.ID, Valid: true})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accounts)
+}
+
+type organizationTransferRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+	Memo          string `json:"memo" binding:"max=140"`
+}
+
+// createOrganizationTransfer pays from a business account. Transfers up to the organization's approval
+// threshold are executed straight away, larger ones are held as pending approval and answered with 202.
+func (server *Server) createOrganizationTransfer(ctx *gin.Context) {
+	var uri organizationURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req organizationTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	member, valid := server.organizationMember(ctx, uri.ID, util.OrganizationPermissionInitiate)
+	if !valid {
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if fromAccount.OrganizationID.Int64 != uri.ID {
+		err := fmt.Errorf("account [%d] doesn't belong to the organization", fromAccount.ID)
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	if fromAccount.ID == req.ToAccountID {
+		err := errors.New("from account cannot be equal to to account")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.validAccount(ctx, req.ToAccountID, req.Currency); !valid {
+		return
+	}
+
+	organization, err := server.store.GetOrganization(ctx, uri.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if req.Amount > organization.ApprovalThreshold {
+		// checked now so the initiator finds out before asking for approvals, and again when it is executed
+		if !server.checkTransferLimit(ctx, member.Username, req.Currency, req.Amount) {
+			return
+		}
+
+		pending, err := server.store.CreateOrganizationTransfer(ctx, db.CreateOrganizationTransferParams{
+			OrganizationID: uri.ID,
+			FromAccountID:  fromAccount.ID,
+			ToAccountID:    req.ToAccountID,
+			Amount:         req.Amount,
+			Memo:           req.Memo,
+			InitiatedBy:    member.Username,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, pending)
+		return
+	}
+
+	quote, valid := server.quoteFee(ctx, fromAccount, util.TransferTypeTransfer, req.Amount)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Fee:           quote.Fee,
+		FeeAccountID:  quote.FeeAccountID,
+		Memo:          req.Memo,
+		LimitUsername: member.Username,
+	})
+	if err != nil {
+		transferError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// list the organization's transfers newest first, optionally only those with a status
+type listOrganizationTransfersRequest struct {
+	pageRequest
+	Status string `form:"status" binding:"omitempty,oneof=pending_approval executed rejected"`
+}
+
+func (server *Server) listOrganizationTransfers(ctx *gin.Context) {
+	var uri organizationURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listOrganizationTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	page, err := req.pageSorted(sortDescending)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.organizationMember(ctx, uri.ID, ""); !valid {
+		return
+	}
+
+	transfers, err := server.store.ListOrganizationTransfers(ctx, db.ListOrganizationTransfersParams{
+		OrganizationID: uri.ID,
+		Status:         req.Status,
+		AfterID:        page.AfterID,
+		Descending:     page.Descending,
+		PageLimit:      page.limit(),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	n, nextCursor := page.next(len(transfers), func(i int) int64 { return transfers[i].ID })
+	setNextCursor(ctx, nextCursor)
+	ctx.JSON(http.StatusOK, transfers[:n])
+}
+
+type organizationTransferURI struct {
+	ID         int64 `uri:"id" binding:"required,min=1"`
+	TransferID int64 `uri:"transfer_id" binding:"required,min=1"`
+}
+
+type organizationTransferResponse struct {
+	OrganizationTransfer db.OrganizationTransfer           `json:"organization_transfer"`
+	Approvals            []db.OrganizationTransferApproval `json:"approvals"`
+}
+
+func (server *Server) getOrganizationTransfer(ctx *gin.Context) {
+	var uri organizationTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.organizationMember(ctx, uri.ID, ""); !valid {
+		return
+	}
+
+	pending, valid := server.organizationTransfer(ctx, uri)
+	if !valid {
+		return
+	}
+
+	approvals, err := server.store.ListOrganizationTransferApprovals(ctx, pending.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, organizationTransferResponse{
+		OrganizationTransfer: pending,
+		Approvals:            approvals,
+	})
+}
+
+// approveOrganizationTransfer adds the authenticated user's approval, the transfer is executed
+// through the same transfer as any other once enough approvers have signed off
+func (server *Server) approveOrganizationTransfer(ctx *gin.Context) {
+	var uri organizationTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	member, valid := server.organizationMember(ctx, uri.ID, util.OrganizationPermissionApprove)
+	if !valid {
+		return
+	}
+
+	pending, valid := server.organizationTransfer(ctx, uri)
+	if !valid {
+		return
+	}
+
+	if pending.InitiatedBy == member.Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(db.ErrSelfApproval))
+		return
+	}
+
+	fromAccount, err := server.store.GetAccount(ctx, pending.FromAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	quote, valid := server.quoteFee(ctx, fromAccount, util.TransferTypeTransfer, pending.Amount)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.ApproveOrganizationTransferTx(ctx, db.ApproveOrganizationTransferTxParams{
+		OrganizationTransferID: pending.ID,
+		Approver:               member.Username,
+		Fee:                    quote.Fee,
+		FeeAccountID:           quote.FeeAccountID,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrOrganizationTransferNotPending) ||
+			errors.Is(err, db.ErrSelfApproval) ||
+			errors.Is(err, db.ErrAlreadyApproved) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		transferError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// rejectOrganizationTransfer stops a pending transfer, any approver or its initiator can reject it
+func (server *Server) rejectOrganizationTransfer(ctx *gin.Context) {
+	var uri organizationTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	member, valid := server.organizationMember(ctx, uri.ID, "")
+	if !valid {
+		return
+	}
+
+	pending, valid := server.organizationTransfer(ctx, uri)
+	if !valid {
+		return
+	}
+
+	if pending.InitiatedBy != member.Username && !util.OrganizationRoleAllows(member.Role, util.OrganizationPermissionApprove) {
+		err := errors.New("only an approver or the initiator can reject a transfer")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	rejected, err := server.store.CloseOrganizationTransfer(ctx, db.CloseOrganizationTransferParams{
+		ID:     pending.ID,
+		Status: util.OrganizationTransferStatusRejected,
+	})
+	if err != nil {
+		// executed or rejected since it was read
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusForbidden, errorResponse(db.ErrOrganizationTransferNotPending))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rejected)
+}
+
+// organizationMember makes sure the authenticated user is a member of the organization
+// and, unless permission is empty, that their role allows it
+func (server *Server) organizationMember(ctx *gin.Context, organizationID int64, permission string) (db.OrganizationMember, bool) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	member, err := server.store.GetOrganizationMember(ctx, db.GetOrganizationMemberParams{
+		OrganizationID: organizationID,
+		Username:       authPayload.Username,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err := fmt.Errorf("authenticated user isn't a member of organization [%d]", organizationID)
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return member, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return member, false
+	}
+
+	if permission != "" && !util.OrganizationRoleAllows(member.Role, permission) {
+		err := fmt.Errorf("an organization %s can't %s", member.Role, permission)
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return member, false
+	}
+
+	return member, true
+}
+
+// organizationTransfer loads a transfer, answering 404 unless it belongs to the organization in the uri
+func (server *Server) organizationTransfer(ctx *gin.Context, uri organizationTransferURI) (db.OrganizationTransfer, bool) {
+	pending, err := server.store.GetOrganizationTransfer(ctx, uri.TransferID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return pending, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return pending, false
+	}
+
+	if pending.OrganizationID != uri.ID {
+		err := fmt.Errorf("transfer [%d] doesn't belong to organization [%d]", pending.ID, uri.ID)
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		return pending, false
+	}
+
+	return pending, true
+}
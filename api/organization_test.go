@@ -0,0 +1,322 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/malcolmmaima/maimabank/db/mock"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomOrganization(createdBy string) db.Organization {
+	return db.Organization{
+		ID:                util.RandomInt(1, 1000),
+		Name:              util.RandomOwner(),
+		ApprovalThreshold: 1000,
+		RequiredApprovals: 2,
+		CreatedBy:         createdBy,
+	}
+}
+
+func TestCreateOrganizationTransferAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	initiator, _ := randomUser(t)
+	recipient, _ := randomUser(t)
+
+	organization := randomOrganization(admin.Username)
+
+	fromAccount := createRandomAccount(admin.Username)
+	fromAccount.Currency = util.USD
+	fromAccount.OrganizationID = sql.NullInt64{Int64: organization.ID, Valid: true}
+	toAccount := createRandomAccount(recipient.Username)
+	toAccount.Currency = util.USD
+
+	memberArg := db.GetOrganizationMemberParams{OrganizationID: organization.ID, Username: initiator.Username}
+	member := db.OrganizationMember{
+		OrganizationID: organization.ID,
+		Username:       initiator.Username,
+		Role:           util.OrganizationRoleInitiator,
+	}
+
+	testCases := []struct {
+		name          string
+		amount        int64
+		fromAccount   db.Account
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "BelowThreshold",
+			amount:      organization.ApprovalThreshold,
+			fromAccount: fromAccount,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Eq(memberArg)).Times(1).Return(member, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetOrganization(gomock.Any(), gomock.Eq(organization.ID)).Times(1).Return(organization, nil)
+				store.EXPECT().QuoteFee(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeQuote{}, nil)
+				store.EXPECT().CreateOrganizationTransfer(gomock.Any(), gomock.Any()).Times(0)
+
+				arg := db.TransferTxParams{
+					FromAccountID: fromAccount.ID,
+					ToAccountID:   toAccount.ID,
+					Amount:        organization.ApprovalThreshold,
+					LimitUsername: member.Username,
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:        "AboveThreshold",
+			amount:      organization.ApprovalThreshold + 1,
+			fromAccount: fromAccount,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Eq(memberArg)).Times(1).Return(member, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetTransferAllowance(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferAllowance{}, nil)
+				store.EXPECT().GetOrganization(gomock.Any(), gomock.Eq(organization.ID)).Times(1).Return(organization, nil)
+
+				arg := db.CreateOrganizationTransferParams{
+					OrganizationID: organization.ID,
+					FromAccountID:  fromAccount.ID,
+					ToAccountID:    toAccount.ID,
+					Amount:         organization.ApprovalThreshold + 1,
+					InitiatedBy:    initiator.Username,
+				}
+				store.EXPECT().CreateOrganizationTransfer(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.OrganizationTransfer{ID: 1, Status: util.OrganizationTransferStatusPending}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var pending db.OrganizationTransfer
+				err := json.Unmarshal(recorder.Body.Bytes(), &pending)
+				require.NoError(t, err)
+				require.Equal(t, util.OrganizationTransferStatusPending, pending.Status)
+			},
+		},
+		{
+			name:        "NotAMember",
+			amount:      10,
+			fromAccount: fromAccount,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(db.OrganizationMember{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:        "PersonalAccount",
+			amount:      10,
+			fromAccount: toAccount,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(member, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"from_account_id": tc.fromAccount.ID,
+				"to_account_id":   toAccount.ID,
+				"amount":          tc.amount,
+				"currency":        util.USD,
+			})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/organizations/%d/transfers", organization.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, initiator.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestApproveOrganizationTransferAPI(t *testing.T) {
+	admin, _ := randomUser(t)
+	approver, _ := randomUser(t)
+	initiator, _ := randomUser(t)
+
+	organization := randomOrganization(admin.Username)
+	fromAccount := createRandomAccount(admin.Username)
+	fromAccount.OrganizationID = sql.NullInt64{Int64: organization.ID, Valid: true}
+
+	pending := db.OrganizationTransfer{
+		ID:             util.RandomInt(1, 1000),
+		OrganizationID: organization.ID,
+		FromAccountID:  fromAccount.ID,
+		ToAccountID:    util.RandomInt(1, 1000),
+		Amount:         5000,
+		InitiatedBy:    approver.Username,
+		Status:         util.OrganizationTransferStatusPending,
+	}
+	quote := db.FeeQuote{Fee: 2, FeeAccountID: 99}
+
+	membership := func(username string, role string) db.OrganizationMember {
+		return db.OrganizationMember{OrganizationID: organization.ID, Username: username, Role: role}
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: admin.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(membership(admin.Username, util.OrganizationRoleAdmin), nil)
+				store.EXPECT().GetOrganizationTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().QuoteFee(gomock.Any(), gomock.Any()).Times(1).Return(quote, nil)
+
+				arg := db.ApproveOrganizationTransferTxParams{
+					OrganizationTransferID: pending.ID,
+					Approver:               admin.Username,
+					Fee:                    quote.Fee,
+					FeeAccountID:           quote.FeeAccountID,
+				}
+				store.EXPECT().ApproveOrganizationTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.ApproveOrganizationTransferTxResult{OrganizationTransfer: pending}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Initiator",
+			username: approver.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(membership(approver.Username, util.OrganizationRoleApprover), nil)
+				store.EXPECT().GetOrganizationTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().ApproveOrganizationTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "InitiatorRole",
+			username: initiator.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(membership(initiator.Username, util.OrganizationRoleInitiator), nil)
+				store.EXPECT().GetOrganizationTransfer(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ApproveOrganizationTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "AlreadyApproved",
+			username: admin.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetOrganizationMember(gomock.Any(), gomock.Any()).Times(1).Return(membership(admin.Username, util.OrganizationRoleAdmin), nil)
+				store.EXPECT().GetOrganizationTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().QuoteFee(gomock.Any(), gomock.Any()).Times(1).Return(quote, nil)
+				store.EXPECT().ApproveOrganizationTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ApproveOrganizationTransferTxResult{}, db.ErrAlreadyApproved)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/organizations/%d/transfers/%d/approve", organization.ID, pending.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestBusinessAccountTransferAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	account := createRandomAccount(user.Username)
+	account.Currency = util.USD
+	account.OrganizationID = sql.NullInt64{Int64: util.RandomInt(1, 1000), Valid: true}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{
+		"from_account_id": account.ID,
+		"to_account_id":   account.ID + 1,
+		"amount":          10,
+		"currency":        util.USD,
+	})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	// even the owner of a business account can't pay from it outside the organization's approvals
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
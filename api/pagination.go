@@ -0,0 +1,127 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pages are 20 items unless the client asks for a size, up to 100
+const defaultPageSize = 20
+
+const (
+	sortAscending  = "asc"
+	sortDescending = "desc"
+)
+
+// lists answer with a plain array and hand out the cursor of their next page in this header
+const nextCursorHeader = "X-Next-Cursor"
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// lists used to be paged with page_id, sending it now would silently return the first page
+var errPageIDNotSupported = errors.New("page_id is no longer supported, page with cursor")
+
+// pageRequest is the keyset pagination list endpoints take. The cursor comes from the next cursor
+// of the previous page, so rows created while paging are neither skipped nor repeated.
+type pageRequest struct {
+	PageID   string `form:"page_id"`
+	Cursor   string `form:"cursor"`
+	PageSize int32  `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Sort     string `form:"sort" binding:"omitempty,oneof=asc desc"`
+}
+
+// pageCursor is what an opaque cursor holds: the last id of a page and the order it was listed in
+type pageCursor struct {
+	AfterID int64  `json:"a"`
+	Sort    string `json:"s"`
+}
+
+// page is where a list query starts and how many rows it reads, one more than the page size
+// so it knows whether there is a next page
+type page struct {
+	AfterID    int64
+	Descending bool
+	Size       int32
+	Sort       string
+}
+
+func (req pageRequest) page() (page, error) {
+	return req.pageSorted(sortAscending)
+}
+
+// pageSorted is page for lists that are listed in another order unless the client asks,
+// like newest first
+func (req pageRequest) pageSorted(defaultSort string) (page, error) {
+	if req.PageID != "" {
+		return page{}, errPageIDNotSupported
+	}
+
+	p := page{Size: req.PageSize, Sort: req.Sort}
+	if p.Size == 0 {
+		p.Size = defaultPageSize
+	}
+
+	if req.Cursor != "" {
+		cursor, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return page{}, err
+		}
+		// a cursor only makes sense in the order it was handed out in
+		if p.Sort != "" && p.Sort != cursor.Sort {
+			return page{}, errors.New("cursor doesn't match the sort order")
+		}
+		p.AfterID = cursor.AfterID
+		p.Sort = cursor.Sort
+	}
+
+	if p.Sort == "" {
+		p.Sort = defaultSort
+	}
+	p.Descending = p.Sort == sortDescending
+	return p, nil
+}
+
+// limit is the number of rows to ask the store for
+func (p page) limit() int32 {
+	return p.Size + 1
+}
+
+// next trims the lookahead row from the rows read and returns the cursor of the next page,
+// empty on the last page
+func (p page) next(n int, lastID func(i int) int64) (int, string) {
+	if n <= int(p.Size) {
+		return n, ""
+	}
+	return int(p.Size), encodeCursor(pageCursor{AfterID: lastID(int(p.Size) - 1), Sort: p.Sort})
+}
+
+// setNextCursor sends the cursor of the next page in a header, if there is one
+func setNextCursor(ctx *gin.Context, cursor string) {
+	if cursor != "" {
+		ctx.Header(nextCursorHeader, cursor)
+	}
+}
+
+func encodeCursor(cursor pageCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageCursor{}, errInvalidCursor
+	}
+
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return pageCursor{}, errInvalidCursor
+	}
+	if cursor.AfterID <= 0 || (cursor.Sort != sortAscending && cursor.Sort != sortDescending) {
+		return pageCursor{}, errInvalidCursor
+	}
+	return cursor, nil
+}
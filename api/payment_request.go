@@ -0,0 +1,307 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/token"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// ask another user, by username or verified email or phone number, to pay into one of the requester's accounts
+type createPaymentRequestRequest struct {
+	Payer       string `json:"payer" binding:"required"`
+	ToAccountID int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount      int64  `json:"amount" binding:"required,gt=0"`
+	Currency    string `json:"currency" binding:"required,currency"`
+	Memo        string `json:"memo" binding:"max=140"`
+	// seconds until the request expires, defaults to the configured payment request duration
+	ExpiresIn int64 `json:"expires_in" binding:"min=0"`
+}
+
+func (server *Server) createPaymentRequest(ctx *gin.Context) {
+	var req createPaymentRequestRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	toAccount, valid := server.validAccount(ctx, req.ToAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, toAccount, util.AccountPermissionView, 0) {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	payer, _, valid := server.aliasOwner(ctx, req.Payer)
+	if !valid {
+		return
+	}
+
+	if payer == authPayload.Username {
+		err := errors.New("cannot request money from yourself")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	expiresIn := server.config.PaymentRequestDuration
+	if req.ExpiresIn > 0 {
+		expiresIn = time.Duration(req.ExpiresIn) * time.Second
+	}
+	if expiresIn > server.config.PaymentRequestDuration {
+		err := errors.New("payment request cannot last longer than " + server.config.PaymentRequestDuration.String())
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	paymentRequest, err := server.store.CreatePaymentRequest(ctx, db.CreatePaymentRequestParams{
+		Requester:   authPayload.Username,
+		Payer:       payer,
+		ToAccountID: toAccount.ID,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Memo:        req.Memo,
+		ExpiresAt:   time.Now().Add(expiresIn),
+	})
+	if err != nil {
+		// the payer was given by a username that doesn't exist
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, paymentRequest)
+}
+
+type paymentRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getPaymentRequest(ctx *gin.Context) {
+	var uri paymentRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	paymentRequest, valid := server.paymentRequestParty(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, paymentRequest)
+}
+
+// list the requests the user was asked to pay (incoming) or sent to others (outgoing) newest first,
+// optionally by status
+type listPaymentRequestsRequest struct {
+	pageRequest
+	Direction string `form:"direction" binding:"required,oneof=incoming outgoing"`
+	Status    string `form:"status" binding:"omitempty,oneof=pending paid declined cancelled expired"`
+}
+
+func (server *Server) listPaymentRequests(ctx *gin.Context) {
+	var req listPaymentRequestsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	page, err := req.pageSorted(sortDescending)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	var paymentRequests []db.PaymentRequest
+	if req.Direction == "incoming" {
+		paymentRequests, err = server.store.ListIncomingPaymentRequests(ctx, db.ListIncomingPaymentRequestsParams{
+			Payer:      authPayload.Username,
+			Status:     req.Status,
+			AfterID:    page.AfterID,
+			Descending: page.Descending,
+			PageLimit:  page.limit(),
+		})
+	} else {
+		paymentRequests, err = server.store.ListOutgoingPaymentRequests(ctx, db.ListOutgoingPaymentRequestsParams{
+			Requester:  authPayload.Username,
+			Status:     req.Status,
+			AfterID:    page.AfterID,
+			Descending: page.Descending,
+			PageLimit:  page.limit(),
+		})
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	n, nextCursor := page.next(len(paymentRequests), func(i int) int64 { return paymentRequests[i].ID })
+	setNextCursor(ctx, nextCursor)
+	ctx.JSON(http.StatusOK, paymentRequests[:n])
+}
+
+// pay a request from one of the payer's accounts in the requested currency
+type acceptPaymentRequestRequest struct {
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+}
+
+func (server *Server) acceptPaymentRequest(ctx *gin.Context) {
+	var uri paymentRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req acceptPaymentRequestRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	paymentRequest, valid := server.pendingPaymentRequest(ctx, uri.ID, true)
+	if !valid {
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, paymentRequest.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, fromAccount, util.AccountPermissionPay, paymentRequest.Amount) {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	quote, valid := server.quoteFee(ctx, fromAccount, util.TransferTypeTransfer, paymentRequest.Amount)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.PayPaymentRequestTx(ctx, db.PayPaymentRequestTxParams{
+		PaymentRequestID: paymentRequest.ID,
+		FromAccountID:    fromAccount.ID,
+		Fee:              quote.Fee,
+		FeeAccountID:     quote.FeeAccountID,
+		Username:         authPayload.Username,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrPaymentRequestNotPending) || errors.Is(err, db.ErrPaymentRequestExpired) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		transferError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// the payer declines a request
+func (server *Server) declinePaymentRequest(ctx *gin.Context) {
+	server.closePaymentRequest(ctx, true, util.PaymentRequestStatusDeclined)
+}
+
+// the requester takes a request back
+func (server *Server) cancelPaymentRequest(ctx *gin.Context) {
+	server.closePaymentRequest(ctx, false, util.PaymentRequestStatusCancelled)
+}
+
+func (server *Server) closePaymentRequest(ctx *gin.Context, asPayer bool, status string) {
+	var uri paymentRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	paymentRequest, valid := server.pendingPaymentRequest(ctx, uri.ID, asPayer)
+	if !valid {
+		return
+	}
+
+	paymentRequest, err := server.store.ClosePaymentRequest(ctx, db.ClosePaymentRequestParams{
+		ID:     paymentRequest.ID,
+		Status: status,
+	})
+	if err != nil {
+		// paid or closed since it was read
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusForbidden, errorResponse(db.ErrPaymentRequestNotPending))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, paymentRequest)
+}
+
+// pendingPaymentRequest makes sure a request is still open and the authenticated user is its payer,
+// or its requester when asPayer is false
+func (server *Server) pendingPaymentRequest(ctx *gin.Context, id int64, asPayer bool) (db.PaymentRequest, bool) {
+	paymentRequest, valid := server.paymentRequestParty(ctx, id)
+	if !valid {
+		return paymentRequest, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if asPayer && paymentRequest.Payer != authPayload.Username {
+		err := errors.New("only the payer can accept or decline a payment request")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return paymentRequest, false
+	}
+	if !asPayer && paymentRequest.Requester != authPayload.Username {
+		err := errors.New("only the requester can cancel a payment request")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return paymentRequest, false
+	}
+
+	if paymentRequest.Status != util.PaymentRequestStatusPending {
+		ctx.JSON(http.StatusForbidden, errorResponse(db.ErrPaymentRequestNotPending))
+		return paymentRequest, false
+	}
+	if !paymentRequest.ExpiresAt.After(time.Now()) {
+		ctx.JSON(http.StatusForbidden, errorResponse(db.ErrPaymentRequestExpired))
+		return paymentRequest, false
+	}
+
+	return paymentRequest, true
+}
+
+// paymentRequestParty makes sure the authenticated user sent or was sent a payment request
+func (server *Server) paymentRequestParty(ctx *gin.Context, id int64) (db.PaymentRequest, bool) {
+	paymentRequest, err := server.store.GetPaymentRequest(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return paymentRequest, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return paymentRequest, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if paymentRequest.Requester != authPayload.Username && paymentRequest.Payer != authPayload.Username {
+		err := errors.New("payment request doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return paymentRequest, false
+	}
+
+	return paymentRequest, true
+}
@@ -0,0 +1,258 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+var errDuplicatePot = errors.New("the account already has a pot with that name or another round-up pot")
+
+// set money aside in a named pot under an account, optionally towards a target amount and date
+type createPotRequest struct {
+	Name         string    `json:"name" binding:"required,max=50"`
+	TargetAmount int64     `json:"target_amount" binding:"min=0"`
+	TargetDate   time.Time `json:"target_date"`
+	// round outgoing transfers from the account up into this pot
+	RoundUp bool `json:"round_up"`
+}
+
+func (server *Server) createPot(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req createPotRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.getAccountByID(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, util.AccountPermissionManage, 0) {
+		return
+	}
+
+	pot, err := server.store.CreatePot(ctx, db.CreatePotParams{
+		AccountID:    account.ID,
+		Name:         req.Name,
+		TargetAmount: req.TargetAmount,
+		TargetDate:   sql.NullTime{Time: req.TargetDate, Valid: !req.TargetDate.IsZero()},
+		RoundUp:      req.RoundUp,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			ctx.JSON(http.StatusForbidden, errorResponse(errDuplicatePot))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pot)
+}
+
+func (server *Server) listPots(ctx *gin.Context) {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.getAccountByID(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, util.AccountPermissionView, 0) {
+		return
+	}
+
+	pots, err := server.store.ListPots(ctx, account.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pots)
+}
+
+type potURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// fields left out keep their current value, a zero target date clears it
+type updatePotRequest struct {
+	Name         *string    `json:"name" binding:"omitempty,min=1,max=50"`
+	TargetAmount *int64     `json:"target_amount" binding:"omitempty,min=0"`
+	TargetDate   *time.Time `json:"target_date"`
+	RoundUp      *bool      `json:"round_up"`
+}
+
+func (server *Server) updatePot(ctx *gin.Context) {
+	var uri potURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updatePotRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	pot, valid := server.accountPot(ctx, uri.ID, util.AccountPermissionManage)
+	if !valid {
+		return
+	}
+
+	arg := db.UpdatePotParams{
+		ID:           pot.ID,
+		Name:         pot.Name,
+		TargetAmount: pot.TargetAmount,
+		TargetDate:   pot.TargetDate,
+		RoundUp:      pot.RoundUp,
+	}
+	if req.Name != nil {
+		arg.Name = *req.Name
+	}
+	if req.TargetAmount != nil {
+		arg.TargetAmount = *req.TargetAmount
+	}
+	if req.TargetDate != nil {
+		arg.TargetDate = sql.NullTime{Time: *req.TargetDate, Valid: !req.TargetDate.IsZero()}
+	}
+	if req.RoundUp != nil {
+		arg.RoundUp = *req.RoundUp
+	}
+
+	pot, err := server.store.UpdatePot(ctx, arg)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			ctx.JSON(http.StatusForbidden, errorResponse(errDuplicatePot))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pot)
+}
+
+// close a pot, it has to be emptied back into the account first
+func (server *Server) deletePot(ctx *gin.Context) {
+	var uri potURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	pot, valid := server.accountPot(ctx, uri.ID, util.AccountPermissionManage)
+	if !valid {
+		return
+	}
+
+	_, err := server.store.ClosePot(ctx, pot.ID)
+	if err != nil {
+		// money was moved into the pot since it was read
+		if err == sql.ErrNoRows {
+			err := errors.New("pot has to be empty to be closed")
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "pot closed"})
+}
+
+type movePotMoneyRequest struct {
+	Amount int64 `json:"amount" binding:"required,gt=0"`
+}
+
+// move money from the account's balance into a pot
+func (server *Server) depositToPot(ctx *gin.Context) {
+	server.movePotMoney(ctx, 1)
+}
+
+// move money from a pot back to the account's balance
+func (server *Server) withdrawFromPot(ctx *gin.Context) {
+	server.movePotMoney(ctx, -1)
+}
+
+func (server *Server) movePotMoney(ctx *gin.Context, sign int64) {
+	var uri potURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req movePotMoneyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	pot, valid := server.accountPot(ctx, uri.ID, util.AccountPermissionManage)
+	if !valid {
+		return
+	}
+
+	result, err := server.store.MovePotMoneyTx(ctx, db.MovePotMoneyTxParams{
+		PotID:  pot.ID,
+		Amount: sign * req.Amount,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientAvailableBalance) || errors.Is(err, db.ErrInsufficientPotBalance) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrPotClosed) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// accountPot loads an open pot and makes sure the authenticated user has permission on its account
+func (server *Server) accountPot(ctx *gin.Context, id int64, permission string) (db.Pot, bool) {
+	pot, err := server.store.GetPot(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return pot, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return pot, false
+	}
+
+	account, err := server.store.GetAccount(ctx, pot.AccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return pot, false
+	}
+
+	if !server.authorizeAccount(ctx, account, permission, 0) {
+		return pot, false
+	}
+
+	return pot, true
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	mockdb "github.com/malcolmmaima/maimabank/db/mock"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePotAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+	account := createRandomAccount(user.Username)
+	targetDate := time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			body: gin.H{
+				"name":          "holiday",
+				"target_amount": 100000,
+				"target_date":   targetDate,
+				"round_up":      true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				arg := db.CreatePotParams{
+					AccountID:    account.ID,
+					Name:         "holiday",
+					TargetAmount: 100000,
+					TargetDate:   sql.NullTime{Time: targetDate, Valid: true},
+					RoundUp:      true,
+				}
+				store.EXPECT().CreatePot(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.Pot{ID: 1, AccountID: account.ID, Name: arg.Name, RoundUp: true}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NoTarget",
+			username: user.Username,
+			body:     gin.H{"name": "rainy day"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CreatePot(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePotParams) (db.Pot, error) {
+						require.Zero(t, arg.TargetAmount)
+						require.False(t, arg.TargetDate.Valid)
+						require.False(t, arg.RoundUp)
+						return db.Pot{ID: 1, AccountID: arg.AccountID, Name: arg.Name}, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "SecondRoundUpPot",
+			username: user.Username,
+			body:     gin.H{"name": "spare change", "round_up": true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CreatePot(gomock.Any(), gomock.Any()).Times(1).Return(db.Pot{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotOwner",
+			username: other.Username,
+			body:     gin.H{"name": "holiday"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().CreatePot(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/pots", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestMovePotMoneyAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := createRandomAccount(user.Username)
+	pot := db.Pot{
+		ID:        util.RandomInt(1, 1000),
+		AccountID: account.ID,
+		Name:      "holiday",
+		Balance:   100,
+	}
+
+	testCases := []struct {
+		name          string
+		action        string
+		amount        int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Deposit",
+			action: "deposit",
+			amount: 50,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPot(gomock.Any(), gomock.Eq(pot.ID)).Times(1).Return(pot, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				arg := db.MovePotMoneyTxParams{PotID: pot.ID, Amount: 50}
+				store.EXPECT().MovePotMoneyTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.MovePotMoneyTxResult{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Withdraw",
+			action: "withdraw",
+			amount: 50,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPot(gomock.Any(), gomock.Eq(pot.ID)).Times(1).Return(pot, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				arg := db.MovePotMoneyTxParams{PotID: pot.ID, Amount: -50}
+				store.EXPECT().MovePotMoneyTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.MovePotMoneyTxResult{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "WithdrawTooMuch",
+			action: "withdraw",
+			amount: 500,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPot(gomock.Any(), gomock.Eq(pot.ID)).Times(1).Return(pot, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().MovePotMoneyTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.MovePotMoneyTxResult{}, db.ErrInsufficientPotBalance)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "PotClosed",
+			action: "deposit",
+			amount: 50,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPot(gomock.Any(), gomock.Eq(pot.ID)).Times(1).Return(pot, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().MovePotMoneyTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.MovePotMoneyTxResult{}, db.ErrPotClosed)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "PotNotFound",
+			action: "deposit",
+			amount: 50,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPot(gomock.Any(), gomock.Eq(pot.ID)).Times(1).Return(db.Pot{}, sql.ErrNoRows)
+				store.EXPECT().MovePotMoneyTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "NegativeAmount",
+			action: "deposit",
+			amount: -50,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPot(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().MovePotMoneyTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"amount": tc.amount})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/pots/%d/%s", pot.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
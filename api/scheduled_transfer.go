@@ -0,0 +1,264 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/token"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// schedule a one-off or recurring transfer, day_of_month is only used by monthly schedules
+// and defaults to the day of start_at
+type createScheduledTransferRequest struct {
+	FromAccountID int64     `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64     `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64     `json:"amount" binding:"required,gt=0"`
+	Currency      string    `json:"currency" binding:"required,currency"`
+	Frequency     string    `json:"frequency" binding:"required,frequency"`
+	DayOfMonth    int32     `json:"day_of_month" binding:"min=0,max=31"`
+	StartAt       time.Time `json:"start_at" binding:"required"`
+	// code sent to the user to confirm a schedule paying a recipient that isn't trusted yet
+	ConfirmationCode string `json:"confirmation_code" binding:"omitempty,len=6,numeric"`
+}
+
+func (server *Server) createScheduledTransfer(ctx *gin.Context) {
+	var req createScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.StartAt.After(time.Now()) {
+		err := errors.New("start_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, fromAccount, util.AccountPermissionPay, req.Amount) {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if fromAccount.ID == req.ToAccountID {
+		err := errors.New("from account cannot be equal to to account")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	toAccount, valid := server.validAccount(ctx, req.ToAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	// every run pays the recipient, so the schedule is confirmed once when it is created
+	if !server.checkRecipient(ctx, authPayload.Username, transferRequest{
+		FromAccountID:    req.FromAccountID,
+		ToAccountID:      req.ToAccountID,
+		Amount:           req.Amount,
+		Currency:         req.Currency,
+		ConfirmationCode: req.ConfirmationCode,
+	}, toAccount) {
+		return
+	}
+
+	dayOfMonth := scheduleDayOfMonth(req.Frequency, req.DayOfMonth, req.StartAt)
+	arg := db.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Frequency:     req.Frequency,
+		DayOfMonth:    dayOfMonth,
+		NextRunAt:     util.FirstRun(req.Frequency, int(dayOfMonth), req.StartAt),
+	}
+
+	scheduledTransfer, err := server.store.CreateScheduledTransfer(ctx, arg)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfer)
+}
+
+type scheduledTransferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, valid := server.ownScheduledTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfer)
+}
+
+type listScheduledTransfersRequest struct {
+	pageRequest
+}
+
+func (server *Server) listScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	page, err := req.page()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	scheduledTransfers, err := server.store.ListScheduledTransfers(ctx, db.ListScheduledTransfersParams{
+		Owner:      authPayload.Username,
+		AfterID:    page.AfterID,
+		Descending: page.Descending,
+		PageLimit:  page.limit(),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	n, nextCursor := page.next(len(scheduledTransfers), func(i int) int64 { return scheduledTransfers[i].ID })
+	setNextCursor(ctx, nextCursor)
+	ctx.JSON(http.StatusOK, scheduledTransfers[:n])
+}
+
+// change, pause or resume a scheduled transfer, next_run_at moves it to a new first run
+type updateScheduledTransferRequest struct {
+	Amount     int64     `json:"amount" binding:"required,gt=0"`
+	Frequency  string    `json:"frequency" binding:"required,frequency"`
+	DayOfMonth int32     `json:"day_of_month" binding:"min=0,max=31"`
+	NextRunAt  time.Time `json:"next_run_at" binding:"required"`
+	Status     string    `json:"status" binding:"required,oneof=active paused"`
+}
+
+func (server *Server) updateScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateScheduledTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.NextRunAt.After(time.Now()) {
+		err := errors.New("next_run_at must be in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, valid := server.ownScheduledTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	if scheduledTransfer.Status == util.ScheduleStatusCompleted {
+		err := errors.New("scheduled transfer has already completed")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	dayOfMonth := scheduleDayOfMonth(req.Frequency, req.DayOfMonth, req.NextRunAt)
+	scheduledTransfer, err := server.store.UpdateScheduledTransfer(ctx, db.UpdateScheduledTransferParams{
+		ID:         scheduledTransfer.ID,
+		Amount:     req.Amount,
+		Frequency:  req.Frequency,
+		DayOfMonth: dayOfMonth,
+		NextRunAt:  util.FirstRun(req.Frequency, int(dayOfMonth), req.NextRunAt),
+		Status:     req.Status,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTransfer)
+}
+
+func (server *Server) deleteScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, valid := server.ownScheduledTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	err := server.store.DeleteScheduledTransfer(ctx, scheduledTransfer.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "scheduled transfer deleted"})
+}
+
+// ownScheduledTransfer makes sure a scheduled transfer exists and belongs to the authenticated user
+func (server *Server) ownScheduledTransfer(ctx *gin.Context, id int64) (db.ScheduledTransfer, bool) {
+	scheduledTransfer, err := server.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return scheduledTransfer, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return scheduledTransfer, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if scheduledTransfer.Owner != authPayload.Username {
+		err := errors.New("scheduled transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return scheduledTransfer, false
+	}
+
+	return scheduledTransfer, true
+}
+
+// monthly schedules run on the day they start unless told otherwise
+func scheduleDayOfMonth(frequency string, dayOfMonth int32, start time.Time) int32 {
+	if frequency != util.FrequencyMonthly {
+		return 0
+	}
+	if dayOfMonth == 0 {
+		return int32(start.Day())
+	}
+	return dayOfMonth
+}
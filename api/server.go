@@ -1,12 +1,14 @@
 package api
 
 import (
+	"crypto/ed25519"
 	"fmt"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/notify"
 	"github.com/malcolmmaima/maimabank/token"
 	"github.com/malcolmmaima/maimabank/util"
 )
@@ -17,25 +19,44 @@ type Server struct {
 	config util.Config
 	store db.Store
 	tokenMaker token.Maker
+	sender notify.Sender
+	ledgerPublicKey ed25519.PublicKey
 	router *gin.Engine
 }
 
-func NewServer(config util.Config,store db.Store) (*Server, error) {
+func NewServer(config util.Config,store db.Store, sender notify.Sender) (*Server, error) {
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey) // to use JWT simply change from token.NewPasetoMaker to token.NewJWTMaker
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w ", err)
 	}
 
+	ledgerKey, err := util.ParseLedgerSigningKey(config.LedgerSigningKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse ledger signing key: %w", err)
+	}
+
 	server := &Server{
 		config: config,
 		store: store,
 		tokenMaker: tokenMaker,
+		sender: sender,
+	}
+	if ledgerKey != nil {
+		server.ledgerPublicKey = ledgerKey.Public().(ed25519.PublicKey)
 	}
 
 	router := gin.Default()
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("transfer_type", validTransferType)
+		v.RegisterValidation("tier", validTier)
+		v.RegisterValidation("role", validRole)
+		v.RegisterValidation("frequency", validFrequency)
+		v.RegisterValidation("category", validCategory)
+		v.RegisterValidation("account_role", validAccountRole)
+		v.RegisterValidation("organization_role", validOrganizationRole)
+		v.RegisterValidation("journal_kind", validJournalKind)
 	}
 
 	router.POST("/users", server.createUser)
@@ -48,12 +69,100 @@ func NewServer(config util.Config,store db.Store) (*Server, error) {
 	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
 	authRoutes.POST("/accounts", server.createAccount)
 	authRoutes.GET("/accounts/:id", server.getAccount)
+	authRoutes.GET("/accounts/:id/balance", server.getAccountBalance)
+	authRoutes.GET("/accounts/:id/statement", server.getAccountStatement)
+	authRoutes.GET("/accounts/:id/analytics", server.getAccountAnalytics)
+	authRoutes.GET("/accounts/:id/statement.pdf", server.exportAccountStatement)
+	authRoutes.GET("/accounts/:id/statement.csv", server.exportAccountStatement)
+	authRoutes.GET("/accounts/:id/statement.ofx", server.exportAccountStatement)
+	authRoutes.GET("/accounts/:id/statement.camt053", server.exportAccountStatement)
+	authRoutes.GET("/accounts/:id/statement.mt940", server.exportAccountStatement)
 	authRoutes.GET("/accounts", server.listAccounts)
+	authRoutes.GET("/accounts/summary", server.getAccountSummary)
 	authRoutes.GET("/accounts/statement", server.listTransfers)
+	authRoutes.GET("/accounts/:id/entries", server.listEntries)
+	authRoutes.GET("/entries/:id", server.getEntry)
 	authRoutes.POST("/transfers", server.createTransfer)
 	authRoutes.POST("/transfers/multicurrency", server.createMultiCurrencyTransfer)
+	authRoutes.POST("/transfers/preview", server.previewTransfer)
 	authRoutes.POST("/exchange_rate", server.createExchangeRate)
 	authRoutes.PUT("/exchange_rate", server.updateExchangeRate)
+	authRoutes.POST("/fee_schedules", server.createFeeSchedule)
+	authRoutes.GET("/fee_schedules", server.listFeeSchedules)
+	authRoutes.DELETE("/fee_schedules/:id", server.deleteFeeSchedule)
+	authRoutes.POST("/fee_waivers", server.createFeeWaiver)
+	authRoutes.GET("/fee_waivers", server.listFeeWaivers)
+	authRoutes.DELETE("/fee_waivers/:id", server.deleteFeeWaiver)
+	authRoutes.GET("/limits", server.getTransferLimits)
+	authRoutes.GET("/limits/tiers", server.listTransferLimits)
+	authRoutes.PUT("/limits/tiers", server.upsertTransferLimit)
+	authRoutes.PUT("/limits/overrides", server.upsertTransferLimitOverride)
+	authRoutes.DELETE("/limits/overrides", server.deleteTransferLimitOverride)
+	authRoutes.PUT("/users/:username/tier", server.updateUserTier)
+	authRoutes.PUT("/users/:username/role", server.updateUserRole)
+	authRoutes.POST("/transfers/:id/reverse", server.reverseTransfer)
+	authRoutes.POST("/transfers/:id/refund", server.refundTransfer)
+	authRoutes.GET("/transfers/:id", server.getTransfer)
+	authRoutes.PATCH("/transfers/:id", server.updateTransferDetails)
+	authRoutes.POST("/transfers/batch", server.createTransferBatch)
+	authRoutes.GET("/transfers/batch/:id", server.getTransferBatch)
+	authRoutes.GET("/transfers/batch/:id/report", server.getTransferBatchReport)
+	authRoutes.POST("/holds", server.createHold)
+	authRoutes.GET("/holds/:id", server.getHold)
+	authRoutes.POST("/holds/:id/capture", server.captureHold)
+	authRoutes.POST("/holds/:id/void", server.voidHold)
+	authRoutes.POST("/scheduled_transfers", server.createScheduledTransfer)
+	authRoutes.GET("/scheduled_transfers", server.listScheduledTransfers)
+	authRoutes.GET("/scheduled_transfers/:id", server.getScheduledTransfer)
+	authRoutes.PUT("/scheduled_transfers/:id", server.updateScheduledTransfer)
+	authRoutes.DELETE("/scheduled_transfers/:id", server.deleteScheduledTransfer)
+	authRoutes.POST("/aliases", server.createAlias)
+	authRoutes.GET("/aliases", server.listAliases)
+	authRoutes.GET("/aliases/lookup", server.lookupAlias)
+	authRoutes.POST("/aliases/:id/verify", server.verifyAlias)
+	authRoutes.DELETE("/aliases/:id", server.deleteAlias)
+	authRoutes.PUT("/accounts/:id/default", server.setDefaultAccount)
+	authRoutes.GET("/accounts/:id/members", server.listAccountMembers)
+	authRoutes.POST("/accounts/:id/members", server.inviteAccountMember)
+	authRoutes.POST("/accounts/:id/members/accept", server.acceptAccountInvitation)
+	authRoutes.DELETE("/accounts/:id/members/:username", server.removeAccountMember)
+	authRoutes.GET("/account_invitations", server.listAccountInvitations)
+	authRoutes.GET("/accounts/:id/pots", server.listPots)
+	authRoutes.POST("/accounts/:id/pots", server.createPot)
+	authRoutes.PATCH("/pots/:id", server.updatePot)
+	authRoutes.DELETE("/pots/:id", server.deletePot)
+	authRoutes.POST("/pots/:id/deposit", server.depositToPot)
+	authRoutes.POST("/pots/:id/withdraw", server.withdrawFromPot)
+	authRoutes.GET("/ledger/accounts", server.listLedgerAccounts)
+	authRoutes.GET("/ledger/verify", server.verifyLedger)
+	authRoutes.GET("/ledger/seals", server.listLedgerSeals)
+	authRoutes.POST("/journals", server.postJournal)
+	authRoutes.GET("/journals/:id", server.getJournal)
+
+	authRoutes.POST("/organizations", server.createOrganization)
+	authRoutes.GET("/organizations", server.listOrganizations)
+	authRoutes.GET("/organizations/:id", server.getOrganization)
+	authRoutes.GET("/organizations/:id/members", server.listOrganizationMembers)
+	authRoutes.POST("/organizations/:id/members", server.addOrganizationMember)
+	authRoutes.DELETE("/organizations/:id/members/:username", server.removeOrganizationMember)
+	authRoutes.GET("/organizations/:id/accounts", server.listOrganizationAccounts)
+	authRoutes.POST("/organizations/:id/accounts", server.createOrganizationAccount)
+	authRoutes.GET("/organizations/:id/transfers", server.listOrganizationTransfers)
+	authRoutes.POST("/organizations/:id/transfers", server.createOrganizationTransfer)
+	authRoutes.GET("/organizations/:id/transfers/:transfer_id", server.getOrganizationTransfer)
+	authRoutes.POST("/organizations/:id/transfers/:transfer_id/approve", server.approveOrganizationTransfer)
+	authRoutes.POST("/organizations/:id/transfers/:transfer_id/reject", server.rejectOrganizationTransfer)
+	authRoutes.POST("/beneficiaries", server.createBeneficiary)
+	authRoutes.GET("/beneficiaries", server.listBeneficiaries)
+	authRoutes.GET("/beneficiaries/:id", server.getBeneficiary)
+	authRoutes.PUT("/beneficiaries/:id", server.updateBeneficiary)
+	authRoutes.DELETE("/beneficiaries/:id", server.deleteBeneficiary)
+	authRoutes.POST("/payment_requests", server.createPaymentRequest)
+	authRoutes.GET("/payment_requests", server.listPaymentRequests)
+	authRoutes.GET("/payment_requests/:id", server.getPaymentRequest)
+	authRoutes.POST("/payment_requests/:id/accept", server.acceptPaymentRequest)
+	authRoutes.POST("/payment_requests/:id/decline", server.declinePaymentRequest)
+	authRoutes.POST("/payment_requests/:id/cancel", server.cancelPaymentRequest)
 	server.router = router
 	return server, nil
 }
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/statement"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// download the statement of an account over a period as a PDF, CSV, OFX, camt.053 or MT940 file, the format
+// comes from the extension of the route. The file is streamed as the entries are read so
+// any period can be exported.
+func (server *Server) exportAccountStatement(ctx *gin.Context) {
+	format := strings.TrimPrefix(path.Ext(ctx.FullPath()), ".")
+
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req getAccountStatementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.StartDate.After(req.EndDate) {
+		err := errors.New("start_date cannot be greater than end_date")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, util.AccountPermissionView, 0) {
+		return
+	}
+
+	holder, err := server.store.GetUser(ctx, account.Owner)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	writer, err := statement.NewWriter(format, ctx.Writer)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	arg := db.AccountStatementParams{
+		AccountID: account.ID,
+		Since:     req.StartDate,
+		Until:     req.EndDate.AddDate(0, 0, 1),
+	}
+
+	// once the header is written the response has started and errors can only cut it short
+	started := false
+	err = server.store.StreamAccountStatement(ctx, arg, func(header db.AccountStatement) error {
+		filename := fmt.Sprintf("statement-%d-%s-%s.%s", account.ID,
+			req.StartDate.Format("20060102"), req.EndDate.Format("20060102"), statement.FileExtension(format))
+		ctx.Header("Content-Type", statement.ContentType(format))
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		ctx.Status(http.StatusOK)
+		started = true
+
+		return writer.Begin(statement.Header{
+			Statement:   header,
+			HolderName:  holder.FullName,
+			HolderEmail: holder.Email,
+			GeneratedAt: time.Now(),
+		})
+	}, writer.Line)
+	if err == nil {
+		err = writer.End()
+	}
+
+	if err != nil {
+		if !started {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		ctx.Error(err)
+		ctx.Abort()
+	}
+}
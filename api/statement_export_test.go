@@ -0,0 +1,226 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/malcolmmaima/maimabank/db/mock"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportAccountStatementAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	account := createRandomAccount(user1.Username)
+
+	arg := db.AccountStatementParams{
+		AccountID: account.ID,
+		Since:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		Until:     time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+	}
+	header := db.AccountStatement{
+		AccountID:      account.ID,
+		Currency:       account.Currency,
+		Since:          arg.Since,
+		Until:          arg.Until,
+		OpeningBalance: 500,
+		TotalCredits:   100,
+		ClosingBalance: 600,
+	}
+	line := db.StatementLine{
+		ListStatementEntriesRow: db.ListStatementEntriesRow{
+			ID:        1,
+			Amount:    100,
+			CreatedAt: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+			LineType:  util.TransferKindTransfer,
+		},
+		Balance: 600,
+	}
+
+	streamStatement := func(_ context.Context, _ db.AccountStatementParams, begin func(db.AccountStatement) error, write func(db.StatementLine) error) error {
+		if err := begin(header); err != nil {
+			return err
+		}
+		return write(line)
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		format        string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "CSV",
+			username: user1.Username,
+			format:   "csv",
+			query:    "start_date=2024-03-01&end_date=2024-03-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().StreamAccountStatement(gomock.Any(), gomock.Eq(arg), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(streamStatement)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Header().Get("Content-Disposition"),
+					fmt.Sprintf(`filename="statement-%d-20240301-20240331.csv"`, account.ID))
+
+				records, err := csv.NewReader(recorder.Body).ReadAll()
+				require.NoError(t, err)
+				require.Len(t, records, 2)
+				require.Equal(t, "6.00", records[1][7])
+			},
+		},
+		{
+			name:     "PDF",
+			username: user1.Username,
+			format:   "pdf",
+			query:    "start_date=2024-03-01&end_date=2024-03-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().StreamAccountStatement(gomock.Any(), gomock.Eq(arg), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(streamStatement)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/pdf", recorder.Header().Get("Content-Type"))
+				require.True(t, bytes.HasPrefix(recorder.Body.Bytes(), []byte("%PDF-")))
+			},
+		},
+		{
+			name:     "OFX",
+			username: user1.Username,
+			format:   "ofx",
+			query:    "start_date=2024-03-01&end_date=2024-03-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().StreamAccountStatement(gomock.Any(), gomock.Eq(arg), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(streamStatement)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/x-ofx", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Body.String(), "<BALAMT>6.00")
+			},
+		},
+		{
+			name:     "Camt053",
+			username: user1.Username,
+			format:   "camt053",
+			query:    "start_date=2024-03-01&end_date=2024-03-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().StreamAccountStatement(gomock.Any(), gomock.Eq(arg), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(streamStatement)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/xml", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Header().Get("Content-Disposition"),
+					fmt.Sprintf(`filename="statement-%d-20240301-20240331.xml"`, account.ID))
+				require.Contains(t, recorder.Body.String(), "<Cd>CLBD</Cd>")
+			},
+		},
+		{
+			name:     "MT940",
+			username: user1.Username,
+			format:   "mt940",
+			query:    "start_date=2024-03-01&end_date=2024-03-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().StreamAccountStatement(gomock.Any(), gomock.Eq(arg), gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(streamStatement)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/plain; charset=us-ascii", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Header().Get("Content-Disposition"),
+					fmt.Sprintf(`filename="statement-%d-20240301-20240331.sta"`, account.ID))
+				require.Contains(t, recorder.Body.String(), ":62F:C240331")
+			},
+		},
+		{
+			name:     "StatementError",
+			username: user1.Username,
+			format:   "csv",
+			query:    "start_date=2024-03-01&end_date=2024-03-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().StreamAccountStatement(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:     "StartAfterEnd",
+			username: user1.Username,
+			format:   "pdf",
+			query:    "start_date=2024-04-01&end_date=2024-03-01",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().StreamAccountStatement(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NotOwner",
+			username: user2.Username,
+			format:   "pdf",
+			query:    "start_date=2024-03-01&end_date=2024-03-31",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().StreamAccountStatement(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/statement.%s?%s", account.ID, tc.format, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -10,13 +10,25 @@ import (
 	"github.com/gin-gonic/gin"
 	db "github.com/malcolmmaima/maimabank/db/sqlc"
 	"github.com/malcolmmaima/maimabank/token"
+	"github.com/malcolmmaima/maimabank/util"
 )
 
 type transferRequest struct {
 	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required_without_all=ToAlias BeneficiaryID,min=0"`
 	Amount        int64  `json:"amount" binding:"required,gt=0"`
 	Currency      string `json:"currency" binding:"required,currency"`
+	// pay a username, a verified email address or phone number, or a saved beneficiary instead of to_account_id
+	ToAlias       string `json:"to_alias"`
+	BeneficiaryID int64  `json:"beneficiary_id" binding:"min=0"`
+	// code sent to the user to confirm a transfer to a recipient that isn't trusted yet
+	ConfirmationCode string `json:"confirmation_code" binding:"omitempty,len=6,numeric"`
+	// optional details, the memo and reference are shown to both parties
+	Memo                 string `json:"memo" binding:"max=140"`
+	Reference            string `json:"reference" binding:"max=35"`
+	SenderDescription    string `json:"sender_description" binding:"max=140"`
+	RecipientDescription string `json:"recipient_description" binding:"max=140"`
+	Category             string `json:"category" binding:"omitempty,category"`
 }
 
 func (server *Server) createTransfer(ctx *gin.Context) {
@@ -26,18 +38,21 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
+	if !server.resolveRecipient(ctx, &req) {
+		return
+	}
+
 	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
 	if !valid {
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if fromAccount.Owner != authPayload.Username {
-		err := errors.New("from account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if !server.authorizeAccount(ctx, fromAccount, util.AccountPermissionPay, req.Amount) {
 		return
 	}
 
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
 	// if fromAccount is equal to toAccount, return error
 	if fromAccount.ID == req.ToAccountID {
 		err := errors.New("from account cannot be equal to to account")
@@ -45,20 +60,37 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	_, valid = server.validAccount(ctx, req.ToAccountID, req.Currency)
+	toAccount, valid := server.validAccount(ctx, req.ToAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.checkRecipient(ctx, authPayload.Username, req, toAccount) {
+		return
+	}
+
+	quote, valid := server.quoteFee(ctx, fromAccount, util.TransferTypeTransfer, req.Amount)
 	if !valid {
 		return
 	}
 
 	arg := db.TransferTxParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
-		Amount:        req.Amount,
+		FromAccountID:        req.FromAccountID,
+		ToAccountID:          req.ToAccountID,
+		Amount:               req.Amount,
+		Fee:                  quote.Fee,
+		FeeAccountID:         quote.FeeAccountID,
+		Memo:                 req.Memo,
+		Reference:            req.Reference,
+		SenderDescription:    req.SenderDescription,
+		RecipientDescription: req.RecipientDescription,
+		SenderCategory:       req.Category,
+		LimitUsername:        authPayload.Username,
 	}
 
 	result, err := server.store.TransferTx(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		transferError(ctx, err)
 		return
 	}
 
@@ -73,18 +105,21 @@ func (server *Server) createMultiCurrencyTransfer(ctx *gin.Context) {
 		return
 	}
 
+	if !server.resolveRecipient(ctx, &req) {
+		return
+	}
+
 	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
 	if !valid {
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if fromAccount.Owner != authPayload.Username {
-		err := errors.New("from account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if !server.authorizeAccount(ctx, fromAccount, util.AccountPermissionPay, req.Amount) {
 		return
 	}
 
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
 	// if fromAccount is equal to toAccount, return error
 	if fromAccount.ID == req.ToAccountID {
 		err := errors.New("from account cannot be equal to to account")
@@ -113,52 +148,39 @@ func (server *Server) createMultiCurrencyTransfer(ctx *gin.Context) {
 			return
 		}
 
-		// get exchange rate
-		arg := db.GetExchangeRateParams{
-			BaseCurrency:   req.Currency,
-			TargetCurrency: toAccount.Currency,
-		}
-
-		exchangeRate, err := server.store.GetExchangeRate(ctx, arg)
-		if err != nil {
-			if err == sql.ErrNoRows {
-				err := errors.New("exchange rate not found")
-				ctx.JSON(http.StatusNotFound, errorResponse(err))
-				return
-			}
-
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		amount, _, valid := server.convertAmount(ctx, req.Amount, req.Currency, toAccount.Currency)
+		if !valid {
 			return
 		}
 
-		// convert amount to target currency... exchangeRate.ExchangeRate is string, convert to int64
-		exchangeRateAmount, err := strconv.ParseFloat(exchangeRate.ExchangeRate, 64)
-		amountToConvert := float64(req.Amount)
-
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		// beneficiary limits are in the currency the money arrives in
+		if !server.checkRecipient(ctx, authPayload.Username, req, toAccount) {
 			return
 		}
 
-		amount :=  amountToConvert * exchangeRateAmount
-
-		// if amount is less than 1, return error
-		if amount < 1 {
-			increaseAmountTo := strconv.FormatInt(int64(1 / exchangeRateAmount) + 1, 10)
-			err := fmt.Errorf("amount to transfer is less than 1 %s, please increase amount to %s %s or more", toAccount.Currency, req.Currency, increaseAmountTo)
-			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		quote, valid := server.quoteFee(ctx, fromAccount, util.TransferTypeFX, req.Amount)
+		if !valid {
 			return
 		}
 
 		arg2 := db.TransferTxParams{
-			FromAccountID: req.FromAccountID,
-			ToAccountID:   req.ToAccountID,
-			Amount:        int64(amount),
+			FromAccountID:        req.FromAccountID,
+			ToAccountID:          req.ToAccountID,
+			Amount:               amount,
+			FromAmount:           req.Amount,
+			Fee:                  quote.Fee,
+			FeeAccountID:         quote.FeeAccountID,
+			Memo:                 req.Memo,
+			Reference:            req.Reference,
+			SenderDescription:    req.SenderDescription,
+			RecipientDescription: req.RecipientDescription,
+			SenderCategory:       req.Category,
+			LimitUsername:        authPayload.Username,
 		}
 
 		result, err := server.store.TransferTx(ctx, arg2)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			transferError(ctx, err)
 			return
 		}
 
@@ -167,15 +189,32 @@ func (server *Server) createMultiCurrencyTransfer(ctx *gin.Context) {
 	}
 
 	// if fromAccount currency is equal to toAccount currency then transfer as usual
+	if !server.checkRecipient(ctx, authPayload.Username, req, toAccount) {
+		return
+	}
+
+	quote, valid := server.quoteFee(ctx, fromAccount, util.TransferTypeTransfer, req.Amount)
+	if !valid {
+		return
+	}
+
 	arg := db.TransferTxParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
-		Amount:        req.Amount,
+		FromAccountID:        req.FromAccountID,
+		ToAccountID:          req.ToAccountID,
+		Amount:               req.Amount,
+		Fee:                  quote.Fee,
+		FeeAccountID:         quote.FeeAccountID,
+		Memo:                 req.Memo,
+		Reference:            req.Reference,
+		SenderDescription:    req.SenderDescription,
+		RecipientDescription: req.RecipientDescription,
+		SenderCategory:       req.Category,
+		LimitUsername:        authPayload.Username,
 	}
 
 	result, err := server.store.TransferTx(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		transferError(ctx, err)
 		return
 	}
 
@@ -203,3 +242,135 @@ func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency s
 
 	return account, true
 }
+
+// convertAmount converts amount from baseCurrency to targetCurrency using the stored exchange rate
+func (server *Server) convertAmount(ctx *gin.Context, amount int64, baseCurrency string, targetCurrency string) (int64, string, bool) {
+	arg := db.GetExchangeRateParams{
+		BaseCurrency:   baseCurrency,
+		TargetCurrency: targetCurrency,
+	}
+
+	exchangeRate, err := server.store.GetExchangeRate(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err := errors.New("exchange rate not found")
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return 0, "", false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return 0, "", false
+	}
+
+	// exchangeRate.ExchangeRate is a decimal string, convert to float before multiplying
+	exchangeRateAmount, err := strconv.ParseFloat(exchangeRate.ExchangeRate, 64)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return 0, "", false
+	}
+
+	converted := float64(amount) * exchangeRateAmount
+
+	// if amount is less than 1, return error
+	if converted < 1 {
+		increaseAmountTo := strconv.FormatInt(int64(1/exchangeRateAmount)+1, 10)
+		err := fmt.Errorf("amount to transfer is less than 1 %s, please increase amount to %s %s or more", targetCurrency, baseCurrency, increaseAmountTo)
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return 0, "", false
+	}
+
+	return int64(converted), exchangeRate.ExchangeRate, true
+}
+
+// quoteFee evaluates the fee fromAccount pays for sending amount in its own currency
+func (server *Server) quoteFee(ctx *gin.Context, fromAccount db.Account, transferType string, amount int64) (db.FeeQuote, bool) {
+	quote, err := server.store.QuoteFee(ctx, db.QuoteFeeParams{
+		AccountType:  fromAccount.AccountType,
+		TransferType: transferType,
+		Currency:     fromAccount.Currency,
+		Amount:       amount,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return quote, false
+	}
+
+	return quote, true
+}
+
+type transferPreviewResponse struct {
+	Amount          int64  `json:"amount"`
+	Currency        string `json:"currency"`
+	Fee             int64  `json:"fee"`
+	FeeWaived       bool   `json:"fee_waived"`
+	TotalDebit      int64  `json:"total_debit"`
+	ConvertedAmount int64  `json:"converted_amount"`
+	TargetCurrency  string `json:"target_currency"`
+	ExchangeRate    string `json:"exchange_rate,omitempty"`
+}
+
+// previewTransfer shows what a transfer will cost without moving any money
+func (server *Server) previewTransfer(ctx *gin.Context) {
+	var req transferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !server.resolveRecipient(ctx, &req) {
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, fromAccount, util.AccountPermissionPay, req.Amount) {
+		return
+	}
+
+	if fromAccount.ID == req.ToAccountID {
+		err := errors.New("from account cannot be equal to to account")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, req.ToAccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := transferPreviewResponse{
+		Amount:          req.Amount,
+		Currency:        req.Currency,
+		ConvertedAmount: req.Amount,
+		TargetCurrency:  toAccount.Currency,
+	}
+
+	transferType := util.TransferTypeTransfer
+	if toAccount.Currency != fromAccount.Currency {
+		transferType = util.TransferTypeFX
+		response.ConvertedAmount, response.ExchangeRate, valid = server.convertAmount(ctx, req.Amount, req.Currency, toAccount.Currency)
+		if !valid {
+			return
+		}
+	}
+
+	quote, valid := server.quoteFee(ctx, fromAccount, transferType, req.Amount)
+	if !valid {
+		return
+	}
+
+	response.Fee = quote.Fee
+	response.FeeWaived = quote.Waived
+	response.TotalDebit = req.Amount + quote.Fee
+
+	ctx.JSON(http.StatusOK, response)
+}
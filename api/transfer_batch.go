@@ -0,0 +1,344 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/token"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// the most transfers a single batch can hold
+const maxBatchSize = 1000
+
+// submit a batch of transfers from one account, as JSON or as a CSV file with a
+// to_account_id,amount,currency,reference header and from_account_id and mode in the query string
+type createTransferBatchRequest struct {
+	FromAccountID int64               `json:"from_account_id" form:"from_account_id" binding:"required,min=1"`
+	Mode          string              `json:"mode" form:"mode" binding:"required,oneof=atomic best_effort"`
+	Items         []transferBatchLine `json:"items" form:"-"`
+}
+
+type transferBatchLine struct {
+	ToAccountID int64  `json:"to_account_id"`
+	Amount      int64  `json:"amount"`
+	Currency    string `json:"currency"`
+	Reference   string `json:"reference"`
+}
+
+type transferBatchLineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+func (server *Server) createTransferBatch(ctx *gin.Context) {
+	var req createTransferBatchRequest
+	if ctx.ContentType() == "text/csv" {
+		if err := ctx.ShouldBindQuery(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		items, err := parseTransferBatchCSV(ctx.Request.Body)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		req.Items = items
+	} else if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if len(req.Items) == 0 || len(req.Items) > maxBatchSize {
+		err := fmt.Errorf("a batch must have between 1 and %d transfers", maxBatchSize)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fromAccount, err := server.store.GetAccount(ctx, req.FromAccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// each transfer in the batch is a payment of its own as far as a payer's limit goes
+	var largest int64
+	for _, item := range req.Items {
+		if item.Amount > largest {
+			largest = item.Amount
+		}
+	}
+	if !server.authorizeAccount(ctx, fromAccount, util.AccountPermissionPay, largest) {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	allowance, err := server.store.GetTransferAllowance(ctx, db.GetTransferAllowanceParams{
+		Username: authPayload.Username,
+		Currency: fromAccount.Currency,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	lines, lineErrors, err := server.validateTransferBatch(ctx, authPayload.Username, fromAccount, allowance, req.Items)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if len(lineErrors) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":  fmt.Sprintf("%d of %d transfers are invalid", len(lineErrors), len(req.Items)),
+			"errors": lineErrors,
+		})
+		return
+	}
+
+	// each transfer is within the per transaction limit, the batch as a whole has to fit the daily and monthly limits
+	var total int64
+	for _, line := range lines {
+		total += line.Amount
+	}
+	allowance.PerTransactionLimit = 0
+	if err := allowance.Check(total); err != nil {
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.CreateTransferBatchTx(ctx, db.CreateTransferBatchTxParams{
+		Owner:         authPayload.Username,
+		FromAccountID: fromAccount.ID,
+		Mode:          req.Mode,
+		Items:         lines,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, result)
+}
+
+// validateTransferBatch checks every line of a batch and returns an error for each invalid one. A batch
+// can't be confirmed with a code, so it can only pay the user's own accounts and trusted beneficiaries.
+func (server *Server) validateTransferBatch(ctx *gin.Context, username string, fromAccount db.Account, allowance db.TransferAllowance, items []transferBatchLine) ([]db.CreateTransferBatchLine, []transferBatchLineError, error) {
+	lines := make([]db.CreateTransferBatchLine, 0, len(items))
+	lineErrors := []transferBatchLineError{}
+	toAccounts := make(map[int64]db.Account)
+	trusted := make(map[int64]bool)
+
+	for i, item := range items {
+		lineNumber := i + 1
+		invalid := func(err error) {
+			lineErrors = append(lineErrors, transferBatchLineError{Line: lineNumber, Error: err.Error()})
+		}
+
+		switch {
+		case item.Amount <= 0:
+			invalid(errors.New("amount must be positive"))
+			continue
+		case item.Currency != fromAccount.Currency:
+			invalid(fmt.Errorf("currency mismatch, expecting %s instead of %s", fromAccount.Currency, item.Currency))
+			continue
+		case item.ToAccountID == fromAccount.ID:
+			invalid(errors.New("from account cannot be equal to to account"))
+			continue
+		case allowance.PerTransactionLimit > 0 && item.Amount > allowance.PerTransactionLimit:
+			invalid(fmt.Errorf("amount exceeds the per transaction limit of %d %s", allowance.PerTransactionLimit, allowance.Currency))
+			continue
+		}
+
+		toAccount, ok := toAccounts[item.ToAccountID]
+		if !ok {
+			var err error
+			toAccount, err = server.store.GetAccount(ctx, item.ToAccountID)
+			if err == sql.ErrNoRows {
+				invalid(fmt.Errorf("account [%d] not found", item.ToAccountID))
+				continue
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			toAccounts[item.ToAccountID] = toAccount
+
+			trusted[toAccount.ID], err = server.trustedRecipient(ctx, username, toAccount)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if toAccount.Currency != item.Currency {
+			invalid(fmt.Errorf("account [%d] currency mismatch, expecting %s instead of %s", toAccount.ID, toAccount.Currency, item.Currency))
+			continue
+		}
+
+		if !trusted[toAccount.ID] {
+			invalid(fmt.Errorf("account [%d] isn't one of yours or a trusted beneficiary, pay it with a confirmed transfer instead", toAccount.ID))
+			continue
+		}
+
+		lines = append(lines, db.CreateTransferBatchLine{
+			LineNumber:  int32(lineNumber),
+			ToAccountID: item.ToAccountID,
+			Amount:      item.Amount,
+			Currency:    item.Currency,
+			Reference:   item.Reference,
+		})
+	}
+
+	return lines, lineErrors, nil
+}
+
+// parseTransferBatchCSV reads transfer lines from a CSV file, columns are matched by the header row
+func parseTransferBatchCSV(r io.Reader) ([]transferBatchLine, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read csv header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"to_account_id", "amount", "currency"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("csv header is missing the %s column", name)
+		}
+	}
+
+	items := []transferBatchLine{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == maxBatchSize {
+			return nil, fmt.Errorf("a batch must have between 1 and %d transfers", maxBatchSize)
+		}
+
+		line := len(items) + 1
+		toAccountID, err := strconv.ParseInt(record[columns["to_account_id"]], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid to_account_id: %w", line, err)
+		}
+		amount, err := strconv.ParseInt(record[columns["amount"]], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid amount: %w", line, err)
+		}
+
+		item := transferBatchLine{
+			ToAccountID: toAccountID,
+			Amount:      amount,
+			Currency:    record[columns["currency"]],
+		}
+		if i, ok := columns["reference"]; ok {
+			item.Reference = record[i]
+		}
+		items = append(items, item)
+	}
+}
+
+type transferBatchURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// poll a batch for its status and the result of each transfer
+func (server *Server) getTransferBatch(ctx *gin.Context) {
+	result, valid := server.ownTransferBatch(ctx)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// download the result of each transfer of a batch as CSV
+func (server *Server) getTransferBatchReport(ctx *gin.Context) {
+	result, valid := server.ownTransferBatch(ctx)
+	if !valid {
+		return
+	}
+
+	ctx.Header("Content-Type", "text/csv")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=transfer_batch_%d.csv", result.Batch.ID))
+	ctx.Status(http.StatusOK)
+
+	writer := csv.NewWriter(ctx.Writer)
+	writer.Write([]string{"line", "to_account_id", "amount", "currency", "reference", "status", "error", "transfer_id"})
+	for _, item := range result.Items {
+		transferID := ""
+		if item.TransferID.Valid {
+			transferID = strconv.FormatInt(item.TransferID.Int64, 10)
+		}
+		writer.Write([]string{
+			strconv.Itoa(int(item.LineNumber)),
+			strconv.FormatInt(item.ToAccountID, 10),
+			strconv.FormatInt(item.Amount, 10),
+			item.Currency,
+			item.Reference,
+			item.Status,
+			item.Error,
+			transferID,
+		})
+	}
+	writer.Flush()
+}
+
+// ownTransferBatch loads the batch in the uri with its items, making sure it belongs to the authenticated user
+func (server *Server) ownTransferBatch(ctx *gin.Context) (db.TransferBatchResult, bool) {
+	var result db.TransferBatchResult
+
+	var uri transferBatchURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return result, false
+	}
+
+	batch, err := server.store.GetTransferBatch(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return result, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return result, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if batch.Owner != authPayload.Username {
+		err := errors.New("transfer batch doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return result, false
+	}
+
+	items, err := server.store.ListTransferBatchItems(ctx, batch.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return result, false
+	}
+
+	result.Batch = batch
+	result.Items = items
+	return result, true
+}
@@ -0,0 +1,281 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/malcolmmaima/maimabank/db/mock"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTransferBatchAPI(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	fromAccount := createRandomAccount(user1.Username)
+	toAccount1 := createRandomAccount(user2.Username)
+	toAccount2 := createRandomAccount(user2.Username)
+	fromAccount.Currency = util.USD
+	toAccount1.Currency = util.USD
+	toAccount2.Currency = util.EUR
+
+	allowanceArg := db.GetTransferAllowanceParams{
+		Username: user1.Username,
+		Currency: util.USD,
+	}
+
+	beneficiaryArg := db.GetBeneficiaryByAccountParams{
+		Owner:     user1.Username,
+		AccountID: toAccount1.ID,
+	}
+	beneficiary := db.Beneficiary{
+		Owner:     user1.Username,
+		AccountID: toAccount1.ID,
+		Currency:  util.USD,
+		TrustedAt: time.Now().Add(-time.Hour),
+	}
+
+	testCases := []struct {
+		name          string
+		contentType   string
+		query         string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "JSON",
+			contentType: "application/json",
+			body: fmt.Sprintf(`{"from_account_id": %d, "mode": "atomic", "items": [
+				{"to_account_id": %d, "amount": 100, "currency": "USD", "reference": "salary"},
+				{"to_account_id": %d, "amount": 50, "currency": "USD"}]}`, fromAccount.ID, toAccount1.ID, toAccount1.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetTransferAllowance(gomock.Any(), gomock.Eq(allowanceArg)).Times(1).Return(db.TransferAllowance{}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount1.ID)).Times(1).Return(toAccount1, nil)
+				store.EXPECT().GetBeneficiaryByAccount(gomock.Any(), gomock.Eq(beneficiaryArg)).Times(1).Return(beneficiary, nil)
+
+				arg := db.CreateTransferBatchTxParams{
+					Owner:         user1.Username,
+					FromAccountID: fromAccount.ID,
+					Mode:          util.BatchModeAtomic,
+					Items: []db.CreateTransferBatchLine{
+						{LineNumber: 1, ToAccountID: toAccount1.ID, Amount: 100, Currency: util.USD, Reference: "salary"},
+						{LineNumber: 2, ToAccountID: toAccount1.ID, Amount: 50, Currency: util.USD},
+					},
+				}
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferBatchResult{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name:        "CSV",
+			contentType: "text/csv",
+			query:       fmt.Sprintf("?from_account_id=%d&mode=best_effort", fromAccount.ID),
+			body:        fmt.Sprintf("to_account_id,amount,currency,reference\n%d,100,USD,rent\n", toAccount1.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetTransferAllowance(gomock.Any(), gomock.Eq(allowanceArg)).Times(1).Return(db.TransferAllowance{}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount1.ID)).Times(1).Return(toAccount1, nil)
+				store.EXPECT().GetBeneficiaryByAccount(gomock.Any(), gomock.Eq(beneficiaryArg)).Times(1).Return(beneficiary, nil)
+
+				arg := db.CreateTransferBatchTxParams{
+					Owner:         user1.Username,
+					FromAccountID: fromAccount.ID,
+					Mode:          util.BatchModeBestEffort,
+					Items: []db.CreateTransferBatchLine{
+						{LineNumber: 1, ToAccountID: toAccount1.ID, Amount: 100, Currency: util.USD, Reference: "rent"},
+					},
+				}
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferBatchResult{}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name:        "InvalidLines",
+			contentType: "text/csv",
+			query:       fmt.Sprintf("?from_account_id=%d&mode=atomic", fromAccount.ID),
+			body:        fmt.Sprintf("to_account_id,amount,currency\n%d,100,USD\n%d,0,USD\n%d,10,EUR\n999999,10,USD\n", toAccount1.ID, toAccount1.ID, toAccount2.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetTransferAllowance(gomock.Any(), gomock.Eq(allowanceArg)).Times(1).Return(db.TransferAllowance{}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount1.ID)).Times(1).Return(toAccount1, nil)
+				store.EXPECT().GetBeneficiaryByAccount(gomock.Any(), gomock.Eq(beneficiaryArg)).Times(1).Return(beneficiary, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(999999))).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				var response struct {
+					Errors []transferBatchLineError `json:"errors"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Len(t, response.Errors, 3)
+				require.Equal(t, 2, response.Errors[0].Line)
+				require.Equal(t, 3, response.Errors[1].Line)
+				require.Equal(t, 4, response.Errors[2].Line)
+			},
+		},
+		{
+			name:        "OverDailyLimit",
+			contentType: "application/json",
+			body: fmt.Sprintf(`{"from_account_id": %d, "mode": "atomic", "items": [
+				{"to_account_id": %d, "amount": 100, "currency": "USD"},
+				{"to_account_id": %d, "amount": 100, "currency": "USD"}]}`, fromAccount.ID, toAccount1.ID, toAccount1.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				remaining := int64(150)
+				allowance := db.TransferAllowance{Currency: util.USD, PerTransactionLimit: 100, DailyLimit: 150, DailyRemaining: &remaining}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetTransferAllowance(gomock.Any(), gomock.Eq(allowanceArg)).Times(1).Return(allowance, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount1.ID)).Times(1).Return(toAccount1, nil)
+				store.EXPECT().GetBeneficiaryByAccount(gomock.Any(), gomock.Eq(beneficiaryArg)).Times(1).Return(beneficiary, nil)
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:        "UntrustedRecipient",
+			contentType: "application/json",
+			body: fmt.Sprintf(`{"from_account_id": %d, "mode": "atomic", "items": [
+				{"to_account_id": %d, "amount": 100, "currency": "USD"}]}`, fromAccount.ID, toAccount1.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				coolingOff := beneficiary
+				coolingOff.TrustedAt = time.Now().Add(time.Hour)
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetTransferAllowance(gomock.Any(), gomock.Eq(allowanceArg)).Times(1).Return(db.TransferAllowance{}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount1.ID)).Times(1).Return(toAccount1, nil)
+				store.EXPECT().GetBeneficiaryByAccount(gomock.Any(), gomock.Eq(beneficiaryArg)).Times(1).Return(coolingOff, nil)
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				var response struct {
+					Errors []transferBatchLineError `json:"errors"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Len(t, response.Errors, 1)
+				require.Equal(t, 1, response.Errors[0].Line)
+			},
+		},
+		{
+			name:        "BadCSVHeader",
+			contentType: "text/csv",
+			query:       fmt.Sprintf("?from_account_id=%d&mode=atomic", fromAccount.ID),
+			body:        "account,amount\n1,100\n",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateTransferBatchTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers/batch"+tc.query, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", tc.contentType)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestGetTransferBatchReportAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	batch := db.TransferBatch{
+		ID:     util.RandomInt(1, 1000),
+		Owner:  user.Username,
+		Status: util.BatchStatusPartiallyCompleted,
+	}
+	items := []db.TransferBatchItem{
+		{LineNumber: 1, ToAccountID: 2, Amount: 100, Currency: util.USD, Status: util.BatchItemStatusSucceeded, TransferID: sql.NullInt64{Int64: 9, Valid: true}},
+		{LineNumber: 2, ToAccountID: 3, Amount: 50, Currency: util.USD, Status: util.BatchItemStatusFailed, Error: "insufficient available balance"},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(batch, nil)
+	store.EXPECT().ListTransferBatchItems(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(items, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/transfers/batch/%d/report", batch.ID)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+
+	expected := "line,to_account_id,amount,currency,reference,status,error,transfer_id\n" +
+		"1,2,100,USD,,succeeded,,9\n" +
+		"2,3,50,USD,,failed,insufficient available balance,\n"
+	require.Equal(t, expected, recorder.Body.String())
+}
+
+func TestGetTransferBatchAPINotOwner(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	batch := db.TransferBatch{ID: util.RandomInt(1, 1000), Owner: user1.Username}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetTransferBatch(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(batch, nil)
+	store.EXPECT().ListTransferBatchItems(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/transfers/batch/%d", batch.ID)
+	request, err := http.NewRequest(http.MethodGet, url, bytes.NewReader(nil))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/statement"
+	"github.com/malcolmmaima/maimabank/token"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+type transferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// fields left out keep their current value, an empty string clears them
+type updateTransferDetailsRequest struct {
+	Category    *string `json:"category" binding:"omitempty,category"`
+	Description *string `json:"description" binding:"omitempty,max=140"`
+}
+
+// either party of a transfer can set their own category and description on it,
+// the sender's are kept apart from the recipient's so neither sees the other's
+func (server *Server) updateTransferDetails(ctx *gin.Context) {
+	var uri transferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateTransferDetailsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, _, isSender, isRecipient, err := server.transferSides(ctx, transfer)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !isSender && !isRecipient {
+		err := errors.New("transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// a transfer between two of the user's own accounts updates both sides
+	if isSender {
+		transfer, err = server.store.UpdateTransferSenderDetails(ctx, db.UpdateTransferSenderDetailsParams{
+			Category:    valueOr(req.Category, transfer.SenderCategory),
+			Description: valueOr(req.Description, transfer.SenderDescription),
+			ID:          transfer.ID,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	if isRecipient {
+		transfer, err = server.store.UpdateTransferRecipientDetails(ctx, db.UpdateTransferRecipientDetailsParams{
+			Category:    valueOr(req.Category, transfer.RecipientCategory),
+			Description: valueOr(req.Description, transfer.RecipientDescription),
+			ID:          transfer.ID,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, hideOtherPartyDetails(transfer, isSender, isRecipient))
+}
+
+// a transfer with the display names of the accounts on either side
+type transferResponse struct {
+	db.Transfer
+	FromAccountName string `json:"from_account_name"`
+	ToAccountName   string `json:"to_account_name"`
+}
+
+// either party of a transfer can look it up, each only sees their own category and description
+func (server *Server) getTransfer(ctx *gin.Context) {
+	var uri transferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	fromAccount, toAccount, isSender, isRecipient, err := server.transferSides(ctx, transfer)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !isSender && !isRecipient {
+		err := errors.New("transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	fromName, err := server.accountDisplayName(ctx, fromAccount)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	toName, err := server.accountDisplayName(ctx, toAccount)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transferResponse{
+		Transfer:        hideOtherPartyDetails(transfer, isSender, isRecipient),
+		FromAccountName: fromName,
+		ToAccountName:   toName,
+	})
+}
+
+// transferSides returns the accounts of a transfer and whether the authenticated user can
+// view the sending and the receiving one
+func (server *Server) transferSides(ctx *gin.Context, transfer db.Transfer) (fromAccount db.Account, toAccount db.Account, isSender bool, isRecipient bool, err error) {
+	fromAccount, err = server.store.GetAccount(ctx, transfer.FromAccountID)
+	if err != nil {
+		return
+	}
+
+	toAccount, err = server.store.GetAccount(ctx, transfer.ToAccountID)
+	if err != nil {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	sender, err := db.AccountMembership(ctx, server.store, fromAccount, authPayload.Username)
+	if err != nil {
+		return
+	}
+
+	recipient, err := db.AccountMembership(ctx, server.store, toAccount, authPayload.Username)
+	if err != nil {
+		return
+	}
+
+	isSender = util.AccountRoleAllows(sender.Role, util.AccountPermissionView)
+	isRecipient = util.AccountRoleAllows(recipient.Role, util.AccountPermissionView)
+	return
+}
+
+// hideOtherPartyDetails clears the category and description of the side of a transfer
+// the user isn't on, so neither party sees what the other filed it under
+func hideOtherPartyDetails(transfer db.Transfer, isSender bool, isRecipient bool) db.Transfer {
+	if !isSender {
+		transfer.SenderCategory = ""
+		transfer.SenderDescription = ""
+	}
+	if !isRecipient {
+		transfer.RecipientCategory = ""
+		transfer.RecipientDescription = ""
+	}
+	return transfer
+}
+
+// accountDisplayName is the name shown for an account: the bank for its own ledger accounts,
+// the organization for business accounts and the owner's full name otherwise
+func (server *Server) accountDisplayName(ctx *gin.Context, account db.Account) (string, error) {
+	if util.IsInternalAccountType(account.AccountType) {
+		return statement.BankName, nil
+	}
+
+	if account.OrganizationID.Valid {
+		organization, err := server.store.GetOrganization(ctx, account.OrganizationID.Int64)
+		if err != nil {
+			return "", err
+		}
+		return organization.Name, nil
+	}
+
+	owner, err := server.store.GetUser(ctx, account.Owner)
+	if err != nil {
+		return "", err
+	}
+	return owner.FullName, nil
+}
+
+// valueOr returns the value value points to, or current if it is nil
+func valueOr(value *string, current string) string {
+	if value == nil {
+		return current
+	}
+	return *value
+}
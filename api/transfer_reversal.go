@@ -0,0 +1,184 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/token"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// send all or part of a transfer back, an amount of 0 sends back whatever is left
+type reverseTransferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type reverseTransferRequest struct {
+	Amount int64 `json:"amount" binding:"min=0"`
+}
+
+// ops staff can reverse any transfer
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	uri, req, valid := bindReverseTransferRequest(ctx)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !server.requireRole(ctx, authPayload.Username, util.OpsRole) {
+		return
+	}
+
+	server.reverse(ctx, db.ReverseTransferTxParams{
+		TransferID: uri.ID,
+		Amount:     req.Amount,
+		Kind:       util.TransferKindReversal,
+	})
+}
+
+// the recipient of a transfer can refund it to the sender
+func (server *Server) refundTransfer(ctx *gin.Context) {
+	uri, req, valid := bindReverseTransferRequest(ctx)
+	if !valid {
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, transfer.ToAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// a refund is a payment out of the recipient's account
+	amount := req.Amount
+	if amount == 0 {
+		amount = transfer.Amount - transfer.ReversedAmount
+	}
+	if !server.authorizeAccount(ctx, toAccount, util.AccountPermissionPay, amount) {
+		return
+	}
+
+	server.reverse(ctx, db.ReverseTransferTxParams{
+		TransferID: transfer.ID,
+		Amount:     req.Amount,
+		Kind:       util.TransferKindRefund,
+	})
+}
+
+// the request body is optional, leaving it out reverses the whole transfer
+func bindReverseTransferRequest(ctx *gin.Context) (reverseTransferURI, reverseTransferRequest, bool) {
+	var uri reverseTransferURI
+	var req reverseTransferRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return uri, req, false
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return uri, req, false
+	}
+
+	return uri, req, true
+}
+
+func (server *Server) reverse(ctx *gin.Context, arg db.ReverseTransferTxParams) {
+	result, err := server.store.ReverseTransferTx(ctx, arg)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		case errors.Is(err, db.ErrTransferNotReversible),
+			errors.Is(err, db.ErrTransferAlreadyReversed),
+			errors.Is(err, db.ErrReversalAmountTooLarge),
+			errors.Is(err, db.ErrInsufficientAvailableBalance):
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// requireRole makes sure username has role
+func (server *Server) requireRole(ctx *gin.Context, username string, role string) bool {
+	user, err := server.store.GetUser(ctx, username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+
+	if user.Role != role {
+		err := fmt.Errorf("only %s users can do this", role)
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+// give a user a role
+type updateUserRoleURI struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+type updateUserRoleRequest struct {
+	Role string `json:"role" binding:"required,role"`
+}
+
+func (server *Server) updateUserRole(ctx *gin.Context) {
+	var uri updateUserRoleURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req updateUserRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != "admin" {
+		err := errors.New("only admin can change user roles")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.UpdateUserRole(ctx, db.UpdateUserRoleParams{
+		Username: uri.Username,
+		Role:     req.Role,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
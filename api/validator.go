@@ -11,4 +11,68 @@ var validCurrency validator.Func = func(fl validator.FieldLevel) bool {
 		return false
 	}
 	return util.IsSupportedCurrency(currency)
-}
\ No newline at end of file
+}
+
+var validTransferType validator.Func = func(fl validator.FieldLevel) bool {
+	transferType, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+	return util.IsSupportedTransferType(transferType)
+}
+
+var validTier validator.Func = func(fl validator.FieldLevel) bool {
+	tier, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+	return util.IsSupportedTier(tier)
+}
+
+var validRole validator.Func = func(fl validator.FieldLevel) bool {
+	role, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+	return util.IsSupportedRole(role)
+}
+
+var validFrequency validator.Func = func(fl validator.FieldLevel) bool {
+	frequency, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+	return util.IsSupportedFrequency(frequency)
+}
+
+var validCategory validator.Func = func(fl validator.FieldLevel) bool {
+	category, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+	return util.IsSupportedCategory(category)
+}
+
+var validAccountRole validator.Func = func(fl validator.FieldLevel) bool {
+	role, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+	return util.IsSupportedAccountRole(role)
+}
+
+var validOrganizationRole validator.Func = func(fl validator.FieldLevel) bool {
+	role, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+	return util.IsSupportedOrganizationRole(role)
+}
+
+var validJournalKind validator.Func = func(fl validator.FieldLevel) bool {
+	kind, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+	return util.IsManualJournalKind(kind)
+}
@@ -0,0 +1,13 @@
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'maimabank_system');
+
+DELETE FROM "accounts" WHERE "owner" = 'maimabank_system';
+
+DELETE FROM "users" WHERE "username" = 'maimabank_system';
+
+DROP TABLE IF EXISTS "fee_waivers";
+
+DROP TABLE IF EXISTS "fee_schedules";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "fee";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "account_type";
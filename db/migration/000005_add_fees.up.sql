@@ -0,0 +1,47 @@
+ALTER TABLE "accounts" ADD COLUMN "account_type" varchar NOT NULL DEFAULT 'personal';
+
+ALTER TABLE "transfers" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "transfers"."fee" IS 'charged to the sender on top of amount';
+
+CREATE TABLE "fee_schedules" (
+  "id" bigserial PRIMARY KEY,
+  "transfer_type" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "min_amount" bigint NOT NULL DEFAULT 0,
+  "flat_fee" bigint NOT NULL DEFAULT 0,
+  "percentage_bps" bigint NOT NULL DEFAULT 0,
+  "min_fee" bigint NOT NULL DEFAULT 0,
+  "max_fee" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "fee_waivers" (
+  "id" bigserial PRIMARY KEY,
+  "account_type" varchar NOT NULL,
+  "transfer_type" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "accounts" ("account_type", "currency");
+
+COMMENT ON COLUMN "fee_schedules"."min_amount" IS 'lower bound of the tier this schedule applies to';
+
+COMMENT ON COLUMN "fee_schedules"."percentage_bps" IS 'basis points, 100 = 1%';
+
+COMMENT ON COLUMN "fee_schedules"."max_fee" IS '0 means no cap';
+
+-- one schedule per tier, the tier with the highest min_amount not above the transfer amount applies
+ALTER TABLE "fee_schedules" ADD CONSTRAINT "transfer_type_currency_min_amount_key" UNIQUE ("transfer_type", "currency", "min_amount");
+
+ALTER TABLE "fee_waivers" ADD CONSTRAINT "account_type_transfer_type_key" UNIQUE ("account_type", "transfer_type");
+
+-- internal user owning the bank's own accounts, the underscore keeps it out of reach of signups (alphanum only)
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email") VALUES
+  ('maimabank_system', '!', 'Maima Bank', 'system@maimabank.com');
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "account_type") VALUES
+  ('maimabank_system', 0, 'USD', 'fee_income'),
+  ('maimabank_system', 0, 'EUR', 'fee_income'),
+  ('maimabank_system', 0, 'KES', 'fee_income'),
+  ('maimabank_system', 0, 'GBP', 'fee_income');
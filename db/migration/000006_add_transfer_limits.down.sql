@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "transfer_limit_overrides";
+
+DROP TABLE IF EXISTS "transfer_limits";
+
+DROP INDEX IF EXISTS "transfers_from_account_id_created_at_idx";
+
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "tier";
@@ -0,0 +1,33 @@
+ALTER TABLE "users" ADD COLUMN "tier" varchar NOT NULL DEFAULT 'standard';
+
+CREATE TABLE "transfer_limits" (
+  "id" bigserial PRIMARY KEY,
+  "tier" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "per_transaction" bigint NOT NULL DEFAULT 0,
+  "daily" bigint NOT NULL DEFAULT 0,
+  "monthly" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_limit_overrides" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "per_transaction" bigint NOT NULL DEFAULT 0,
+  "daily" bigint NOT NULL DEFAULT 0,
+  "monthly" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
+
+COMMENT ON COLUMN "transfer_limits"."per_transaction" IS '0 means no limit';
+
+COMMENT ON COLUMN "transfer_limit_overrides"."per_transaction" IS '0 means no limit';
+
+ALTER TABLE "transfer_limits" ADD CONSTRAINT "tier_currency_key" UNIQUE ("tier", "currency");
+
+ALTER TABLE "transfer_limit_overrides" ADD CONSTRAINT "username_currency_key" UNIQUE ("username", "currency");
+
+ALTER TABLE "transfer_limit_overrides" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,13 @@
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "transfer_id";
+
+ALTER TABLE IF EXISTS "transfers" DROP CONSTRAINT IF EXISTS "reversed_amount_check";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "reversed_amount";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "original_transfer_id";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "status";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "kind";
+
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "role";
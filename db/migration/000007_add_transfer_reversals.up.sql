@@ -0,0 +1,27 @@
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'customer';
+
+ALTER TABLE "transfers" ADD COLUMN "kind" varchar NOT NULL DEFAULT 'transfer';
+
+ALTER TABLE "transfers" ADD COLUMN "status" varchar NOT NULL DEFAULT 'completed';
+
+ALTER TABLE "transfers" ADD COLUMN "original_transfer_id" bigint;
+
+ALTER TABLE "transfers" ADD COLUMN "reversed_amount" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+CREATE INDEX ON "transfers" ("original_transfer_id");
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+COMMENT ON COLUMN "transfers"."kind" IS 'transfer, reversal or refund';
+
+COMMENT ON COLUMN "transfers"."original_transfer_id" IS 'the transfer a reversal or refund compensates';
+
+COMMENT ON COLUMN "transfers"."reversed_amount" IS 'sum of reversals and refunds, never above amount';
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("original_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfers" ADD CONSTRAINT "reversed_amount_check" CHECK ("reversed_amount" >= 0 AND "reversed_amount" <= "amount");
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
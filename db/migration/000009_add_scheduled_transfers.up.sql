@@ -0,0 +1,39 @@
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "frequency" varchar NOT NULL,
+  "day_of_month" integer NOT NULL DEFAULT 0,
+  "next_run_at" timestamptz NOT NULL,
+  "retry_at" timestamptz,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "failed_attempts" integer NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "last_run_at" timestamptz,
+  "last_transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+
+CREATE INDEX ON "scheduled_transfers" ("status", "next_run_at");
+
+COMMENT ON COLUMN "scheduled_transfers"."amount" IS 'must be positive';
+
+COMMENT ON COLUMN "scheduled_transfers"."frequency" IS 'once, daily, weekly, monthly or end_of_month';
+
+COMMENT ON COLUMN "scheduled_transfers"."day_of_month" IS 'only used by monthly schedules, clamped to the length of the month';
+
+COMMENT ON COLUMN "scheduled_transfers"."retry_at" IS 'set while a failed run is waiting to be retried';
+
+COMMENT ON COLUMN "scheduled_transfers"."status" IS 'active, paused, completed or failed';
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("last_transfer_id") REFERENCES "transfers" ("id");
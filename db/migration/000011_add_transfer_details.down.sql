@@ -0,0 +1,11 @@
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "recipient_category";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "sender_category";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "recipient_description";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "sender_description";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "reference";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "memo";
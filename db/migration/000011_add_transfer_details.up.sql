@@ -0,0 +1,25 @@
+ALTER TABLE "transfers" ADD COLUMN "memo" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "reference" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "sender_description" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "recipient_description" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "sender_category" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "recipient_category" varchar NOT NULL DEFAULT '';
+
+CREATE INDEX ON "transfers" ("reference");
+
+COMMENT ON COLUMN "transfers"."memo" IS 'free text from the sender, visible to both parties';
+
+COMMENT ON COLUMN "transfers"."reference" IS 'end-to-end reference used for reconciliation';
+
+COMMENT ON COLUMN "transfers"."sender_description" IS 'only shown on the sender''s statement';
+
+COMMENT ON COLUMN "transfers"."recipient_description" IS 'only shown on the recipient''s statement';
+
+COMMENT ON COLUMN "transfers"."sender_category" IS 'set and edited by the sender, empty if uncategorised';
+
+COMMENT ON COLUMN "transfers"."recipient_category" IS 'set and edited by the recipient, empty if uncategorised';
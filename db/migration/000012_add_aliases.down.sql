@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "default_accounts";
+
+DROP TABLE IF EXISTS "aliases";
@@ -0,0 +1,40 @@
+CREATE TABLE "aliases" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "alias_type" varchar NOT NULL,
+  "value" varchar NOT NULL,
+  "hashed_code" varchar NOT NULL,
+  "code_expires_at" timestamptz NOT NULL,
+  "failed_attempts" integer NOT NULL DEFAULT 0,
+  "verified_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "default_accounts" (
+  "owner" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "account_id" bigint NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("owner", "currency")
+);
+
+-- anyone can claim an alias but only one user can verify it
+CREATE UNIQUE INDEX ON "aliases" ("alias_type", "value") WHERE "verified_at" IS NOT NULL;
+
+CREATE INDEX ON "aliases" ("owner");
+
+COMMENT ON COLUMN "aliases"."alias_type" IS 'email or phone, usernames are aliases of themselves';
+
+COMMENT ON COLUMN "aliases"."value" IS 'lower case email or E.164 phone number';
+
+COMMENT ON COLUMN "aliases"."hashed_code" IS 'bcrypt hash of the verification code sent to the alias';
+
+COMMENT ON COLUMN "default_accounts"."account_id" IS 'the account payments to the owner''s aliases go to in this currency';
+
+ALTER TABLE "aliases" ADD CONSTRAINT "owner_alias_type_value_key" UNIQUE ("owner", "alias_type", "value");
+
+ALTER TABLE "aliases" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "default_accounts" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "default_accounts" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
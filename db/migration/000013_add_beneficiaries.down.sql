@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "transfer_confirmations";
+
+DROP TABLE IF EXISTS "beneficiaries";
@@ -0,0 +1,43 @@
+CREATE TABLE "beneficiaries" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "nickname" varchar NOT NULL,
+  "account_id" bigint NOT NULL,
+  "alias" varchar NOT NULL DEFAULT '',
+  "currency" varchar NOT NULL,
+  "daily_limit" bigint NOT NULL DEFAULT 0,
+  "trusted_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_confirmations" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "hashed_code" varchar NOT NULL,
+  "failed_attempts" integer NOT NULL DEFAULT 0,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "transfer_confirmations" ("username", "from_account_id", "to_account_id");
+
+COMMENT ON COLUMN "beneficiaries"."alias" IS 'the alias the beneficiary was saved by, empty if saved by account';
+
+COMMENT ON COLUMN "beneficiaries"."daily_limit" IS '0 means no limit';
+
+COMMENT ON COLUMN "beneficiaries"."trusted_at" IS 'end of the cooling-off period, transfers before it need a confirmation code';
+
+COMMENT ON COLUMN "transfer_confirmations"."hashed_code" IS 'bcrypt hash of the code sent to one of the user''s verified aliases';
+
+ALTER TABLE "beneficiaries" ADD CONSTRAINT "owner_account_id_key" UNIQUE ("owner", "account_id");
+
+ALTER TABLE "beneficiaries" ADD CONSTRAINT "owner_nickname_key" UNIQUE ("owner", "nickname");
+
+ALTER TABLE "beneficiaries" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "beneficiaries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "transfer_confirmations" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
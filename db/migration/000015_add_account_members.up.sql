@@ -0,0 +1,24 @@
+CREATE TABLE "account_members" (
+  "account_id" bigint NOT NULL,
+  "username" varchar NOT NULL,
+  "role" varchar NOT NULL,
+  "payment_limit" bigint NOT NULL DEFAULT 0,
+  "invited_by" varchar NOT NULL,
+  "accepted_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "username")
+);
+
+CREATE INDEX ON "account_members" ("username");
+
+COMMENT ON COLUMN "account_members"."role" IS 'co_owner, viewer or payer, the owner is accounts.owner';
+
+COMMENT ON COLUMN "account_members"."payment_limit" IS 'largest single payment a payer can make, 0 means no limit';
+
+COMMENT ON COLUMN "account_members"."accepted_at" IS 'null until the invited user accepts';
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("username");
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "organization_transfer_approvals";
+
+DROP TABLE IF EXISTS "organization_transfers";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "organization_id";
+
+DROP TABLE IF EXISTS "organization_members";
+
+DROP TABLE IF EXISTS "organizations";
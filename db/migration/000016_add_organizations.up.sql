@@ -0,0 +1,81 @@
+CREATE TABLE "organizations" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "approval_threshold" bigint NOT NULL,
+  "required_approvals" int NOT NULL DEFAULT 1,
+  "created_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "organization_members" (
+  "organization_id" bigint NOT NULL,
+  "username" varchar NOT NULL,
+  "role" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("organization_id", "username")
+);
+
+ALTER TABLE "accounts" ADD COLUMN "organization_id" bigint;
+
+CREATE TABLE "organization_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "organization_id" bigint NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "memo" varchar NOT NULL DEFAULT '',
+  "initiated_by" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending_approval',
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "organization_transfer_approvals" (
+  "organization_transfer_id" bigint NOT NULL,
+  "username" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("organization_transfer_id", "username")
+);
+
+CREATE INDEX ON "organization_members" ("username");
+
+CREATE INDEX ON "accounts" ("organization_id");
+
+CREATE INDEX ON "organization_transfers" ("organization_id", "status");
+
+COMMENT ON COLUMN "organizations"."approval_threshold" IS 'transfers above this amount, in the account''s currency, wait for approval';
+
+COMMENT ON COLUMN "organizations"."required_approvals" IS 'distinct approvers, other than the initiator, a transfer needs';
+
+COMMENT ON COLUMN "organization_members"."role" IS 'admin, approver or initiator';
+
+COMMENT ON COLUMN "accounts"."organization_id" IS 'set on business accounts, their payments go through the organization';
+
+COMMENT ON COLUMN "organization_transfers"."status" IS 'pending_approval, executed or rejected';
+
+COMMENT ON COLUMN "organization_transfers"."transfer_id" IS 'set once the quorum is met and the transfer is executed';
+
+ALTER TABLE "organizations" ADD CONSTRAINT "required_approvals_check" CHECK ("required_approvals" > 0);
+
+ALTER TABLE "organizations" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("username");
+
+ALTER TABLE "organization_members" ADD FOREIGN KEY ("organization_id") REFERENCES "organizations" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "organization_members" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("organization_id") REFERENCES "organizations" ("id");
+
+ALTER TABLE "organization_transfers" ADD FOREIGN KEY ("organization_id") REFERENCES "organizations" ("id");
+
+ALTER TABLE "organization_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "organization_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "organization_transfers" ADD FOREIGN KEY ("initiated_by") REFERENCES "users" ("username");
+
+ALTER TABLE "organization_transfers" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "organization_transfer_approvals" ADD FOREIGN KEY ("organization_transfer_id") REFERENCES "organization_transfers" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "organization_transfer_approvals" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "pot_id";
+
+DROP TABLE IF EXISTS "pots";
@@ -0,0 +1,37 @@
+CREATE TABLE "pots" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "name" varchar NOT NULL,
+  "balance" bigint NOT NULL DEFAULT 0,
+  "target_amount" bigint NOT NULL DEFAULT 0,
+  "target_date" date,
+  "round_up" boolean NOT NULL DEFAULT false,
+  "closed_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "entries" ADD COLUMN "pot_id" bigint;
+
+CREATE INDEX ON "pots" ("account_id");
+
+CREATE UNIQUE INDEX ON "pots" ("account_id", "name") WHERE "closed_at" IS NULL;
+
+CREATE UNIQUE INDEX ON "pots" ("account_id") WHERE "round_up" AND "closed_at" IS NULL;
+
+CREATE INDEX ON "entries" ("pot_id");
+
+COMMENT ON COLUMN "pots"."balance" IS 'money set aside from the account, not part of its balance';
+
+COMMENT ON COLUMN "pots"."target_amount" IS '0 means no target';
+
+COMMENT ON COLUMN "pots"."round_up" IS 'outgoing transfers from the account are rounded up into this pot, one pot per account';
+
+COMMENT ON COLUMN "pots"."closed_at" IS 'pots are closed rather than deleted so their entries keep pointing at them';
+
+COMMENT ON COLUMN "entries"."pot_id" IS 'set on moves between the account and one of its pots';
+
+ALTER TABLE "pots" ADD CONSTRAINT "pot_balance_check" CHECK ("balance" >= 0);
+
+ALTER TABLE "pots" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("pot_id") REFERENCES "pots" ("id");
@@ -0,0 +1,17 @@
+DROP TRIGGER IF EXISTS "journal_balanced" ON "entries";
+
+DROP FUNCTION IF EXISTS check_journal_balanced();
+
+DELETE FROM "entries" WHERE "account_id" IN (
+  SELECT "id" FROM "accounts"
+  WHERE "owner" = 'maimabank_system'
+  AND "account_type" IN ('fx_position', 'interest_expense', 'suspense', 'pot_holding')
+);
+
+DELETE FROM "accounts"
+WHERE "owner" = 'maimabank_system'
+AND "account_type" IN ('fx_position', 'interest_expense', 'suspense', 'pot_holding');
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "journal_id";
+
+DROP TABLE IF EXISTS "journals";
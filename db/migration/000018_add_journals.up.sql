@@ -0,0 +1,69 @@
+-- a journal is one posting, its entries sum to zero in every currency
+CREATE TABLE "journals" (
+  "id" bigserial PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "transfer_id" bigint,
+  "memo" varchar NOT NULL DEFAULT '',
+  "created_by" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "entries" ADD COLUMN "journal_id" bigint;
+
+CREATE INDEX ON "journals" ("transfer_id");
+
+CREATE INDEX ON "entries" ("journal_id");
+
+COMMENT ON COLUMN "journals"."created_by" IS 'ops user who posted a manual journal, empty for postings made by the bank';
+
+COMMENT ON COLUMN "entries"."journal_id" IS 'empty on entries posted before journals were introduced';
+
+ALTER TABLE "journals" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+-- older entries were never grouped into journals, every new entry has to belong to one
+ALTER TABLE "entries" ADD CONSTRAINT "entries_journal_id_check" CHECK ("journal_id" IS NOT NULL) NOT VALID;
+
+-- checked when the transaction commits, once all the entries of the journal are in
+CREATE FUNCTION check_journal_balanced() RETURNS trigger AS $$
+BEGIN
+  IF EXISTS (
+    SELECT a.currency
+    FROM entries e
+    JOIN accounts a ON a.id = e.account_id
+    WHERE e.journal_id = NEW.journal_id
+    GROUP BY a.currency
+    HAVING SUM(e.amount) <> 0
+  ) THEN
+    RAISE EXCEPTION 'journal % does not balance', NEW.journal_id
+      USING ERRCODE = 'check_violation', CONSTRAINT = 'journal_balanced';
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER "journal_balanced"
+AFTER INSERT OR UPDATE ON "entries"
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+EXECUTE FUNCTION check_journal_balanced();
+
+-- the rest of the bank's chart of accounts, fee income accounts already exist
+INSERT INTO "accounts" ("owner", "balance", "currency", "account_type") VALUES
+  ('maimabank_system', 0, 'USD', 'fx_position'),
+  ('maimabank_system', 0, 'EUR', 'fx_position'),
+  ('maimabank_system', 0, 'KES', 'fx_position'),
+  ('maimabank_system', 0, 'GBP', 'fx_position'),
+  ('maimabank_system', 0, 'USD', 'interest_expense'),
+  ('maimabank_system', 0, 'EUR', 'interest_expense'),
+  ('maimabank_system', 0, 'KES', 'interest_expense'),
+  ('maimabank_system', 0, 'GBP', 'interest_expense'),
+  ('maimabank_system', 0, 'USD', 'suspense'),
+  ('maimabank_system', 0, 'EUR', 'suspense'),
+  ('maimabank_system', 0, 'KES', 'suspense'),
+  ('maimabank_system', 0, 'GBP', 'suspense'),
+  ('maimabank_system', 0, 'USD', 'pot_holding'),
+  ('maimabank_system', 0, 'EUR', 'pot_holding'),
+  ('maimabank_system', 0, 'KES', 'pot_holding'),
+  ('maimabank_system', 0, 'GBP', 'pot_holding');
@@ -0,0 +1,21 @@
+DROP TRIGGER IF EXISTS "journal_balanced" ON "entries";
+
+CREATE CONSTRAINT TRIGGER "journal_balanced"
+AFTER INSERT OR UPDATE ON "entries"
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+EXECUTE FUNCTION check_journal_balanced();
+
+DROP TABLE IF EXISTS "ledger_seals";
+
+DROP INDEX IF EXISTS "entries_account_id_id_idx";
+
+DROP INDEX IF EXISTS "entries_created_at_idx";
+
+DROP INDEX IF EXISTS "transfers_created_at_idx";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "hash";
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "hash";
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "previous_hash";
@@ -0,0 +1,38 @@
+ALTER TABLE "entries" ADD COLUMN "previous_hash" bytea;
+
+ALTER TABLE "entries" ADD COLUMN "hash" bytea;
+
+ALTER TABLE "transfers" ADD COLUMN "hash" bytea;
+
+-- one Merkle root over the hashes of every entry and transfer of a day (UTC), written once the day is over
+CREATE TABLE "ledger_seals" (
+  "day" date PRIMARY KEY,
+  "entry_count" bigint NOT NULL,
+  "transfer_count" bigint NOT NULL,
+  "merkle_root" bytea NOT NULL,
+  "signature" bytea,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "entries" ("account_id", "id");
+
+CREATE INDEX ON "entries" ("created_at");
+
+CREATE INDEX ON "transfers" ("created_at");
+
+COMMENT ON COLUMN "entries"."previous_hash" IS 'hash of the entry before it on the same account, empty on an account''s first hashed entry';
+
+COMMENT ON COLUMN "entries"."hash" IS 'SHA-256 of the entry and its previous_hash, empty on entries posted before hashing was introduced';
+
+COMMENT ON COLUMN "transfers"."hash" IS 'SHA-256 of the fields of the transfer that never change';
+
+COMMENT ON COLUMN "ledger_seals"."signature" IS 'ed25519 signature of merkle_root, empty when no signing key is configured';
+
+-- setting the hash of a new entry shouldn't check its journal again
+DROP TRIGGER IF EXISTS "journal_balanced" ON "entries";
+
+CREATE CONSTRAINT TRIGGER "journal_balanced"
+AFTER INSERT OR UPDATE OF "account_id", "amount", "journal_id" ON "entries"
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+EXECUTE FUNCTION check_journal_balanced();
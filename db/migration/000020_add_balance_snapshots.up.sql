@@ -0,0 +1,20 @@
+-- the balance of an account up to and including one of its entries, so a balance at a point in time
+-- only has to add up the entries posted after the last snapshot before it
+CREATE TABLE "balance_snapshots" (
+  "account_id" bigint NOT NULL,
+  "entry_id" bigint NOT NULL,
+  "balance" bigint NOT NULL,
+  "taken_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "entry_id")
+);
+
+CREATE INDEX ON "balance_snapshots" ("account_id", "taken_at");
+
+COMMENT ON COLUMN "balance_snapshots"."entry_id" IS 'last entry of the account the balance includes';
+
+COMMENT ON COLUMN "balance_snapshots"."taken_at" IS 'latest created_at of the entries the balance includes';
+
+ALTER TABLE "balance_snapshots" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "balance_snapshots" ADD FOREIGN KEY ("entry_id") REFERENCES "entries" ("id");
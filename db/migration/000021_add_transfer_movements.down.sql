@@ -0,0 +1,3 @@
+DROP VIEW IF EXISTS "transfer_movements";
+
+DROP INDEX IF EXISTS "entries_account_id_created_at_idx";
@@ -0,0 +1,24 @@
+-- every entry an account got from a transfer, seen from that account: the amount in its own currency,
+-- the other account and the category the account holder filed it under. Fees the sender paid on a
+-- transfer come after its principal entry and are filed under 'fee'.
+CREATE VIEW "transfer_movements" AS
+SELECT e.id,
+       e.account_id,
+       e.amount,
+       e.created_at,
+       e.transfer_id,
+       (CASE WHEN t.from_account_id = e.account_id THEN t.to_account_id ELSE t.from_account_id END) AS counterparty_account_id,
+       (CASE
+         WHEN t.fee > 0 AND e.account_id = t.from_account_id AND EXISTS (
+           SELECT 1 FROM entries p
+           WHERE p.transfer_id = e.transfer_id
+             AND p.account_id = e.account_id
+             AND p.id < e.id
+         ) THEN 'fee'
+         WHEN t.from_account_id = e.account_id THEN t.sender_category
+         ELSE t.recipient_category
+       END) AS category
+FROM entries e
+JOIN transfers t ON t.id = e.transfer_id;
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS "exchange_rates" DROP COLUMN IF EXISTS "updated_at";
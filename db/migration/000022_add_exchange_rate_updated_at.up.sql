@@ -0,0 +1,5 @@
+ALTER TABLE "exchange_rates" ADD COLUMN "updated_at" timestamptz NOT NULL DEFAULT (now());
+
+UPDATE "exchange_rates" SET "updated_at" = "created_at";
+
+COMMENT ON COLUMN "exchange_rates"."updated_at" IS 'when exchange_rate was last set';
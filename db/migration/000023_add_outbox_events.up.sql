@@ -0,0 +1,23 @@
+-- domain events written in the same transaction as the change they describe, so an event is
+-- recorded if and only if the change is committed. The relay worker publishes them in id order.
+CREATE TABLE "outbox_events" (
+  "id" bigserial PRIMARY KEY,
+  "event_type" varchar NOT NULL,
+  "aggregate_type" varchar NOT NULL,
+  "aggregate_id" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "published_at" timestamptz,
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT ''
+);
+
+CREATE INDEX ON "outbox_events" ("id") WHERE "published_at" IS NULL;
+
+COMMENT ON COLUMN "outbox_events"."event_type" IS 'TransferCompleted, AccountCreated, UserCreated or RateUpdated';
+
+COMMENT ON COLUMN "outbox_events"."aggregate_id" IS 'id of the transfer, account or exchange rate, username of the user';
+
+COMMENT ON COLUMN "outbox_events"."published_at" IS 'null until the relay has handed the event to the publisher';
+
+COMMENT ON COLUMN "outbox_events"."attempts" IS 'failed attempts to publish the event';
@@ -0,0 +1,17 @@
+ALTER TABLE "transfers" ADD COLUMN "from_amount" bigint NOT NULL DEFAULT 0;
+
+-- the sender's debit is the first entry of the transfer posted to the sender's account,
+-- the fee and round-up entries come after it
+UPDATE "transfers" t SET "from_amount" = -e."amount"
+FROM (
+  SELECT DISTINCT ON ("transfer_id", "account_id") "transfer_id", "account_id", "amount"
+  FROM "entries"
+  WHERE "transfer_id" IS NOT NULL
+  ORDER BY "transfer_id", "account_id", "id"
+) e
+WHERE e."transfer_id" = t."id"
+AND e."account_id" = t."from_account_id";
+
+UPDATE "transfers" SET "from_amount" = "amount" WHERE "from_amount" = 0;
+
+COMMENT ON COLUMN "transfers"."from_amount" IS 'debited from the sender before the fee, in the sender''s currency where amount is in the recipient''s';
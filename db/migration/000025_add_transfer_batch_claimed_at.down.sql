@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS "transfer_batches" DROP COLUMN IF EXISTS "claimed_at";
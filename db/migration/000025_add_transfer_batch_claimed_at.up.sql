@@ -0,0 +1,5 @@
+ALTER TABLE "transfer_batches" ADD COLUMN "claimed_at" timestamptz;
+
+UPDATE "transfer_batches" SET "claimed_at" = now() WHERE "status" = 'processing';
+
+COMMENT ON COLUMN "transfer_batches"."claimed_at" IS 'when a processor last claimed the batch, one left processing for too long is claimed again';
@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "outbox_events" DROP COLUMN IF EXISTS "failed_at";
+
+ALTER TABLE IF EXISTS "outbox_events" DROP COLUMN IF EXISTS "next_attempt_at";
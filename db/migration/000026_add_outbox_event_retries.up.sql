@@ -0,0 +1,9 @@
+ALTER TABLE "outbox_events" ADD COLUMN "next_attempt_at" timestamptz NOT NULL DEFAULT (now());
+
+ALTER TABLE "outbox_events" ADD COLUMN "failed_at" timestamptz;
+
+CREATE INDEX ON "outbox_events" ("aggregate_type", "aggregate_id") WHERE "published_at" IS NULL AND "failed_at" IS NULL AND "attempts" > 0;
+
+COMMENT ON COLUMN "outbox_events"."next_attempt_at" IS 'a failed event is not published again before this';
+
+COMMENT ON COLUMN "outbox_events"."failed_at" IS 'set once the event has failed too many times, it is no longer published';
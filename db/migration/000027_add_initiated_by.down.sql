@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "holds" DROP COLUMN IF EXISTS "initiated_by";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "initiated_by";
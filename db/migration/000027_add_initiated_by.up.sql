@@ -0,0 +1,20 @@
+ALTER TABLE "transfers" ADD COLUMN "initiated_by" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "holds" ADD COLUMN "initiated_by" varchar NOT NULL DEFAULT '';
+
+-- usage from before transfers and holds recorded who started them stays with the account owner
+UPDATE "transfers" t SET "initiated_by" = a."owner"
+FROM "accounts" a
+WHERE a."id" = t."from_account_id";
+
+UPDATE "holds" h SET "initiated_by" = a."owner"
+FROM "accounts" a
+WHERE a."id" = h."from_account_id";
+
+CREATE INDEX ON "transfers" ("initiated_by", "created_at");
+
+CREATE INDEX ON "holds" ("initiated_by", "status");
+
+COMMENT ON COLUMN "transfers"."initiated_by" IS 'the user who started the transfer and whose transfer limits it counts against, empty for transfers the bank makes';
+
+COMMENT ON COLUMN "holds"."initiated_by" IS 'the user who placed the hold and whose transfer limits it counts against';
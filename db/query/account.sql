@@ -2,9 +2,10 @@
 INSERT INTO accounts (
   owner,
   balance,
+  available_balance,
   currency
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $2, $3
 ) RETURNING *;
 
 -- name: GetAccount :one
@@ -16,22 +17,55 @@ SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE;
 
+-- name: GetInternalAccount :one
+SELECT * FROM accounts
+WHERE account_type = $1
+AND currency = $2
+LIMIT 1;
+
+-- name: ListInternalAccounts :many
+SELECT * FROM accounts
+WHERE account_type <> 'personal'
+ORDER BY account_type, currency;
+
 -- name: ListAccounts :many
 SELECT * FROM accounts
-WHERE owner = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3;
+WHERE (
+    owner = sqlc.arg(username)
+    OR id IN (
+      SELECT account_id FROM account_members
+      WHERE username = sqlc.arg(username)
+      AND accepted_at IS NOT NULL
+    )
+)
+AND (sqlc.arg(currency)::text = '' OR currency = sqlc.arg(currency))
+AND (
+  sqlc.arg(after_id)::bigint = 0 OR
+  (sqlc.arg(descending)::bool AND id < sqlc.arg(after_id)) OR
+  (NOT sqlc.arg(descending)::bool AND id > sqlc.arg(after_id))
+)
+ORDER BY
+  CASE WHEN sqlc.arg(descending)::bool THEN id END DESC,
+  id
+LIMIT sqlc.arg(page_limit);
 
 -- name: UpdateAccount :one
 UPDATE accounts
-SET balance = $2
+SET balance = $2,
+    available_balance = available_balance + $2 - balance
 WHERE id = $1
 RETURNING *;
 
 -- name: AddAccountBalance :one
 UPDATE accounts
-SET balance = balance + sqlc.arg(amount)
+SET balance = balance + sqlc.arg(amount),
+    available_balance = available_balance + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: AddAccountAvailableBalance :one
+UPDATE accounts
+SET available_balance = available_balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
@@ -0,0 +1,40 @@
+-- name: CreateAccountMember :one
+INSERT INTO account_members (
+  account_id,
+  username,
+  role,
+  payment_limit,
+  invited_by
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetAccountMember :one
+SELECT * FROM account_members
+WHERE account_id = $1
+AND username = $2
+LIMIT 1;
+
+-- name: ListAccountMembers :many
+SELECT * FROM account_members
+WHERE account_id = $1
+ORDER BY created_at;
+
+-- name: ListAccountInvitations :many
+SELECT * FROM account_members
+WHERE username = $1
+AND accepted_at IS NULL
+ORDER BY created_at;
+
+-- name: AcceptAccountMember :one
+UPDATE account_members
+SET accepted_at = now()
+WHERE account_id = $1
+AND username = $2
+AND accepted_at IS NULL
+RETURNING *;
+
+-- name: DeleteAccountMember :exec
+DELETE FROM account_members
+WHERE account_id = $1
+AND username = $2;
@@ -0,0 +1,69 @@
+-- name: CreateAlias :one
+INSERT INTO aliases (
+  owner,
+  alias_type,
+  value,
+  hashed_code,
+  code_expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (owner, alias_type, value) DO UPDATE
+SET hashed_code = EXCLUDED.hashed_code,
+    code_expires_at = EXCLUDED.code_expires_at,
+    failed_attempts = 0
+WHERE aliases.verified_at IS NULL
+RETURNING *;
+
+-- name: GetAlias :one
+SELECT * FROM aliases
+WHERE id = $1 LIMIT 1;
+
+-- name: GetVerifiedAlias :one
+SELECT * FROM aliases
+WHERE alias_type = $1
+AND value = $2
+AND verified_at IS NOT NULL
+LIMIT 1;
+
+-- name: ListAliases :many
+SELECT * FROM aliases
+WHERE owner = $1
+ORDER BY id;
+
+-- name: VerifyAlias :one
+UPDATE aliases
+SET verified_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: AddAliasFailedAttempt :one
+UPDATE aliases
+SET failed_attempts = failed_attempts + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteAlias :exec
+DELETE FROM aliases
+WHERE id = $1;
+
+-- name: SetDefaultAccount :one
+INSERT INTO default_accounts (
+  owner,
+  currency,
+  account_id
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (owner, currency) DO UPDATE
+SET account_id = EXCLUDED.account_id,
+    updated_at = now()
+RETURNING *;
+
+-- name: GetDefaultAccount :one
+SELECT * FROM accounts
+WHERE id = COALESCE(
+  (SELECT account_id FROM default_accounts WHERE default_accounts.owner = $1 AND default_accounts.currency = $2),
+  (SELECT min(id) FROM accounts AS owned WHERE owned.owner = $1 AND owned.currency = $2)
+)
+LIMIT 1;
@@ -0,0 +1,146 @@
+-- The analytics queries cover transfer_movements of an account from previous_since up to until,
+-- split into the previous period before since and the current one from since. Amounts are positive,
+-- direction is 'debit' for money out and 'credit' for money in, and converted amounts are the current
+-- period in the report currency at the rate in exchange_rates, 0 when there is no rate.
+
+-- name: GetAnalyticsTotals :one
+WITH rate AS (
+  SELECT (CASE
+           WHEN a.currency = sqlc.arg(currency)::text THEN 1
+           ELSE COALESCE((
+             SELECT r.exchange_rate FROM exchange_rates r
+             WHERE r.base_currency = a.currency
+               AND r.target_currency = sqlc.arg(currency)
+           ), 0)
+         END)::numeric AS rate
+  FROM accounts a
+  WHERE a.id = sqlc.arg(account_id)
+),
+totals AS (
+  SELECT COALESCE(sum(m.amount) FILTER (WHERE m.amount > 0 AND m.created_at >= sqlc.arg(since)), 0) AS incoming,
+         COALESCE(-sum(m.amount) FILTER (WHERE m.amount < 0 AND m.created_at >= sqlc.arg(since)), 0) AS outgoing,
+         COALESCE(sum(m.amount) FILTER (WHERE m.amount > 0 AND m.created_at < sqlc.arg(since)), 0) AS previous_incoming,
+         COALESCE(-sum(m.amount) FILTER (WHERE m.amount < 0 AND m.created_at < sqlc.arg(since)), 0) AS previous_outgoing,
+         count(*) FILTER (WHERE m.created_at >= sqlc.arg(since)) AS movements
+  FROM transfer_movements m
+  WHERE m.account_id = sqlc.arg(account_id)
+    AND m.created_at >= sqlc.arg(previous_since)
+    AND m.created_at < sqlc.arg(until)
+)
+SELECT t.incoming::bigint AS incoming,
+       t.outgoing::bigint AS outgoing,
+       t.previous_incoming::bigint AS previous_incoming,
+       t.previous_outgoing::bigint AS previous_outgoing,
+       t.movements::bigint AS movements,
+       round(t.incoming * r.rate)::bigint AS converted_incoming,
+       round(t.outgoing * r.rate)::bigint AS converted_outgoing
+FROM totals t, rate r;
+
+-- name: ListCategoryAnalytics :many
+WITH rate AS (
+  SELECT (CASE
+           WHEN a.currency = sqlc.arg(currency)::text THEN 1
+           ELSE COALESCE((
+             SELECT r.exchange_rate FROM exchange_rates r
+             WHERE r.base_currency = a.currency
+               AND r.target_currency = sqlc.arg(currency)
+           ), 0)
+         END)::numeric AS rate
+  FROM accounts a
+  WHERE a.id = sqlc.arg(account_id)
+),
+categories AS (
+  SELECT m.category,
+         (CASE WHEN m.amount < 0 THEN 'debit' ELSE 'credit' END) AS direction,
+         COALESCE(sum(abs(m.amount)) FILTER (WHERE m.created_at >= sqlc.arg(since)), 0) AS amount,
+         COALESCE(sum(abs(m.amount)) FILTER (WHERE m.created_at < sqlc.arg(since)), 0) AS previous_amount,
+         count(*) FILTER (WHERE m.created_at >= sqlc.arg(since)) AS movements
+  FROM transfer_movements m
+  WHERE m.account_id = sqlc.arg(account_id)
+    AND m.created_at >= sqlc.arg(previous_since)
+    AND m.created_at < sqlc.arg(until)
+  GROUP BY 1, 2
+)
+SELECT c.category::varchar AS category,
+       c.direction::varchar AS direction,
+       c.amount::bigint AS amount,
+       c.previous_amount::bigint AS previous_amount,
+       (c.amount - c.previous_amount)::bigint AS change,
+       c.movements::bigint AS movements,
+       round(c.amount * r.rate)::bigint AS converted_amount
+FROM categories c, rate r
+ORDER BY c.direction DESC, c.amount DESC, c.category;
+
+-- name: ListCounterpartyAnalytics :many
+WITH rate AS (
+  SELECT (CASE
+           WHEN a.currency = sqlc.arg(currency)::text THEN 1
+           ELSE COALESCE((
+             SELECT r.exchange_rate FROM exchange_rates r
+             WHERE r.base_currency = a.currency
+               AND r.target_currency = sqlc.arg(currency)
+           ), 0)
+         END)::numeric AS rate
+  FROM accounts a
+  WHERE a.id = sqlc.arg(account_id)
+),
+counterparties AS (
+  SELECT m.counterparty_account_id,
+         (CASE WHEN m.amount < 0 THEN 'debit' ELSE 'credit' END) AS direction,
+         COALESCE(sum(abs(m.amount)) FILTER (WHERE m.created_at >= sqlc.arg(since)), 0) AS amount,
+         COALESCE(sum(abs(m.amount)) FILTER (WHERE m.created_at < sqlc.arg(since)), 0) AS previous_amount,
+         count(*) FILTER (WHERE m.created_at >= sqlc.arg(since)) AS movements
+  FROM transfer_movements m
+  WHERE m.account_id = sqlc.arg(account_id)
+    AND m.created_at >= sqlc.arg(previous_since)
+    AND m.created_at < sqlc.arg(until)
+  GROUP BY 1, 2
+)
+SELECT c.counterparty_account_id::bigint AS counterparty_account_id,
+       COALESCE(o.name, u.full_name, '')::varchar AS counterparty_name,
+       c.direction::varchar AS direction,
+       c.amount::bigint AS amount,
+       c.previous_amount::bigint AS previous_amount,
+       (c.amount - c.previous_amount)::bigint AS change,
+       c.movements::bigint AS movements,
+       round(c.amount * r.rate)::bigint AS converted_amount
+FROM counterparties c
+CROSS JOIN rate r
+JOIN accounts a ON a.id = c.counterparty_account_id
+LEFT JOIN organizations o ON o.id = a.organization_id
+LEFT JOIN users u ON u.username = a.owner
+ORDER BY c.amount DESC, c.counterparty_account_id
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListMonthlyAnalytics :many
+WITH rate AS (
+  SELECT (CASE
+           WHEN a.currency = sqlc.arg(currency)::text THEN 1
+           ELSE COALESCE((
+             SELECT r.exchange_rate FROM exchange_rates r
+             WHERE r.base_currency = a.currency
+               AND r.target_currency = sqlc.arg(currency)
+           ), 0)
+         END)::numeric AS rate
+  FROM accounts a
+  WHERE a.id = sqlc.arg(account_id)
+),
+months AS (
+  SELECT date_trunc('month', m.created_at AT TIME ZONE 'UTC') AS month,
+         COALESCE(sum(m.amount) FILTER (WHERE m.amount > 0), 0) AS incoming,
+         COALESCE(-sum(m.amount) FILTER (WHERE m.amount < 0), 0) AS outgoing,
+         count(*) AS movements
+  FROM transfer_movements m
+  WHERE m.account_id = sqlc.arg(account_id)
+    AND m.created_at >= sqlc.arg(since)
+    AND m.created_at < sqlc.arg(until)
+  GROUP BY 1
+)
+SELECT (m.month AT TIME ZONE 'UTC')::timestamptz AS month,
+       m.incoming::bigint AS incoming,
+       m.outgoing::bigint AS outgoing,
+       m.movements::bigint AS movements,
+       round(m.incoming * r.rate)::bigint AS converted_incoming,
+       round(m.outgoing * r.rate)::bigint AS converted_outgoing
+FROM months m, rate r
+ORDER BY m.month;
@@ -0,0 +1,45 @@
+-- name: CreateBalanceSnapshots :execrows
+WITH latest AS (
+  SELECT DISTINCT ON (account_id) account_id, entry_id, balance, taken_at
+  FROM balance_snapshots
+  ORDER BY account_id, entry_id DESC
+)
+INSERT INTO balance_snapshots (
+  account_id,
+  entry_id,
+  balance,
+  taken_at
+)
+SELECT e.account_id,
+       max(e.id),
+       COALESCE(max(l.balance), 0) + sum(e.amount),
+       GREATEST(max(e.created_at), max(l.taken_at))
+FROM entries e
+LEFT JOIN latest l ON l.account_id = e.account_id
+WHERE e.id > COALESCE(l.entry_id, 0)
+  AND e.created_at < sqlc.arg(before)
+GROUP BY e.account_id;
+
+-- name: GetAccountBalanceAt :one
+WITH snapshot AS (
+  SELECT entry_id, balance
+  FROM balance_snapshots
+  WHERE account_id = sqlc.arg(account_id)
+    AND taken_at < sqlc.arg(at)
+  ORDER BY entry_id DESC
+  LIMIT 1
+)
+SELECT (
+  COALESCE((SELECT balance FROM snapshot), 0) + COALESCE((
+    SELECT sum(amount) FROM entries
+    WHERE account_id = sqlc.arg(account_id)
+      AND id > COALESCE((SELECT entry_id FROM snapshot), 0)
+      AND created_at < sqlc.arg(at)
+  ), 0)
+)::bigint AS balance;
+
+-- name: GetLastBalanceSnapshot :one
+SELECT * FROM balance_snapshots
+WHERE account_id = $1
+ORDER BY entry_id DESC
+LIMIT 1;
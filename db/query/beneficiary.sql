@@ -0,0 +1,64 @@
+-- name: CreateBeneficiary :one
+INSERT INTO beneficiaries (
+  owner,
+  nickname,
+  account_id,
+  alias,
+  currency,
+  daily_limit,
+  trusted_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetBeneficiary :one
+SELECT * FROM beneficiaries
+WHERE id = $1 LIMIT 1;
+
+-- name: GetBeneficiaryByAccount :one
+SELECT * FROM beneficiaries
+WHERE owner = $1
+AND account_id = $2
+LIMIT 1;
+
+-- name: ListBeneficiaries :many
+SELECT * FROM beneficiaries
+WHERE owner = sqlc.arg(owner)
+AND (
+  sqlc.arg(after_id)::bigint = 0 OR
+  (sqlc.arg(descending)::bool AND id < sqlc.arg(after_id)) OR
+  (NOT sqlc.arg(descending)::bool AND id > sqlc.arg(after_id))
+)
+ORDER BY
+  CASE WHEN sqlc.arg(descending)::bool THEN id END DESC,
+  id
+LIMIT sqlc.arg(page_limit);
+
+-- name: UpdateBeneficiary :one
+UPDATE beneficiaries
+SET nickname = $2,
+    daily_limit = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteBeneficiary :exec
+DELETE FROM beneficiaries
+WHERE id = $1;
+
+-- name: GetBeneficiaryTransferTotal :one
+SELECT (
+  COALESCE((
+    SELECT SUM(amount) FROM transfers
+    WHERE initiated_by = sqlc.arg(owner)
+    AND to_account_id = sqlc.arg(to_account_id)
+    AND kind = 'transfer'
+    AND created_at >= sqlc.arg(since)
+  ), 0) +
+  COALESCE((
+    SELECT SUM(amount) FROM holds
+    WHERE initiated_by = sqlc.arg(owner)
+    AND to_account_id = sqlc.arg(to_account_id)
+    AND status = 'pending'
+    AND created_at >= sqlc.arg(since)
+  ), 0)
+)::bigint AS total;
@@ -1,9 +1,13 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id,
+  journal_id,
+  pot_id,
+  previous_hash
 ) VALUES (
-  $1, $2
+  $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -12,7 +16,43 @@ WHERE id = $1 LIMIT 1;
 
 -- name: ListEntries :many
 SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id)
+AND (
+  sqlc.arg(after_id)::bigint = 0 OR
+  (sqlc.arg(descending)::bool AND id < sqlc.arg(after_id)) OR
+  (NOT sqlc.arg(descending)::bool AND id > sqlc.arg(after_id))
+)
+ORDER BY
+  CASE WHEN sqlc.arg(descending)::bool THEN id END DESC,
+  id
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListJournalEntries :many
+SELECT * FROM entries
+WHERE journal_id = $1
+ORDER BY id;
+
+-- name: GetLastEntryHash :one
+SELECT hash FROM entries
 WHERE account_id = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3;
\ No newline at end of file
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: SetEntryHash :one
+UPDATE entries
+SET hash = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: ListEntriesByAccountAfter :many
+SELECT * FROM entries
+WHERE (account_id, id) > (sqlc.arg(account_id)::bigint, sqlc.arg(id)::bigint)
+ORDER BY account_id, id
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListEntryHashes :many
+SELECT hash FROM entries
+WHERE created_at >= sqlc.arg(since)
+AND created_at < sqlc.arg(until)
+AND hash IS NOT NULL
+ORDER BY id;
@@ -19,7 +19,8 @@ ORDER BY base_currency ASC;
 
 -- name: UpdateExchangeRate :one
 UPDATE exchange_rates SET
-  exchange_rate = $2
+  exchange_rate = $2,
+  updated_at = now()
 WHERE id = $1
 RETURNING *;
 
@@ -0,0 +1,50 @@
+-- name: CreateFeeSchedule :one
+INSERT INTO fee_schedules (
+  transfer_type,
+  currency,
+  min_amount,
+  flat_fee,
+  percentage_bps,
+  min_fee,
+  max_fee
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetApplicableFeeSchedule :one
+SELECT * FROM fee_schedules
+WHERE transfer_type = sqlc.arg(transfer_type)
+AND currency = sqlc.arg(currency)
+AND min_amount <= sqlc.arg(amount)
+ORDER BY min_amount DESC
+LIMIT 1;
+
+-- name: ListFeeSchedules :many
+SELECT * FROM fee_schedules
+ORDER BY transfer_type, currency, min_amount;
+
+-- name: DeleteFeeSchedule :exec
+DELETE FROM fee_schedules
+WHERE id = $1;
+
+-- name: CreateFeeWaiver :one
+INSERT INTO fee_waivers (
+  account_type,
+  transfer_type
+) VALUES (
+  $1, $2
+) RETURNING *;
+
+-- name: GetFeeWaiver :one
+SELECT * FROM fee_waivers
+WHERE account_type = $1
+AND transfer_type = $2
+LIMIT 1;
+
+-- name: ListFeeWaivers :many
+SELECT * FROM fee_waivers
+ORDER BY account_type, transfer_type;
+
+-- name: DeleteFeeWaiver :exec
+DELETE FROM fee_waivers
+WHERE id = $1;
@@ -0,0 +1,44 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+  from_account_id,
+  to_account_id,
+  amount,
+  fee,
+  expires_at,
+  initiated_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListExpiredHolds :many
+SELECT * FROM holds
+WHERE status = 'pending'
+AND expires_at <= now()
+ORDER BY expires_at
+LIMIT $1;
+
+-- name: UpdateHold :one
+UPDATE holds
+SET status = $2,
+    captured_amount = $3,
+    transfer_id = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: GetPendingHoldTotal :one
+SELECT COALESCE(SUM(holds.amount), 0)::bigint AS total
+FROM holds
+JOIN accounts ON accounts.id = holds.from_account_id
+WHERE holds.initiated_by = sqlc.arg(username)
+AND accounts.currency = sqlc.arg(currency)
+AND holds.status = 'pending'
+AND holds.created_at >= sqlc.arg(since);
@@ -0,0 +1,18 @@
+-- name: CreateJournal :one
+INSERT INTO journals (
+  kind,
+  transfer_id,
+  memo,
+  created_by
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetJournal :one
+SELECT * FROM journals
+WHERE id = $1 LIMIT 1;
+
+-- name: ListTransferJournals :many
+SELECT * FROM journals
+WHERE transfer_id = $1
+ORDER BY id;
@@ -0,0 +1,41 @@
+-- name: ListAccountBalanceMismatches :many
+SELECT a.id AS account_id, a.currency, a.balance, COALESCE(SUM(e.amount), 0)::bigint AS entries_balance
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- name: ListTransferEntryMismatches :many
+SELECT t.id AS transfer_id, t.amount, t.from_amount, t.fee,
+  COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.to_account_id), 0)::bigint AS credited,
+  COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.from_account_id), 0)::bigint AS debited
+FROM transfers t
+LEFT JOIN entries e ON e.transfer_id = t.id
+GROUP BY t.id
+HAVING COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.to_account_id), 0) <> t.amount
+OR COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.from_account_id), 0) <> -(t.from_amount + t.fee)
+ORDER BY t.id;
+
+-- name: ListUnbalancedJournals :many
+SELECT e.journal_id, a.currency, SUM(e.amount)::bigint AS total
+FROM entries e
+JOIN accounts a ON a.id = e.account_id
+WHERE e.journal_id IS NOT NULL
+GROUP BY e.journal_id, a.currency
+HAVING SUM(e.amount) <> 0
+ORDER BY e.journal_id;
+
+-- name: ListPotHoldingMismatches :many
+WITH pot_totals AS (
+  SELECT a.currency, SUM(p.balance) AS balance
+  FROM pots p
+  JOIN accounts a ON a.id = p.account_id
+  GROUP BY a.currency
+)
+SELECT h.id AS account_id, h.currency, h.balance, COALESCE(pt.balance, 0)::bigint AS pots_balance
+FROM accounts h
+LEFT JOIN pot_totals pt ON pt.currency = h.currency
+WHERE h.account_type = 'pot_holding'
+AND h.balance <> COALESCE(pt.balance, 0)
+ORDER BY h.id;
@@ -0,0 +1,25 @@
+-- name: CreateLedgerSeal :one
+INSERT INTO ledger_seals (
+  day,
+  entry_count,
+  transfer_count,
+  merkle_root,
+  signature
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetLastLedgerSeal :one
+SELECT * FROM ledger_seals
+ORDER BY day DESC
+LIMIT 1;
+
+-- name: ListLedgerSeals :many
+SELECT * FROM ledger_seals
+ORDER BY day;
+
+-- name: GetFirstHashedEntryTime :one
+SELECT created_at FROM entries
+WHERE hash IS NOT NULL
+ORDER BY id
+LIMIT 1;
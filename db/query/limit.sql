@@ -0,0 +1,61 @@
+-- name: UpsertTransferLimit :one
+INSERT INTO transfer_limits (
+  tier,
+  currency,
+  per_transaction,
+  daily,
+  monthly
+) VALUES (
+  $1, $2, $3, $4, $5
+) ON CONFLICT (tier, currency) DO UPDATE SET
+  per_transaction = EXCLUDED.per_transaction,
+  daily = EXCLUDED.daily,
+  monthly = EXCLUDED.monthly
+RETURNING *;
+
+-- name: GetTransferLimit :one
+SELECT * FROM transfer_limits
+WHERE tier = $1
+AND currency = $2
+LIMIT 1;
+
+-- name: ListTransferLimits :many
+SELECT * FROM transfer_limits
+ORDER BY tier, currency;
+
+-- name: UpsertTransferLimitOverride :one
+INSERT INTO transfer_limit_overrides (
+  username,
+  currency,
+  per_transaction,
+  daily,
+  monthly
+) VALUES (
+  $1, $2, $3, $4, $5
+) ON CONFLICT (username, currency) DO UPDATE SET
+  per_transaction = EXCLUDED.per_transaction,
+  daily = EXCLUDED.daily,
+  monthly = EXCLUDED.monthly
+RETURNING *;
+
+-- name: GetTransferLimitOverride :one
+SELECT * FROM transfer_limit_overrides
+WHERE username = $1
+AND currency = $2
+LIMIT 1;
+
+-- name: DeleteTransferLimitOverride :exec
+DELETE FROM transfer_limit_overrides
+WHERE username = $1
+AND currency = $2;
+
+-- name: GetOutgoingTransferTotal :one
+SELECT COALESCE(SUM(transfers.from_amount), 0)::bigint AS total
+FROM transfers
+JOIN accounts ON accounts.id = transfers.from_account_id
+WHERE transfers.initiated_by = sqlc.arg(username)
+AND accounts.currency = sqlc.arg(currency)
+AND transfers.created_at >= sqlc.arg(since);
+
+-- name: LockTransferLimit :exec
+SELECT pg_advisory_xact_lock(hashtext('transfer_limit:' || sqlc.arg(username)::text));
@@ -0,0 +1,62 @@
+-- name: CreateOrganization :one
+INSERT INTO organizations (
+  name,
+  approval_threshold,
+  required_approvals,
+  created_by
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetOrganization :one
+SELECT * FROM organizations
+WHERE id = $1 LIMIT 1;
+
+-- name: ListOrganizations :many
+SELECT * FROM organizations
+WHERE id IN (
+  SELECT organization_id FROM organization_members
+  WHERE username = $1
+)
+ORDER BY id;
+
+-- name: CreateOrganizationMember :one
+INSERT INTO organization_members (
+  organization_id,
+  username,
+  role
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetOrganizationMember :one
+SELECT * FROM organization_members
+WHERE organization_id = $1
+AND username = $2
+LIMIT 1;
+
+-- name: ListOrganizationMembers :many
+SELECT * FROM organization_members
+WHERE organization_id = $1
+ORDER BY created_at;
+
+-- name: DeleteOrganizationMember :exec
+DELETE FROM organization_members
+WHERE organization_id = $1
+AND username = $2;
+
+-- name: CreateOrganizationAccount :one
+INSERT INTO accounts (
+  owner,
+  balance,
+  available_balance,
+  currency,
+  organization_id
+) VALUES (
+  $1, 0, 0, $2, $3
+) RETURNING *;
+
+-- name: ListOrganizationAccounts :many
+SELECT * FROM accounts
+WHERE organization_id = $1
+ORDER BY id;
@@ -0,0 +1,64 @@
+-- name: CreateOrganizationTransfer :one
+INSERT INTO organization_transfers (
+  organization_id,
+  from_account_id,
+  to_account_id,
+  amount,
+  memo,
+  initiated_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetOrganizationTransfer :one
+SELECT * FROM organization_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetOrganizationTransferForUpdate :one
+SELECT * FROM organization_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListOrganizationTransfers :many
+SELECT * FROM organization_transfers
+WHERE organization_id = sqlc.arg(organization_id)
+AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status))
+AND (
+  sqlc.arg(after_id)::bigint = 0 OR
+  (sqlc.arg(descending)::bool AND id < sqlc.arg(after_id)) OR
+  (NOT sqlc.arg(descending)::bool AND id > sqlc.arg(after_id))
+)
+ORDER BY
+  CASE WHEN sqlc.arg(descending)::bool THEN id END DESC,
+  id
+LIMIT sqlc.arg(page_limit);
+
+-- name: CloseOrganizationTransfer :one
+UPDATE organization_transfers
+SET status = $2,
+    transfer_id = $3,
+    updated_at = now()
+WHERE id = $1
+AND status = 'pending_approval'
+RETURNING *;
+
+-- name: CreateOrganizationTransferApproval :one
+INSERT INTO organization_transfer_approvals (
+  organization_transfer_id,
+  username
+) VALUES (
+  $1, $2
+) RETURNING *;
+
+-- name: CountCurrentOrganizationTransferApprovals :one
+SELECT COUNT(*) FROM organization_transfer_approvals a
+JOIN organization_transfers t ON t.id = a.organization_transfer_id
+JOIN organization_members m ON m.organization_id = t.organization_id AND m.username = a.username
+WHERE a.organization_transfer_id = $1
+AND m.role IN ('admin', 'approver')
+AND m.created_at <= a.created_at;
+
+-- name: ListOrganizationTransferApprovals :many
+SELECT * FROM organization_transfer_approvals
+WHERE organization_transfer_id = $1
+ORDER BY created_at;
@@ -0,0 +1,40 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox_events (
+  event_type,
+  aggregate_type,
+  aggregate_id,
+  payload
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListPendingOutboxEvents :many
+SELECT * FROM outbox_events
+WHERE published_at IS NULL
+AND failed_at IS NULL
+AND next_attempt_at <= now()
+AND NOT EXISTS (
+  SELECT 1 FROM outbox_events AS retrying
+  WHERE retrying.aggregate_type = outbox_events.aggregate_type
+  AND retrying.aggregate_id = outbox_events.aggregate_id
+  AND retrying.id < outbox_events.id
+  AND retrying.published_at IS NULL
+  AND retrying.failed_at IS NULL
+  AND retrying.attempts > 0
+)
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox_events SET
+  published_at = now()
+WHERE id = $1;
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE outbox_events SET
+  attempts = attempts + 1,
+  last_error = $2,
+  next_attempt_at = $3,
+  failed_at = $4
+WHERE id = $1;
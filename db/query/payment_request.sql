@@ -0,0 +1,65 @@
+-- name: CreatePaymentRequest :one
+INSERT INTO payment_requests (
+  requester,
+  payer,
+  to_account_id,
+  amount,
+  currency,
+  memo,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetPaymentRequest :one
+SELECT * FROM payment_requests
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPaymentRequestForUpdate :one
+SELECT * FROM payment_requests
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListIncomingPaymentRequests :many
+SELECT * FROM payment_requests
+WHERE payer = sqlc.arg(payer)
+AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status))
+AND (
+  sqlc.arg(after_id)::bigint = 0 OR
+  (sqlc.arg(descending)::bool AND id < sqlc.arg(after_id)) OR
+  (NOT sqlc.arg(descending)::bool AND id > sqlc.arg(after_id))
+)
+ORDER BY
+  CASE WHEN sqlc.arg(descending)::bool THEN id END DESC,
+  id
+LIMIT sqlc.arg(page_limit);
+
+-- name: ListOutgoingPaymentRequests :many
+SELECT * FROM payment_requests
+WHERE requester = sqlc.arg(requester)
+AND (sqlc.arg(status)::text = '' OR status = sqlc.arg(status))
+AND (
+  sqlc.arg(after_id)::bigint = 0 OR
+  (sqlc.arg(descending)::bool AND id < sqlc.arg(after_id)) OR
+  (NOT sqlc.arg(descending)::bool AND id > sqlc.arg(after_id))
+)
+ORDER BY
+  CASE WHEN sqlc.arg(descending)::bool THEN id END DESC,
+  id
+LIMIT sqlc.arg(page_limit);
+
+-- name: ClosePaymentRequest :one
+UPDATE payment_requests
+SET status = $2,
+    transfer_id = $3,
+    updated_at = now()
+WHERE id = $1
+AND status = 'pending'
+RETURNING *;
+
+-- name: ExpirePaymentRequests :execrows
+UPDATE payment_requests
+SET status = 'expired',
+    updated_at = now()
+WHERE status = 'pending'
+AND expires_at <= now();
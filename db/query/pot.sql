@@ -0,0 +1,70 @@
+-- name: CreatePot :one
+INSERT INTO pots (
+  account_id,
+  name,
+  target_amount,
+  target_date,
+  round_up
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetPot :one
+SELECT * FROM pots
+WHERE id = $1
+AND closed_at IS NULL
+LIMIT 1;
+
+-- name: ListPots :many
+SELECT * FROM pots
+WHERE account_id = $1
+AND closed_at IS NULL
+ORDER BY id;
+
+-- name: ListPotTotals :many
+SELECT p.account_id, SUM(p.balance)::bigint AS balance
+FROM pots p
+JOIN accounts a ON a.id = p.account_id
+WHERE (
+    a.owner = sqlc.arg(username)
+    OR a.id IN (
+      SELECT account_id FROM account_members
+      WHERE username = sqlc.arg(username)
+      AND accepted_at IS NOT NULL
+    )
+)
+AND p.closed_at IS NULL
+GROUP BY p.account_id
+ORDER BY p.account_id;
+
+-- name: GetRoundUpPot :one
+SELECT * FROM pots
+WHERE account_id = $1
+AND round_up
+AND closed_at IS NULL
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: UpdatePot :one
+UPDATE pots
+SET name = $2,
+    target_amount = $3,
+    target_date = $4,
+    round_up = $5
+WHERE id = $1
+RETURNING *;
+
+-- name: AddPotBalance :one
+UPDATE pots
+SET balance = balance + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+AND closed_at IS NULL
+RETURNING *;
+
+-- name: ClosePot :one
+UPDATE pots
+SET closed_at = now()
+WHERE id = $1
+AND balance = 0
+AND closed_at IS NULL
+RETURNING *;
\ No newline at end of file
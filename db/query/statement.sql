@@ -0,0 +1,41 @@
+-- line_type is util.StatementLinePot, util.StatementLineFee, the transfer kind or the journal kind
+-- name: ListStatementEntries :many
+SELECT e.id,
+       e.amount,
+       e.created_at,
+       e.transfer_id,
+       e.journal_id,
+       e.pot_id,
+       (CASE
+         WHEN e.pot_id IS NOT NULL THEN 'pot'
+         WHEN t.id IS NULL THEN COALESCE(j.kind, 'entry')
+         WHEN t.fee > 0 AND e.account_id = t.from_account_id AND EXISTS (
+           SELECT 1 FROM entries p
+           WHERE p.transfer_id = e.transfer_id
+             AND p.account_id = e.account_id
+             AND p.id < e.id
+         ) THEN 'fee'
+         ELSE t.kind
+       END)::varchar AS line_type,
+       COALESCE(CASE WHEN t.from_account_id = e.account_id THEN t.to_account_id ELSE t.from_account_id END, 0)::bigint AS counterparty_account_id,
+       COALESCE(t.memo, '')::varchar AS memo,
+       COALESCE(t.reference, '')::varchar AS reference,
+       COALESCE(CASE WHEN t.from_account_id = e.account_id THEN t.sender_description ELSE t.recipient_description END, '')::varchar AS description,
+       COALESCE(CASE WHEN t.from_account_id = e.account_id THEN t.sender_category ELSE t.recipient_category END, '')::varchar AS category
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN journals j ON j.id = e.journal_id
+WHERE e.account_id = sqlc.arg(account_id)
+  AND e.created_at >= sqlc.arg(since)
+  AND e.created_at < sqlc.arg(until)
+  AND e.id > sqlc.arg(after_id)
+ORDER BY e.id
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetStatementTotals :one
+SELECT COALESCE(sum(amount) FILTER (WHERE amount > 0), 0)::bigint AS total_credits,
+       COALESCE(-sum(amount) FILTER (WHERE amount < 0), 0)::bigint AS total_debits
+FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(since)
+  AND created_at < sqlc.arg(until);
@@ -2,30 +2,118 @@
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  fee,
+  kind,
+  original_transfer_id,
+  memo,
+  reference,
+  sender_description,
+  recipient_description,
+  sender_category,
+  from_amount,
+  initiated_by
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 ) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
--- name: ListTransfers :many
+-- name: GetTransferForUpdate :one
 SELECT * FROM transfers
-WHERE 
-    from_account_id = $1 OR
-    to_account_id = $2
-ORDER BY id
-LIMIT $3
-OFFSET $4;
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListAccountTransfers :many
+SELECT * FROM transfers
+WHERE
+    (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id))
+    AND (
+        sqlc.arg(direction)::text = '' OR
+        (sqlc.arg(direction) = 'debit' AND from_account_id = sqlc.arg(account_id)) OR
+        (sqlc.arg(direction) = 'credit' AND to_account_id = sqlc.arg(account_id))
+    )
+    AND (
+        sqlc.arg(counterparty_account_id)::bigint = 0 OR
+        (from_account_id = sqlc.arg(account_id) AND to_account_id = sqlc.arg(counterparty_account_id)) OR
+        (to_account_id = sqlc.arg(account_id) AND from_account_id = sqlc.arg(counterparty_account_id))
+    )
+    AND (
+        sqlc.arg(min_amount)::bigint = 0 OR
+        CASE WHEN from_account_id = sqlc.arg(account_id) THEN from_amount ELSE amount END >= sqlc.arg(min_amount)
+    )
+    AND (
+        sqlc.arg(max_amount)::bigint = 0 OR
+        CASE WHEN from_account_id = sqlc.arg(account_id) THEN from_amount ELSE amount END <= sqlc.arg(max_amount)
+    )
+    AND (
+        sqlc.arg(query)::text = '' OR
+        memo ILIKE '%' || sqlc.arg(query) || '%' OR
+        reference ILIKE '%' || sqlc.arg(query) || '%' OR
+        (from_account_id = sqlc.arg(account_id) AND sender_description ILIKE '%' || sqlc.arg(query) || '%') OR
+        (to_account_id = sqlc.arg(account_id) AND recipient_description ILIKE '%' || sqlc.arg(query) || '%')
+    )
+    AND (
+        sqlc.arg(category)::text = '' OR
+        (from_account_id = sqlc.arg(account_id) AND sender_category = sqlc.arg(category)) OR
+        (to_account_id = sqlc.arg(account_id) AND recipient_category = sqlc.arg(category))
+    )
+    AND (sqlc.narg(start_date)::timestamptz IS NULL OR created_at >= sqlc.narg(start_date))
+    AND (sqlc.narg(end_date)::timestamptz IS NULL OR created_at < sqlc.narg(end_date))
+    AND (
+        sqlc.arg(after_id)::bigint = 0 OR
+        (sqlc.arg(descending)::bool AND id < sqlc.arg(after_id)) OR
+        (NOT sqlc.arg(descending)::bool AND id > sqlc.arg(after_id))
+    )
+ORDER BY
+    CASE WHEN sqlc.arg(descending)::bool THEN id END DESC,
+    id
+LIMIT sqlc.arg(page_limit);
+
+-- name: UpdateTransferSenderDetails :one
+UPDATE transfers
+SET sender_category = sqlc.arg(category),
+    sender_description = sqlc.arg(description)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateTransferRecipientDetails :one
+UPDATE transfers
+SET recipient_category = sqlc.arg(category),
+    recipient_description = sqlc.arg(description)
+WHERE id = sqlc.arg(id)
+RETURNING *;
 
--- name: ListTransfersByDate :many
+-- name: UpdateTransferReversal :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + sqlc.arg(amount),
+    status = sqlc.arg(status)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListTransferReversals :many
 SELECT * FROM transfers
-WHERE 
-    (from_account_id = $1 OR to_account_id = $2)
-    AND created_at >= $3
-    AND created_at <= $4
+WHERE original_transfer_id = $1
+ORDER BY id;
+
+-- name: SetTransferHash :one
+UPDATE transfers
+SET hash = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: ListHashedTransfersAfter :many
+SELECT * FROM transfers
+WHERE id > $1
+AND hash IS NOT NULL
 ORDER BY id
-LIMIT $5
-OFFSET $6;
+LIMIT $2;
+
+-- name: ListTransferHashes :many
+SELECT hash FROM transfers
+WHERE created_at >= sqlc.arg(since)
+AND created_at < sqlc.arg(until)
+AND hash IS NOT NULL
+ORDER BY id;
\ No newline at end of file
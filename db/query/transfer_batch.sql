@@ -0,0 +1,69 @@
+-- name: CreateTransferBatch :one
+INSERT INTO transfer_batches (
+  owner,
+  from_account_id,
+  mode,
+  item_count,
+  total_amount
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: CreateTransferBatchItem :one
+INSERT INTO transfer_batch_items (
+  batch_id,
+  line_number,
+  to_account_id,
+  amount,
+  currency,
+  reference
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetTransferBatch :one
+SELECT * FROM transfer_batches
+WHERE id = $1 LIMIT 1;
+
+-- name: GetTransferBatchForUpdate :one
+SELECT * FROM transfer_batches
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListTransferBatchItems :many
+SELECT * FROM transfer_batch_items
+WHERE batch_id = $1
+ORDER BY line_number;
+
+-- name: ClaimTransferBatch :one
+UPDATE transfer_batches
+SET status = 'processing',
+    claimed_at = now()
+WHERE id = (
+  SELECT id FROM transfer_batches
+  WHERE status = 'pending'
+  OR (status = 'processing' AND claimed_at < sqlc.arg(stale_before)::timestamptz)
+  ORDER BY id
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: UpdateTransferBatchItem :one
+UPDATE transfer_batch_items
+SET status = $2,
+    error = $3,
+    transfer_id = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: FinishTransferBatch :one
+UPDATE transfer_batches
+SET status = $2,
+    succeeded_count = $3,
+    failed_count = $4,
+    error = $5,
+    completed_at = now()
+WHERE id = $1
+AND claimed_at = $6
+RETURNING *;
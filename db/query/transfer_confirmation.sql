@@ -0,0 +1,30 @@
+-- name: CreateTransferConfirmation :one
+INSERT INTO transfer_confirmations (
+  username,
+  from_account_id,
+  to_account_id,
+  amount,
+  hashed_code,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetTransferConfirmation :one
+SELECT * FROM transfer_confirmations
+WHERE username = $1
+AND from_account_id = $2
+AND to_account_id = $3
+AND amount = $4
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: AddTransferConfirmationFailedAttempt :one
+UPDATE transfer_confirmations
+SET failed_attempts = failed_attempts + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteTransferConfirmation :exec
+DELETE FROM transfer_confirmations
+WHERE id = $1;
@@ -9,11 +9,40 @@ import (
 	"context"
 )
 
+const addAccountAvailableBalance = `-- name: AddAccountAvailableBalance :one
+UPDATE accounts
+SET available_balance = available_balance + $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, account_type, available_balance, organization_id
+`
+
+type AddAccountAvailableBalanceParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountAvailableBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountType,
+		&i.AvailableBalance,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
-SET balance = balance + $1
+SET balance = balance + $1,
+    available_balance = available_balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, account_type, available_balance, organization_id
 `
 
 type AddAccountBalanceParams struct {
@@ -30,6 +59,9 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountType,
+		&i.AvailableBalance,
+		&i.OrganizationID,
 	)
 	return i, err
 }
@@ -38,10 +70,11 @@ const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
   owner,
   balance,
+  available_balance,
   currency
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, owner, balance, currency, created_at
+  $1, $2, $2, $3
+) RETURNING id, owner, balance, currency, created_at, account_type, available_balance, organization_id
 `
 
 type CreateAccountParams struct {
@@ -59,6 +92,9 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountType,
+		&i.AvailableBalance,
+		&i.OrganizationID,
 	)
 	return i, err
 }
@@ -74,7 +110,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, account_type, available_balance, organization_id FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -87,12 +123,15 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountType,
+		&i.AvailableBalance,
+		&i.OrganizationID,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, account_type, available_balance, organization_id FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -106,26 +145,117 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountType,
+		&i.AvailableBalance,
+		&i.OrganizationID,
+	)
+	return i, err
+}
+
+const getInternalAccount = `-- name: GetInternalAccount :one
+SELECT id, owner, balance, currency, created_at, account_type, available_balance, organization_id FROM accounts
+WHERE account_type = $1
+AND currency = $2
+LIMIT 1
+`
+
+type GetInternalAccountParams struct {
+	AccountType string `json:"account_type"`
+	Currency    string `json:"currency"`
+}
+
+func (q *Queries) GetInternalAccount(ctx context.Context, arg GetInternalAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getInternalAccount, arg.AccountType, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountType,
+		&i.AvailableBalance,
+		&i.OrganizationID,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
-WHERE owner = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3
+SELECT id, owner, balance, currency, created_at, account_type, available_balance, organization_id FROM accounts
+WHERE (
+    owner = $1
+    OR id IN (
+      SELECT account_id FROM account_members
+      WHERE username = $1
+      AND accepted_at IS NOT NULL
+    )
+)
+AND ($2::text = '' OR currency = $2)
+AND (
+  $3::bigint = 0 OR
+  ($4::bool AND id < $3) OR
+  (NOT $4::bool AND id > $3)
+)
+ORDER BY
+  CASE WHEN $4::bool THEN id END DESC,
+  id
+LIMIT $5
 `
 
 type ListAccountsParams struct {
-	Owner  string `json:"owner"`
-	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
+	Username   string `json:"username"`
+	Currency   string `json:"currency"`
+	AfterID    int64  `json:"after_id"`
+	Descending bool   `json:"descending"`
+	PageLimit  int32  `json:"page_limit"`
 }
 
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listAccounts,
+		arg.Username,
+		arg.Currency,
+		arg.AfterID,
+		arg.Descending,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.AccountType,
+			&i.AvailableBalance,
+			&i.OrganizationID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInternalAccounts = `-- name: ListInternalAccounts :many
+SELECT id, owner, balance, currency, created_at, account_type, available_balance, organization_id FROM accounts
+WHERE account_type <> 'personal'
+ORDER BY account_type, currency
+`
+
+func (q *Queries) ListInternalAccounts(ctx context.Context) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listInternalAccounts)
 	if err != nil {
 		return nil, err
 	}
@@ -139,6 +269,9 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.AccountType,
+			&i.AvailableBalance,
+			&i.OrganizationID,
 		); err != nil {
 			return nil, err
 		}
@@ -155,9 +288,10 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 
 const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
-SET balance = $2
+SET balance = $2,
+    available_balance = available_balance + $2 - balance
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, account_type, available_balance, organization_id
 `
 
 type UpdateAccountParams struct {
@@ -174,6 +308,9 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountType,
+		&i.AvailableBalance,
+		&i.OrganizationID,
 	)
 	return i, err
 }
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// AccountMembership returns username's membership of account. The owner is always a member,
+// members of a business account's organization are viewers and anyone without an accepted
+// invitation comes back with an empty role. Every check of who can use an account on its
+// owner's behalf, in the api or in the workers, starts here.
+func AccountMembership(ctx context.Context, q Querier, account Account, username string) (AccountMember, error) {
+	if account.Owner == username {
+		return AccountMember{
+			AccountID: account.ID,
+			Username:  username,
+			Role:      util.AccountRoleOwner,
+		}, nil
+	}
+
+	// members of the organization a business account belongs to can see it
+	if account.OrganizationID.Valid {
+		_, err := q.GetOrganizationMember(ctx, GetOrganizationMemberParams{
+			OrganizationID: account.OrganizationID.Int64,
+			Username:       username,
+		})
+		if err == nil {
+			return AccountMember{
+				AccountID: account.ID,
+				Username:  username,
+				Role:      util.AccountRoleViewer,
+			}, nil
+		}
+		if err != sql.ErrNoRows {
+			return AccountMember{}, err
+		}
+	}
+
+	member, err := q.GetAccountMember(ctx, GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  username,
+	})
+	if err == sql.ErrNoRows || (err == nil && !member.AcceptedAt.Valid) {
+		return AccountMember{}, nil
+	}
+
+	return member, err
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountMember(t *testing.T) {
+	account := createRandomAccount(t)
+	user := createRandomUser(t)
+
+	arg := CreateAccountMemberParams{
+		AccountID:    account.ID,
+		Username:     user.Username,
+		Role:         util.AccountRolePayer,
+		PaymentLimit: 100,
+		InvitedBy:    account.Owner,
+	}
+	member, err := testQueries.CreateAccountMember(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Role, member.Role)
+	require.False(t, member.AcceptedAt.Valid)
+
+	// a user can only be invited once per account
+	_, err = testQueries.CreateAccountMember(context.Background(), arg)
+	require.Error(t, err)
+	require.Equal(t, "unique_violation", err.(*pq.Error).Code.Name())
+
+	invitations, err := testQueries.ListAccountInvitations(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, invitations, 1)
+
+	// the account only shows up in the member's list once they accept
+	listArg := ListAccountsParams{Username: user.Username, PageLimit: 10}
+	accounts, err := testQueries.ListAccounts(context.Background(), listArg)
+	require.NoError(t, err)
+	require.Empty(t, accounts)
+
+	accepted, err := testQueries.AcceptAccountMember(context.Background(), AcceptAccountMemberParams{
+		AccountID: account.ID,
+		Username:  user.Username,
+	})
+	require.NoError(t, err)
+	require.True(t, accepted.AcceptedAt.Valid)
+
+	_, err = testQueries.AcceptAccountMember(context.Background(), AcceptAccountMemberParams{
+		AccountID: account.ID,
+		Username:  user.Username,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	accounts, err = testQueries.ListAccounts(context.Background(), listArg)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, account.ID, accounts[0].ID)
+
+	err = testQueries.DeleteAccountMember(context.Background(), DeleteAccountMemberParams{
+		AccountID: account.ID,
+		Username:  user.Username,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetAccountMember(context.Background(), GetAccountMemberParams{
+		AccountID: account.ID,
+		Username:  user.Username,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -106,9 +106,8 @@ func TestListAccounts(t *testing.T) {
 	}
 
 	arg := ListAccountsParams{
-		Owner:  lastAccount.Owner,
-		Limit:  5,
-		Offset: 0,
+		Username:  lastAccount.Owner,
+		PageLimit: 5,
 	}
 
 	accounts, err := testQueries.ListAccounts(context.Background(), arg)
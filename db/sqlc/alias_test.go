@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyAlias(t *testing.T) {
+	user1 := createRandomUser(t)
+	user2 := createRandomUser(t)
+	phone := fmt.Sprintf("+2547%08d", util.RandomInt(0, 99999999))
+
+	arg := CreateAliasParams{
+		Owner:         user1.Username,
+		AliasType:     util.AliasTypePhone,
+		Value:         phone,
+		HashedCode:    util.RandomString(60),
+		CodeExpiresAt: time.Now().Add(time.Minute),
+	}
+	alias1, err := testQueries.CreateAlias(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, alias1.VerifiedAt.Valid)
+
+	// registering again replaces the code and resets the attempts
+	_, err = testQueries.AddAliasFailedAttempt(context.Background(), alias1.ID)
+	require.NoError(t, err)
+	arg.HashedCode = util.RandomString(60)
+	again, err := testQueries.CreateAlias(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, alias1.ID, again.ID)
+	require.Equal(t, arg.HashedCode, again.HashedCode)
+	require.Zero(t, again.FailedAttempts)
+
+	// another user can claim the same number until it is verified
+	arg.Owner = user2.Username
+	alias2, err := testQueries.CreateAlias(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetVerifiedAlias(context.Background(), GetVerifiedAliasParams{AliasType: util.AliasTypePhone, Value: phone})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	alias1, err = testQueries.VerifyAlias(context.Background(), alias1.ID)
+	require.NoError(t, err)
+	require.True(t, alias1.VerifiedAt.Valid)
+
+	verified, err := testQueries.GetVerifiedAlias(context.Background(), GetVerifiedAliasParams{AliasType: util.AliasTypePhone, Value: phone})
+	require.NoError(t, err)
+	require.Equal(t, user1.Username, verified.Owner)
+
+	_, err = testQueries.VerifyAlias(context.Background(), alias2.ID)
+	require.Error(t, err)
+
+	// a verified alias can't be registered again
+	arg.Owner = user1.Username
+	_, err = testQueries.CreateAlias(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestGetDefaultAccount(t *testing.T) {
+	account := createRandomAccount(t)
+
+	// without a default the owner's account in the currency is used
+	defaultAccount, err := testQueries.GetDefaultAccount(context.Background(), GetDefaultAccountParams{
+		Owner:    account.Owner,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, defaultAccount.ID)
+
+	_, err = testQueries.SetDefaultAccount(context.Background(), SetDefaultAccountParams{
+		Owner:     account.Owner,
+		Currency:  account.Currency,
+		AccountID: account.ID,
+	})
+	require.NoError(t, err)
+
+	defaultAccount, err = testQueries.GetDefaultAccount(context.Background(), GetDefaultAccountParams{
+		Owner:    account.Owner,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, defaultAccount.ID)
+
+	_, err = testQueries.GetDefaultAccount(context.Background(), GetDefaultAccountParams{
+		Owner:    account.Owner,
+		Currency: "XYZ",
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
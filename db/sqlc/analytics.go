@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// AccountAnalyticsParams contains the input parameters for the analytics of an account over the
+// period from Since up to but not including Until, compared with the period of the same length
+// right before it. Converted amounts are in Currency.
+type AccountAnalyticsParams struct {
+	AccountID int64     `json:"account_id"`
+	Currency  string    `json:"currency"`
+	Since     time.Time `json:"since"`
+	Until     time.Time `json:"until"`
+	// number of counterparties listed, the ones the most money moved with come first
+	TopCounterparties int32 `json:"top_counterparties"`
+}
+
+// AccountAnalytics breaks down the money that moved in and out of an account by transfer,
+// per category, counterparty and month
+type AccountAnalytics struct {
+	AccountID      int64                          `json:"account_id"`
+	Currency       string                         `json:"currency"`
+	ReportCurrency string                         `json:"report_currency"`
+	Since          time.Time                      `json:"since"`
+	Until          time.Time                      `json:"until"`
+	PreviousSince  time.Time                      `json:"previous_since"`
+	Totals         GetAnalyticsTotalsRow          `json:"totals"`
+	Categories     []ListCategoryAnalyticsRow     `json:"categories"`
+	Counterparties []ListCounterpartyAnalyticsRow `json:"counterparties"`
+	Months         []ListMonthlyAnalyticsRow      `json:"months"`
+}
+
+// AccountAnalytics aggregates the transfers of an account, all from the same snapshot so the
+// breakdowns add up to the totals
+func (store *SQLStore) AccountAnalytics(ctx context.Context, arg AccountAnalyticsParams) (AccountAnalytics, error) {
+	var analytics AccountAnalytics
+
+	tx, err := store.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return analytics, err
+	}
+	defer tx.Rollback()
+	q := New(tx)
+
+	account, err := q.GetAccount(ctx, arg.AccountID)
+	if err != nil {
+		return analytics, err
+	}
+
+	analytics = AccountAnalytics{
+		AccountID:      arg.AccountID,
+		Currency:       account.Currency,
+		ReportCurrency: arg.Currency,
+		Since:          arg.Since,
+		Until:          arg.Until,
+		PreviousSince:  arg.Since.Add(-arg.Until.Sub(arg.Since)),
+	}
+
+	analytics.Totals, err = q.GetAnalyticsTotals(ctx, GetAnalyticsTotalsParams{
+		Currency:      arg.Currency,
+		AccountID:     arg.AccountID,
+		Since:         analytics.Since,
+		PreviousSince: analytics.PreviousSince,
+		Until:         analytics.Until,
+	})
+	if err != nil {
+		return analytics, err
+	}
+
+	analytics.Categories, err = q.ListCategoryAnalytics(ctx, ListCategoryAnalyticsParams{
+		Currency:      arg.Currency,
+		AccountID:     arg.AccountID,
+		Since:         analytics.Since,
+		PreviousSince: analytics.PreviousSince,
+		Until:         analytics.Until,
+	})
+	if err != nil {
+		return analytics, err
+	}
+
+	analytics.Counterparties, err = q.ListCounterpartyAnalytics(ctx, ListCounterpartyAnalyticsParams{
+		Currency:      arg.Currency,
+		AccountID:     arg.AccountID,
+		Since:         analytics.Since,
+		PreviousSince: analytics.PreviousSince,
+		Until:         analytics.Until,
+		PageLimit:     arg.TopCounterparties,
+	})
+	if err != nil {
+		return analytics, err
+	}
+
+	analytics.Months, err = q.ListMonthlyAnalytics(ctx, ListMonthlyAnalyticsParams{
+		Currency:  arg.Currency,
+		AccountID: arg.AccountID,
+		Since:     analytics.Since,
+		Until:     analytics.Until,
+	})
+	if err != nil {
+		return analytics, err
+	}
+
+	return analytics, tx.Commit()
+}
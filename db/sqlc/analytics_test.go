@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountAnalytics(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+	account3 := createRandomAccountInCurrency(t, util.USD)
+	feeAccount, err := testQueries.GetInternalAccount(context.Background(), GetInternalAccountParams{
+		AccountType: util.FeeIncomeAccount,
+		Currency:    util.USD,
+	})
+	require.NoError(t, err)
+
+	// the first transfer falls in the previous period
+	previous, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID:  account1.ID,
+		ToAccountID:    account2.ID,
+		Amount:         30,
+		SenderCategory: util.CategoryRent,
+	})
+	require.NoError(t, err)
+
+	rent, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID:  account1.ID,
+		ToAccountID:    account2.ID,
+		Amount:         50,
+		SenderCategory: util.CategoryRent,
+		Fee:            2,
+		FeeAccountID:   feeAccount.ID,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID:  account1.ID,
+		ToAccountID:    account3.ID,
+		Amount:         15,
+		SenderCategory: util.CategoryGroceries,
+	})
+	require.NoError(t, err)
+
+	salary, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account3.ID,
+		ToAccountID:   account1.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+	_, err = testQueries.UpdateTransferRecipientDetails(context.Background(), UpdateTransferRecipientDetailsParams{
+		Category: util.CategorySalary,
+		ID:       salary.Transfer.ID,
+	})
+	require.NoError(t, err)
+
+	since := rent.FromEntry.CreatedAt
+	until := salary.ToEntry.CreatedAt.Add(time.Microsecond)
+	// the previous period is as long as the current one and ends where it starts
+	require.True(t, previous.FromEntry.CreatedAt.After(since.Add(-until.Sub(since))))
+
+	analytics, err := store.AccountAnalytics(context.Background(), AccountAnalyticsParams{
+		AccountID:         account1.ID,
+		Currency:          util.USD,
+		Since:             since,
+		Until:             until,
+		TopCounterparties: 10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.USD, analytics.Currency)
+
+	require.Equal(t, GetAnalyticsTotalsRow{
+		Incoming:          100,
+		Outgoing:          50 + 2 + 15,
+		PreviousIncoming:  0,
+		PreviousOutgoing:  30,
+		Movements:         4,
+		ConvertedIncoming: 100,
+		ConvertedOutgoing: 50 + 2 + 15,
+	}, analytics.Totals)
+
+	require.Equal(t, []ListCategoryAnalyticsRow{
+		{Category: util.CategoryRent, Direction: "debit", Amount: 50, PreviousAmount: 30, Change: 20, Movements: 1, ConvertedAmount: 50},
+		{Category: util.CategoryGroceries, Direction: "debit", Amount: 15, PreviousAmount: 0, Change: 15, Movements: 1, ConvertedAmount: 15},
+		{Category: util.StatementLineFee, Direction: "debit", Amount: 2, PreviousAmount: 0, Change: 2, Movements: 1, ConvertedAmount: 2},
+		{Category: util.CategorySalary, Direction: "credit", Amount: 100, PreviousAmount: 0, Change: 100, Movements: 1, ConvertedAmount: 100},
+	}, analytics.Categories)
+
+	require.Len(t, analytics.Counterparties, 3)
+	require.Equal(t, account3.ID, analytics.Counterparties[0].CounterpartyAccountID)
+	require.Equal(t, "credit", analytics.Counterparties[0].Direction)
+	require.Equal(t, account2.ID, analytics.Counterparties[1].CounterpartyAccountID)
+	require.Equal(t, int64(52), analytics.Counterparties[1].Amount)
+	require.Equal(t, int64(30), analytics.Counterparties[1].PreviousAmount)
+	require.Equal(t, int64(2), analytics.Counterparties[1].Movements)
+
+	require.NotEmpty(t, analytics.Months)
+	var incoming, outgoing int64
+	for _, month := range analytics.Months {
+		require.Equal(t, 1, month.Month.UTC().Day())
+		incoming += month.Incoming
+		outgoing += month.Outgoing
+	}
+	require.Equal(t, analytics.Totals.Incoming, incoming)
+	require.Equal(t, analytics.Totals.Outgoing, outgoing)
+
+	// converted amounts use the rate in exchange_rates
+	rate, err := testQueries.GetExchangeRate(context.Background(), GetExchangeRateParams{
+		BaseCurrency:   util.USD,
+		TargetCurrency: util.KES,
+	})
+	if err == sql.ErrNoRows {
+		rate, err = testQueries.CreateExchangeRate(context.Background(), CreateExchangeRateParams{
+			BaseCurrency:   util.USD,
+			TargetCurrency: util.KES,
+			ExchangeRate:   "130.50",
+		})
+	}
+	require.NoError(t, err)
+	exchangeRate, err := strconv.ParseFloat(rate.ExchangeRate, 64)
+	require.NoError(t, err)
+
+	converted, err := store.AccountAnalytics(context.Background(), AccountAnalyticsParams{
+		AccountID:         account1.ID,
+		Currency:          util.KES,
+		Since:             since,
+		Until:             until,
+		TopCounterparties: 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.KES, converted.ReportCurrency)
+	require.Equal(t, analytics.Totals.Outgoing, converted.Totals.Outgoing)
+	require.Equal(t, int64(math.Round(67*exchangeRate)), converted.Totals.ConvertedOutgoing)
+	require.Len(t, converted.Counterparties, 1)
+}
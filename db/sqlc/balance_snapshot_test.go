@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountBalanceAt(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+
+	transfer := func() TransferTxResult {
+		result, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
+		return result
+	}
+	balanceAt := func(at time.Time) int64 {
+		balance, err := testQueries.GetAccountBalanceAt(context.Background(), GetAccountBalanceAtParams{
+			AccountID: account1.ID,
+			At:        at,
+		})
+		require.NoError(t, err)
+		return balance
+	}
+
+	first := transfer()
+	createdAt := first.FromEntry.CreatedAt
+	require.Zero(t, balanceAt(createdAt))
+	require.Equal(t, int64(-10), balanceAt(createdAt.Add(time.Microsecond)))
+
+	snapshotted, err := testQueries.CreateBalanceSnapshots(context.Background(), createdAt.Add(time.Microsecond))
+	require.NoError(t, err)
+	require.NotZero(t, snapshotted)
+
+	snapshot, err := testQueries.GetLastBalanceSnapshot(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, first.FromEntry.ID, snapshot.EntryID)
+	require.Equal(t, int64(-10), snapshot.Balance)
+	require.WithinDuration(t, createdAt, snapshot.TakenAt, time.Microsecond)
+
+	// later entries are added to the snapshot
+	second := transfer()
+	require.Equal(t, int64(-10), balanceAt(second.FromEntry.CreatedAt))
+	require.Equal(t, int64(-20), balanceAt(second.FromEntry.CreatedAt.Add(time.Microsecond)))
+}
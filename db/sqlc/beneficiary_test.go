@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBeneficiary(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+
+	arg := CreateBeneficiaryParams{
+		Owner:      account1.Owner,
+		Nickname:   util.RandomOwner(),
+		AccountID:  account2.ID,
+		Currency:   account2.Currency,
+		DailyLimit: 100,
+		TrustedAt:  time.Now().Add(time.Hour),
+	}
+	beneficiary, err := testQueries.CreateBeneficiary(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.AccountID, beneficiary.AccountID)
+	require.WithinDuration(t, arg.TrustedAt, beneficiary.TrustedAt, time.Second)
+
+	// an account can only be saved once per user
+	arg.Nickname = util.RandomOwner()
+	_, err = testQueries.CreateBeneficiary(context.Background(), arg)
+	require.Error(t, err)
+	require.Equal(t, "unique_violation", err.(*pq.Error).Code.Name())
+
+	found, err := testQueries.GetBeneficiaryByAccount(context.Background(), GetBeneficiaryByAccountParams{
+		Owner:     account1.Owner,
+		AccountID: account2.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, beneficiary.ID, found.ID)
+
+	for i := 0; i < 2; i++ {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+			LimitUsername: account1.Owner,
+		})
+		require.NoError(t, err)
+	}
+
+	// pending holds to the beneficiary count until they are captured or released
+	_, err = store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        30,
+		ExpiresAt:     time.Now().Add(time.Hour),
+		LimitUsername: account1.Owner,
+	})
+	require.NoError(t, err)
+
+	total, err := testQueries.GetBeneficiaryTransferTotal(context.Background(), GetBeneficiaryTransferTotalParams{
+		Owner:       account1.Owner,
+		ToAccountID: account2.ID,
+		Since:       time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(50), total)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        51,
+		LimitUsername: account1.Owner,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	_, err = store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        51,
+		ExpiresAt:     time.Now().Add(time.Hour),
+		LimitUsername: account1.Owner,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        50,
+		LimitUsername: account1.Owner,
+	})
+	require.NoError(t, err)
+
+	err = testQueries.DeleteBeneficiary(context.Background(), beneficiary.ID)
+	require.NoError(t, err)
+}
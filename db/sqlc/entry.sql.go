@@ -7,36 +7,58 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  transfer_id,
+  journal_id,
+  pot_id,
+  previous_hash
 ) VALUES (
-  $1, $2
-) RETURNING id, account_id, amount, created_at
+  $1, $2, $3, $4, $5, $6
+) RETURNING id, account_id, amount, created_at, transfer_id, pot_id, journal_id, previous_hash, hash
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID    int64         `json:"account_id"`
+	Amount       int64         `json:"amount"`
+	TransferID   sql.NullInt64 `json:"transfer_id"`
+	JournalID    sql.NullInt64 `json:"journal_id"`
+	PotID        sql.NullInt64 `json:"pot_id"`
+	PreviousHash []byte        `json:"previous_hash"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.TransferID,
+		arg.JournalID,
+		arg.PotID,
+		arg.PreviousHash,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.PotID,
+		&i.JournalID,
+		&i.PreviousHash,
+		&i.Hash,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, pot_id, journal_id, previous_hash, hash FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -48,26 +70,103 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.PotID,
+		&i.JournalID,
+		&i.PreviousHash,
+		&i.Hash,
 	)
 	return i, err
 }
 
+const getLastEntryHash = `-- name: GetLastEntryHash :one
+SELECT hash FROM entries
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLastEntryHash(ctx context.Context, accountID int64) ([]byte, error) {
+	row := q.db.QueryRowContext(ctx, getLastEntryHash, accountID)
+	var hash []byte
+	err := row.Scan(&hash)
+	return hash, err
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, pot_id, journal_id, previous_hash, hash FROM entries
 WHERE account_id = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3
+AND (
+  $2::bigint = 0 OR
+  ($3::bool AND id < $2) OR
+  (NOT $3::bool AND id > $2)
+)
+ORDER BY
+  CASE WHEN $3::bool THEN id END DESC,
+  id
+LIMIT $4
 `
 
 type ListEntriesParams struct {
-	AccountID int64 `json:"account_id"`
-	Limit     int32 `json:"limit"`
-	Offset    int32 `json:"offset"`
+	AccountID  int64 `json:"account_id"`
+	AfterID    int64 `json:"after_id"`
+	Descending bool  `json:"descending"`
+	PageLimit  int32 `json:"page_limit"`
 }
 
 func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
-	rows, err := q.db.QueryContext(ctx, listEntries, arg.AccountID, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listEntries,
+		arg.AccountID,
+		arg.AfterID,
+		arg.Descending,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.PotID,
+			&i.JournalID,
+			&i.PreviousHash,
+			&i.Hash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesByAccountAfter = `-- name: ListEntriesByAccountAfter :many
+SELECT id, account_id, amount, created_at, transfer_id, pot_id, journal_id, previous_hash, hash FROM entries
+WHERE (account_id, id) > ($1::bigint, $2::bigint)
+ORDER BY account_id, id
+LIMIT $3
+`
+
+type ListEntriesByAccountAfterParams struct {
+	AccountID int64 `json:"account_id"`
+	ID        int64 `json:"id"`
+	PageLimit int32 `json:"page_limit"`
+}
+
+func (q *Queries) ListEntriesByAccountAfter(ctx context.Context, arg ListEntriesByAccountAfterParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesByAccountAfter, arg.AccountID, arg.ID, arg.PageLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -80,6 +179,11 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.PotID,
+			&i.JournalID,
+			&i.PreviousHash,
+			&i.Hash,
 		); err != nil {
 			return nil, err
 		}
@@ -93,3 +197,107 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 	}
 	return items, nil
 }
+
+const listEntryHashes = `-- name: ListEntryHashes :many
+SELECT hash FROM entries
+WHERE created_at >= $1
+AND created_at < $2
+AND hash IS NOT NULL
+ORDER BY id
+`
+
+type ListEntryHashesParams struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+}
+
+func (q *Queries) ListEntryHashes(ctx context.Context, arg ListEntryHashesParams) ([][]byte, error) {
+	rows, err := q.db.QueryContext(ctx, listEntryHashes, arg.Since, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := [][]byte{}
+	for rows.Next() {
+		var hash []byte
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		items = append(items, hash)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listJournalEntries = `-- name: ListJournalEntries :many
+SELECT id, account_id, amount, created_at, transfer_id, pot_id, journal_id, previous_hash, hash FROM entries
+WHERE journal_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListJournalEntries(ctx context.Context, journalID sql.NullInt64) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listJournalEntries, journalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.PotID,
+			&i.JournalID,
+			&i.PreviousHash,
+			&i.Hash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setEntryHash = `-- name: SetEntryHash :one
+UPDATE entries
+SET hash = $2
+WHERE id = $1
+RETURNING id, account_id, amount, created_at, transfer_id, pot_id, journal_id, previous_hash, hash
+`
+
+type SetEntryHashParams struct {
+	ID   int64  `json:"id"`
+	Hash []byte `json:"hash"`
+}
+
+func (q *Queries) SetEntryHash(ctx context.Context, arg SetEntryHashParams) (Entry, error) {
+	row := q.db.QueryRowContext(ctx, setEntryHash, arg.ID, arg.Hash)
+	var i Entry
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.TransferID,
+		&i.PotID,
+		&i.JournalID,
+		&i.PreviousHash,
+		&i.Hash,
+	)
+	return i, err
+}
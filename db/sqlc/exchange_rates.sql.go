@@ -16,7 +16,7 @@ INSERT INTO exchange_rates (
   exchange_rate
 ) VALUES (
   $1, $2, $3
-) RETURNING id, base_currency, target_currency, exchange_rate, created_at
+) RETURNING id, base_currency, target_currency, exchange_rate, created_at, updated_at
 `
 
 type CreateExchangeRateParams struct {
@@ -34,6 +34,7 @@ func (q *Queries) CreateExchangeRate(ctx context.Context, arg CreateExchangeRate
 		&i.TargetCurrency,
 		&i.ExchangeRate,
 		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -49,7 +50,7 @@ func (q *Queries) DeleteExchangeRate(ctx context.Context, id int64) error {
 }
 
 const getExchangeRate = `-- name: GetExchangeRate :one
-SELECT id, base_currency, target_currency, exchange_rate, created_at FROM exchange_rates
+SELECT id, base_currency, target_currency, exchange_rate, created_at, updated_at FROM exchange_rates
 WHERE base_currency = $1
 AND target_currency = $2
 LIMIT 1
@@ -69,12 +70,13 @@ func (q *Queries) GetExchangeRate(ctx context.Context, arg GetExchangeRateParams
 		&i.TargetCurrency,
 		&i.ExchangeRate,
 		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
 const listExchangeRates = `-- name: ListExchangeRates :many
-SELECT id, base_currency, target_currency, exchange_rate, created_at FROM exchange_rates
+SELECT id, base_currency, target_currency, exchange_rate, created_at, updated_at FROM exchange_rates
 ORDER BY base_currency ASC
 `
 
@@ -93,6 +95,7 @@ func (q *Queries) ListExchangeRates(ctx context.Context) ([]ExchangeRate, error)
 			&i.TargetCurrency,
 			&i.ExchangeRate,
 			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -109,9 +112,10 @@ func (q *Queries) ListExchangeRates(ctx context.Context) ([]ExchangeRate, error)
 
 const updateExchangeRate = `-- name: UpdateExchangeRate :one
 UPDATE exchange_rates SET
-  exchange_rate = $2
+  exchange_rate = $2,
+  updated_at = now()
 WHERE id = $1
-RETURNING id, base_currency, target_currency, exchange_rate, created_at
+RETURNING id, base_currency, target_currency, exchange_rate, created_at, updated_at
 `
 
 type UpdateExchangeRateParams struct {
@@ -128,6 +132,7 @@ func (q *Queries) UpdateExchangeRate(ctx context.Context, arg UpdateExchangeRate
 		&i.TargetCurrency,
 		&i.ExchangeRate,
 		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// QuoteFeeParams contains the input parameters for quoting a transfer fee
+type QuoteFeeParams struct {
+	AccountType  string `json:"account_type"`
+	TransferType string `json:"transfer_type"`
+	Currency     string `json:"currency"`
+	Amount       int64  `json:"amount"`
+}
+
+// FeeQuote is the fee a transfer will be charged and where the fee goes
+type FeeQuote struct {
+	Fee          int64 `json:"fee"`
+	Waived       bool  `json:"waived"`
+	FeeAccountID int64 `json:"-"`
+}
+
+// QuoteFee evaluates the fee schedules for a transfer before it is made
+func (store *SQLStore) QuoteFee(ctx context.Context, arg QuoteFeeParams) (FeeQuote, error) {
+	var quote FeeQuote
+
+	_, err := store.GetFeeWaiver(ctx, GetFeeWaiverParams{
+		AccountType:  arg.AccountType,
+		TransferType: arg.TransferType,
+	})
+	if err == nil {
+		quote.Waived = true
+		return quote, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return quote, err
+	}
+
+	schedule, err := store.GetApplicableFeeSchedule(ctx, GetApplicableFeeScheduleParams{
+		TransferType: arg.TransferType,
+		Currency:     arg.Currency,
+		Amount:       arg.Amount,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return quote, nil
+		}
+		return quote, err
+	}
+
+	quote.Fee = util.CalculateFee(arg.Amount, schedule.FlatFee, schedule.PercentageBps, schedule.MinFee, schedule.MaxFee)
+	if quote.Fee == 0 {
+		return quote, nil
+	}
+
+	feeAccount, err := store.GetInternalAccount(ctx, GetInternalAccountParams{
+		AccountType: util.FeeIncomeAccount,
+		Currency:    arg.Currency,
+	})
+	if err != nil {
+		return quote, fmt.Errorf("cannot find %s fee income account: %w", arg.Currency, err)
+	}
+	quote.FeeAccountID = feeAccount.ID
+
+	return quote, nil
+}
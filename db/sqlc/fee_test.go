@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomFeeSchedule(t *testing.T, transferType string, minAmount int64) FeeSchedule {
+	arg := CreateFeeScheduleParams{
+		TransferType:  transferType,
+		Currency:      util.USD,
+		MinAmount:     minAmount,
+		FlatFee:       util.RandomInt(0, 100),
+		PercentageBps: util.RandomInt(0, 500),
+	}
+
+	schedule, err := testQueries.CreateFeeSchedule(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, schedule.ID)
+	require.Equal(t, arg.TransferType, schedule.TransferType)
+	require.Equal(t, arg.MinAmount, schedule.MinAmount)
+	require.Equal(t, arg.FlatFee, schedule.FlatFee)
+	require.Equal(t, arg.PercentageBps, schedule.PercentageBps)
+
+	return schedule
+}
+
+func TestGetApplicableFeeSchedule(t *testing.T) {
+	// a random transfer type keeps the tiers of this test apart from any other schedule
+	transferType := util.RandomString(8)
+
+	tier1 := createRandomFeeSchedule(t, transferType, 0)
+	tier2 := createRandomFeeSchedule(t, transferType, 1000)
+
+	schedule, err := testQueries.GetApplicableFeeSchedule(context.Background(), GetApplicableFeeScheduleParams{
+		TransferType: transferType,
+		Currency:     util.USD,
+		Amount:       999,
+	})
+	require.NoError(t, err)
+	require.Equal(t, tier1.ID, schedule.ID)
+
+	schedule, err = testQueries.GetApplicableFeeSchedule(context.Background(), GetApplicableFeeScheduleParams{
+		TransferType: transferType,
+		Currency:     util.USD,
+		Amount:       1000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, tier2.ID, schedule.ID)
+}
+
+func TestQuoteFee(t *testing.T) {
+	store := NewStore(testDB)
+	transferType := util.RandomString(8)
+	schedule := createRandomFeeSchedule(t, transferType, 0)
+
+	amount := int64(5000)
+	quote, err := store.QuoteFee(context.Background(), QuoteFeeParams{
+		AccountType:  util.PersonalAccount,
+		TransferType: transferType,
+		Currency:     util.USD,
+		Amount:       amount,
+	})
+	require.NoError(t, err)
+	require.False(t, quote.Waived)
+	require.Equal(t, util.CalculateFee(amount, schedule.FlatFee, schedule.PercentageBps, 0, 0), quote.Fee)
+	if quote.Fee > 0 {
+		require.NotZero(t, quote.FeeAccountID)
+	}
+
+	_, err = testQueries.CreateFeeWaiver(context.Background(), CreateFeeWaiverParams{
+		AccountType:  util.PersonalAccount,
+		TransferType: transferType,
+	})
+	require.NoError(t, err)
+
+	quote, err = store.QuoteFee(context.Background(), QuoteFeeParams{
+		AccountType:  util.PersonalAccount,
+		TransferType: transferType,
+		Currency:     util.USD,
+		Amount:       amount,
+	})
+	require.NoError(t, err)
+	require.True(t, quote.Waived)
+	require.Zero(t, quote.Fee)
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+)
+
+// EntryHash is the SHA-256 of an entry's content and the hash of the entry before it on the same
+// account, so changing or deleting an entry breaks the hash of every later entry of the account
+func EntryHash(entry Entry) []byte {
+	h := sha256.New()
+	fmt.Fprintf(h, "entry|%d|%d|%d|%d|%d|%d|%d|",
+		entry.ID,
+		entry.AccountID,
+		entry.Amount,
+		entry.TransferID.Int64,
+		entry.JournalID.Int64,
+		entry.PotID.Int64,
+		entry.CreatedAt.UnixMicro(),
+	)
+	h.Write(entry.PreviousHash)
+	return h.Sum(nil)
+}
+
+// TransferHash is the SHA-256 of the fields of a transfer that never change. Reversed amounts,
+// statuses, descriptions and categories are left out as they are updated after the transfer.
+func TransferHash(transfer Transfer) []byte {
+	h := sha256.New()
+	fmt.Fprintf(h, "transfer.v2|%d|%d|%d|%d|%d|%d|%q|%d|%q|%q|%d",
+		transfer.ID,
+		transfer.FromAccountID,
+		transfer.ToAccountID,
+		transfer.Amount,
+		transfer.FromAmount,
+		transfer.Fee,
+		transfer.Kind,
+		transfer.OriginalTransferID.Int64,
+		transfer.Memo,
+		transfer.Reference,
+		transfer.CreatedAt.UnixMicro(),
+	)
+	return h.Sum(nil)
+}
+
+// transferHashV1 is how transfers were hashed before from_amount was, the from_amount of those
+// transfers is only covered by their entries
+func transferHashV1(transfer Transfer) []byte {
+	h := sha256.New()
+	fmt.Fprintf(h, "transfer|%d|%d|%d|%d|%d|%q|%d|%q|%q|%d",
+		transfer.ID,
+		transfer.FromAccountID,
+		transfer.ToAccountID,
+		transfer.Amount,
+		transfer.Fee,
+		transfer.Kind,
+		transfer.OriginalTransferID.Int64,
+		transfer.Memo,
+		transfer.Reference,
+		transfer.CreatedAt.UnixMicro(),
+	)
+	return h.Sum(nil)
+}
+
+// transferHashMatches checks a transfer against its hash in any format it could have been hashed
+// in. The formats are prefixed differently, so a transfer hashed in the current one can't pass as
+// an older one with its from_amount changed.
+func transferHashMatches(transfer Transfer) bool {
+	return bytes.Equal(transfer.Hash, TransferHash(transfer)) ||
+		bytes.Equal(transfer.Hash, transferHashV1(transfer))
+}
+
+// chainEntry creates an entry linked to the last entry of its account. The account has to be locked
+// by the transaction already, which updating its balance does, so no other entry can be chained
+// after the same one.
+func chainEntry(ctx context.Context, q *Queries, arg CreateEntryParams) (Entry, error) {
+	previousHash, err := q.GetLastEntryHash(ctx, arg.AccountID)
+	if err != nil && err != sql.ErrNoRows {
+		return Entry{}, err
+	}
+	arg.PreviousHash = previousHash
+
+	entry, err := q.CreateEntry(ctx, arg)
+	if err != nil {
+		return entry, err
+	}
+
+	return q.SetEntryHash(ctx, SetEntryHashParams{
+		ID:   entry.ID,
+		Hash: EntryHash(entry),
+	})
+}
@@ -0,0 +1,233 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+var (
+	ErrInsufficientAvailableBalance = errors.New("insufficient available balance")
+	ErrHoldNotPending               = errors.New("hold is no longer pending")
+	ErrHoldExpired                  = errors.New("hold has expired")
+	ErrCaptureAmountTooLarge        = errors.New("amount exceeds the held amount")
+)
+
+// CreateHoldTxParams contains the input parameters for placing a hold
+type CreateHoldTxParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	Fee           int64     `json:"fee"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	// the user whose transfer limits the hold counts against until it is captured or released
+	LimitUsername string `json:"limit_username"`
+}
+
+// CreateHoldTxResult contains the result of placing a hold
+type CreateHoldTxResult struct {
+	Hold        Hold    `json:"hold"`
+	FromAccount Account `json:"from_account"`
+}
+
+// CreateHoldTx reserves amount and fee on the sender's available balance without touching its ledger balance
+func (store *SQLStore) CreateHoldTx(ctx context.Context, arg CreateHoldTxParams) (CreateHoldTxResult, error) {
+	var result CreateHoldTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		if arg.LimitUsername != "" {
+			err = checkTransferLimit(ctx, q, arg.LimitUsername, arg.FromAccountID, arg.Amount)
+			if err != nil {
+				return err
+			}
+
+			err = checkBeneficiaryLimit(ctx, q, arg.LimitUsername, arg.ToAccountID, arg.Amount)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.FromAccount, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+			ID:     arg.FromAccountID,
+			Amount: -(arg.Amount + arg.Fee),
+		})
+		if err != nil {
+			return err
+		}
+		if result.FromAccount.AvailableBalance < 0 {
+			return ErrInsufficientAvailableBalance
+		}
+
+		result.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			Fee:           arg.Fee,
+			ExpiresAt:     arg.ExpiresAt,
+			InitiatedBy:   arg.LimitUsername,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// CaptureHoldTxParams contains the input parameters for capturing a hold.
+// An Amount of 0 captures the whole hold.
+type CaptureHoldTxParams struct {
+	HoldID int64 `json:"hold_id"`
+	Amount int64 `json:"amount"`
+}
+
+// CaptureHoldTxResult contains the captured hold and the transfer it turned into
+type CaptureHoldTxResult struct {
+	Hold     Hold             `json:"hold"`
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// CaptureHoldTx turns a pending hold into a transfer. A partial capture
+// releases whatever was held beyond the captured amount.
+func (store *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error) {
+	var result CaptureHoldTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, arg.HoldID)
+		if err != nil {
+			return err
+		}
+
+		if hold.Status != util.HoldStatusPending {
+			return ErrHoldNotPending
+		}
+		if !hold.ExpiresAt.After(time.Now()) {
+			return ErrHoldExpired
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount > hold.Amount {
+			return ErrCaptureAmountTooLarge
+		}
+
+		transferArg := TransferTxParams{
+			FromAccountID: hold.FromAccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        amount,
+			Fee:           hold.Fee,
+			LimitUsername: hold.InitiatedBy,
+			// counted against the limits when the hold was placed, and released below
+			Held: hold.Amount + hold.Fee,
+		}
+		if hold.Fee > 0 {
+			fromAccount, err := q.GetAccount(ctx, hold.FromAccountID)
+			if err != nil {
+				return err
+			}
+			feeAccount, err := q.GetInternalAccount(ctx, GetInternalAccountParams{
+				AccountType: util.FeeIncomeAccount,
+				Currency:    fromAccount.Currency,
+			})
+			if err != nil {
+				return err
+			}
+			transferArg.FeeAccountID = feeAccount.ID
+		}
+
+		result.Transfer, err = transfer(ctx, q, transferArg, util.TransferKindTransfer, sql.NullInt64{})
+		if err != nil {
+			return err
+		}
+
+		// the sender is already locked by the transfer, so releasing the hold can't deadlock
+		result.Transfer.FromAccount, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+			ID:     hold.FromAccountID,
+			Amount: hold.Amount + hold.Fee,
+		})
+		if err != nil {
+			return err
+		}
+
+		status := util.HoldStatusCaptured
+		if amount < hold.Amount {
+			status = util.HoldStatusPartiallyCaptured
+		}
+
+		result.Hold, err = q.UpdateHold(ctx, UpdateHoldParams{
+			ID:             hold.ID,
+			Status:         status,
+			CapturedAmount: amount,
+			TransferID:     sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// VoidHoldTx cancels a pending hold and gives the held money back to the sender's available balance
+func (store *SQLStore) VoidHoldTx(ctx context.Context, holdID int64) (Hold, error) {
+	var hold Hold
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		hold, err = releaseHold(ctx, q, holdID, util.HoldStatusVoided)
+		return err
+	})
+
+	return hold, err
+}
+
+// ExpireHolds releases up to limit pending holds that are past their expiry, one transaction each,
+// and returns how many were expired
+func (store *SQLStore) ExpireHolds(ctx context.Context, limit int32) (int, error) {
+	holds, err := store.ListExpiredHolds(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, hold := range holds {
+		err := store.execTx(ctx, func(q *Queries) error {
+			_, err := releaseHold(ctx, q, hold.ID, util.HoldStatusExpired)
+			return err
+		})
+		// the hold was captured or voided since it was listed
+		if errors.Is(err, ErrHoldNotPending) {
+			continue
+		}
+		if err != nil {
+			return expired, err
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+func releaseHold(ctx context.Context, q *Queries, holdID int64, status string) (Hold, error) {
+	hold, err := q.GetHoldForUpdate(ctx, holdID)
+	if err != nil {
+		return hold, err
+	}
+
+	if hold.Status != util.HoldStatusPending {
+		return hold, ErrHoldNotPending
+	}
+
+	_, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+		ID:     hold.FromAccountID,
+		Amount: hold.Amount + hold.Fee,
+	})
+	if err != nil {
+		return hold, err
+	}
+
+	return q.UpdateHold(ctx, UpdateHoldParams{
+		ID:     hold.ID,
+		Status: status,
+	})
+}
@@ -0,0 +1,204 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+
+	created, err := store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.HoldStatusPending, created.Hold.Status)
+	require.Equal(t, account1.Balance, created.FromAccount.Balance)
+	require.Equal(t, account1.AvailableBalance-10, created.FromAccount.AvailableBalance)
+
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		HoldID: created.Hold.ID,
+		Amount: 4,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.HoldStatusPartiallyCaptured, result.Hold.Status)
+	require.Equal(t, int64(4), result.Hold.CapturedAmount)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Hold.TransferID.Int64)
+
+	// the uncaptured part of the hold is released
+	require.Equal(t, account1.Balance-4, result.Transfer.FromAccount.Balance)
+	require.Equal(t, account1.AvailableBalance-4, result.Transfer.FromAccount.AvailableBalance)
+	require.Equal(t, account2.Balance+4, result.Transfer.ToAccount.Balance)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: created.Hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotPending)
+}
+
+func TestCreateHoldTxInsufficientAvailableBalance(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+
+	_, err := store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.AvailableBalance + 1,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientAvailableBalance)
+
+	account, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.AvailableBalance, account.AvailableBalance)
+}
+
+func TestVoidAndExpireHolds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.EUR)
+	account2 := createRandomAccountInCurrency(t, util.EUR)
+
+	voidable, err := store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	hold, err := store.VoidHoldTx(context.Background(), voidable.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.HoldStatusVoided, hold.Status)
+
+	expiring, err := store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+		ExpiresAt:     time.Now().Add(-time.Second),
+	})
+	require.NoError(t, err)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: expiring.Hold.ID})
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	for {
+		expired, err := store.ExpireHolds(context.Background(), 100)
+		require.NoError(t, err)
+		if expired < 100 {
+			break
+		}
+	}
+
+	hold, err = testQueries.GetHold(context.Background(), expiring.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.HoldStatusExpired, hold.Status)
+
+	account, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account.Balance)
+	require.Equal(t, account1.AvailableBalance, account.AvailableBalance)
+}
+
+func TestTransferTxCannotSpendHeldMoney(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	account1, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  100,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+
+	created, err := store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        95,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        6,
+	}
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInsufficientAvailableBalance)
+
+	arg.Amount = 5
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// the hold itself can still be captured in full
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: created.Hold.ID})
+	require.NoError(t, err)
+	require.Zero(t, result.Transfer.FromAccount.Balance)
+	require.Zero(t, result.Transfer.FromAccount.AvailableBalance)
+}
+
+func TestPendingHoldsCountAgainstLimits(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+
+	_, err := testQueries.UpsertTransferLimitOverride(context.Background(), UpsertTransferLimitOverrideParams{
+		Username: account1.Owner,
+		Currency: util.USD,
+		Daily:    10,
+	})
+	require.NoError(t, err)
+
+	arg := CreateHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        6,
+		ExpiresAt:     time.Now().Add(time.Hour),
+		LimitUsername: account1.Owner,
+	}
+	created, err := store.CreateHoldTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	allowance, err := store.GetTransferAllowance(context.Background(), GetTransferAllowanceParams{
+		Username: account1.Owner,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(6), allowance.DailyUsed)
+
+	_, err = store.CreateHoldTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	// once released the hold no longer counts
+	_, err = store.VoidHoldTx(context.Background(), created.Hold.ID)
+	require.NoError(t, err)
+
+	created, err = store.CreateHoldTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, account1.Owner, created.Hold.InitiatedBy)
+
+	// a captured hold keeps counting, as the transfer it turned into
+	captured, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: created.Hold.ID})
+	require.NoError(t, err)
+	require.Equal(t, account1.Owner, captured.Transfer.Transfer.InitiatedBy)
+
+	allowance, err = store.GetTransferAllowance(context.Background(), GetTransferAllowanceParams{
+		Username: account1.Owner,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(6), allowance.DailyUsed)
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+var ErrUnbalancedJournal = errors.New("journal entries have to sum to zero in every currency")
+
+// JournalLine is one leg of a journal, a positive Amount credits the account
+type JournalLine struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+}
+
+// PostJournalTxParams contains the input parameters for posting a journal by hand
+type PostJournalTxParams struct {
+	Kind      string        `json:"kind"`
+	Memo      string        `json:"memo"`
+	CreatedBy string        `json:"created_by"`
+	Lines     []JournalLine `json:"lines"`
+}
+
+// JournalResult is a journal with the entries it posted
+type JournalResult struct {
+	Journal Journal `json:"journal"`
+	Entries []Entry `json:"entries"`
+}
+
+// PostJournalTx posts a balanced set of lines, e.g. interest paid out of the interest expense
+// account or an adjustment through suspense. Accounts are updated in id order so concurrent
+// journals can't deadlock on each other.
+func (store *SQLStore) PostJournalTx(ctx context.Context, arg PostJournalTxParams) (JournalResult, error) {
+	var result JournalResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Journal, err = q.CreateJournal(ctx, CreateJournalParams{
+			Kind:      arg.Kind,
+			Memo:      arg.Memo,
+			CreatedBy: arg.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		lines := make([]JournalLine, len(arg.Lines))
+		copy(lines, arg.Lines)
+		sort.SliceStable(lines, func(i, j int) bool {
+			return lines[i].AccountID < lines[j].AccountID
+		})
+
+		totals := map[string]int64{}
+		for _, line := range lines {
+			account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+				ID:     line.AccountID,
+				Amount: line.Amount,
+			})
+			if err != nil {
+				return err
+			}
+			totals[account.Currency] += line.Amount
+
+			entry, err := chainEntry(ctx, q, CreateEntryParams{
+				AccountID: line.AccountID,
+				Amount:    line.Amount,
+				JournalID: journalID(result.Journal),
+			})
+			if err != nil {
+				return err
+			}
+			result.Entries = append(result.Entries, entry)
+		}
+
+		// the database checks this again on commit, failing early gives a clearer error
+		for _, total := range totals {
+			if total != 0 {
+				return ErrUnbalancedJournal
+			}
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+func journalID(journal Journal) sql.NullInt64 {
+	return sql.NullInt64{Int64: journal.ID, Valid: true}
+}
+
+// postFX balances a multi currency transfer's journal through the bank's FX position accounts:
+// what the sender paid goes into the position in their currency and what the recipient got
+// comes out of the position in theirs. The positions are locked in id order after every
+// other account of the transfer.
+func postFX(
+	ctx context.Context,
+	q *Queries,
+	journal Journal,
+	fromCurrency string,
+	fromAmount int64,
+	toCurrency string,
+	toAmount int64,
+) error {
+	fromPosition, err := q.GetInternalAccount(ctx, GetInternalAccountParams{
+		AccountType: util.FXPositionAccount,
+		Currency:    fromCurrency,
+	})
+	if err != nil {
+		return err
+	}
+
+	toPosition, err := q.GetInternalAccount(ctx, GetInternalAccountParams{
+		AccountType: util.FXPositionAccount,
+		Currency:    toCurrency,
+	})
+	if err != nil {
+		return err
+	}
+
+	if fromPosition.ID < toPosition.ID {
+		_, _, err = addMoney(ctx, q, fromPosition.ID, fromAmount, toPosition.ID, -toAmount)
+	} else {
+		_, _, err = addMoney(ctx, q, toPosition.ID, -toAmount, fromPosition.ID, fromAmount)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = chainEntry(ctx, q, CreateEntryParams{
+		AccountID:  fromPosition.ID,
+		Amount:     fromAmount,
+		TransferID: journal.TransferID,
+		JournalID:  journalID(journal),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = chainEntry(ctx, q, CreateEntryParams{
+		AccountID:  toPosition.ID,
+		Amount:     -toAmount,
+		TransferID: journal.TransferID,
+		JournalID:  journalID(journal),
+	})
+	return err
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func requireBalancedJournal(t *testing.T, journal Journal) []Entry {
+	entries, err := testQueries.ListJournalEntries(context.Background(), sql.NullInt64{Int64: journal.ID, Valid: true})
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+
+	totals := map[string]int64{}
+	for _, entry := range entries {
+		account, err := testQueries.GetAccount(context.Background(), entry.AccountID)
+		require.NoError(t, err)
+		totals[account.Currency] += entry.Amount
+	}
+	for currency, total := range totals {
+		require.Zero(t, total, currency)
+	}
+
+	return entries
+}
+
+func TestPostJournalTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccountInCurrency(t, util.USD)
+	interest, err := testQueries.GetInternalAccount(context.Background(), GetInternalAccountParams{
+		AccountType: util.InterestExpenseAccount,
+		Currency:    util.USD,
+	})
+	require.NoError(t, err)
+
+	result, err := store.PostJournalTx(context.Background(), PostJournalTxParams{
+		Kind:      util.JournalKindInterest,
+		Memo:      "monthly interest",
+		CreatedBy: "ops",
+		Lines: []JournalLine{
+			{AccountID: account.ID, Amount: 5},
+			{AccountID: interest.ID, Amount: -5},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.JournalKindInterest, result.Journal.Kind)
+	require.False(t, result.Journal.TransferID.Valid)
+	require.Len(t, result.Entries, 2)
+	requireBalancedJournal(t, result.Journal)
+
+	updated, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+5, updated.Balance)
+
+	// nothing is posted when the lines don't balance
+	_, err = store.PostJournalTx(context.Background(), PostJournalTxParams{
+		Kind: util.JournalKindAdjustment,
+		Lines: []JournalLine{
+			{AccountID: account.ID, Amount: 5},
+			{AccountID: interest.ID, Amount: -4},
+		},
+	})
+	require.ErrorIs(t, err, ErrUnbalancedJournal)
+
+	unchanged, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, updated.Balance, unchanged.Balance)
+}
+
+func TestUnbalancedJournalRejectedByDatabase(t *testing.T) {
+	account := createRandomAccountInCurrency(t, util.USD)
+
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	q := New(tx)
+
+	journal, err := q.CreateJournal(context.Background(), CreateJournalParams{Kind: util.JournalKindAdjustment})
+	require.NoError(t, err)
+
+	_, err = q.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID: account.ID,
+		Amount:    10,
+		JournalID: sql.NullInt64{Int64: journal.ID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	// the check is deferred to commit
+	require.Error(t, tx.Commit())
+}
+
+func TestTransferTxJournal(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+	feeAccount, err := testQueries.GetInternalAccount(context.Background(), GetInternalAccountParams{
+		AccountType: util.FeeIncomeAccount,
+		Currency:    util.USD,
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		Fee:           2,
+		FeeAccountID:  feeAccount.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.TransferKindTransfer, result.Journal.Kind)
+	require.Equal(t, result.Transfer.ID, result.Journal.TransferID.Int64)
+	require.Equal(t, result.Journal.ID, result.FromEntry.JournalID.Int64)
+
+	entries := requireBalancedJournal(t, result.Journal)
+	require.Len(t, entries, 4)
+}
+
+func TestMultiCurrencyTransferTxJournal(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.EUR)
+
+	fxPosition := func(currency string) Account {
+		account, err := testQueries.GetInternalAccount(context.Background(), GetInternalAccountParams{
+			AccountType: util.FXPositionAccount,
+			Currency:    currency,
+		})
+		require.NoError(t, err)
+		return account
+	}
+	usdPosition := fxPosition(util.USD)
+	eurPosition := fxPosition(util.EUR)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        90,
+		FromAmount:    100,
+	})
+	require.NoError(t, err)
+
+	// the sender pays in their own currency
+	require.Equal(t, int64(-100), result.FromEntry.Amount)
+	require.Equal(t, account1.Balance-100, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+90, result.ToAccount.Balance)
+
+	entries := requireBalancedJournal(t, result.Journal)
+	require.Len(t, entries, 4)
+
+	require.Equal(t, usdPosition.Balance+100, fxPosition(util.USD).Balance)
+	require.Equal(t, eurPosition.Balance-90, fxPosition(util.EUR).Balance)
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"time"
+)
+
+// LedgerReport lists every discrepancy found in the ledger, which is consistent when all the lists are empty
+type LedgerReport struct {
+	CheckedAt  time.Time `json:"checked_at"`
+	Consistent bool      `json:"consistent"`
+	// accounts whose balance isn't the sum of their entries
+	AccountBalances []ListAccountBalanceMismatchesRow `json:"account_balances"`
+	// transfers whose entries don't credit the recipient the amount or debit the sender the amount and fee
+	Transfers []ListTransferEntryMismatchesRow `json:"transfers"`
+	// journals whose entries don't sum to zero in a currency
+	Journals []ListUnbalancedJournalsRow `json:"journals"`
+	// pot holding accounts that don't match the pots they hold in their currency
+	PotHoldings []ListPotHoldingMismatchesRow `json:"pot_holdings"`
+	// entries and transfers whose hashes show they were changed or deleted
+	Tampered []TamperedRow `json:"tampered"`
+	// sealed days that no longer match their seal
+	Seals []BrokenSeal `json:"seals"`
+}
+
+// VerifyLedger checks the ledger against itself and its hashes and seals. Seal signatures are checked
+// when publicKey is set. All checks read the same snapshot so money moving while they run can't show
+// up as a discrepancy.
+func (store *SQLStore) VerifyLedger(ctx context.Context, publicKey ed25519.PublicKey) (LedgerReport, error) {
+	report := LedgerReport{CheckedAt: time.Now()}
+
+	tx, err := store.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return report, err
+	}
+	defer tx.Rollback()
+	q := New(tx)
+
+	report.AccountBalances, err = q.ListAccountBalanceMismatches(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	report.Transfers, err = q.ListTransferEntryMismatches(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	report.Journals, err = q.ListUnbalancedJournals(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	report.PotHoldings, err = q.ListPotHoldingMismatches(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	report.Tampered, err = verifyEntryChains(ctx, q)
+	if err != nil {
+		return report, err
+	}
+
+	tamperedTransfers, err := verifyTransferHashes(ctx, q)
+	if err != nil {
+		return report, err
+	}
+	report.Tampered = append(report.Tampered, tamperedTransfers...)
+
+	report.Seals, err = verifySeals(ctx, q, publicKey)
+	if err != nil {
+		return report, err
+	}
+
+	report.Consistent = len(report.AccountBalances) == 0 &&
+		len(report.Transfers) == 0 &&
+		len(report.Journals) == 0 &&
+		len(report.PotHoldings) == 0 &&
+		len(report.Tampered) == 0 &&
+		len(report.Seals) == 0
+
+	return report, tx.Commit()
+}
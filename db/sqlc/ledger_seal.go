@@ -0,0 +1,188 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"time"
+
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// LedgerDay is the start of the UTC day t falls on, ledger seals cover one UTC day each
+func LedgerDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// SealLedgerDay writes the Merkle root of the hashes of every entry then every transfer of the day,
+// signed with key unless it is nil. A day can only be sealed once.
+func (store *SQLStore) SealLedgerDay(ctx context.Context, day time.Time, key ed25519.PrivateKey) (LedgerSeal, error) {
+	day = LedgerDay(day)
+	root, entryCount, transferCount, err := ledgerDayRoot(ctx, store.Queries, day)
+	if err != nil {
+		return LedgerSeal{}, err
+	}
+
+	var signature []byte
+	if key != nil {
+		signature = ed25519.Sign(key, root)
+	}
+
+	return store.CreateLedgerSeal(ctx, CreateLedgerSealParams{
+		Day:           day,
+		EntryCount:    entryCount,
+		TransferCount: transferCount,
+		MerkleRoot:    root,
+		Signature:     signature,
+	})
+}
+
+func ledgerDayRoot(ctx context.Context, q *Queries, day time.Time) ([]byte, int64, int64, error) {
+	since, until := day, day.AddDate(0, 0, 1)
+
+	entryHashes, err := q.ListEntryHashes(ctx, ListEntryHashesParams{
+		Since: since,
+		Until: until,
+	})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	transferHashes, err := q.ListTransferHashes(ctx, ListTransferHashesParams{
+		Since: since,
+		Until: until,
+	})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	leaves := append(entryHashes, transferHashes...)
+	return util.MerkleRoot(leaves), int64(len(entryHashes)), int64(len(transferHashes)), nil
+}
+
+// number of rows read at a time when checking hashes
+const ledgerVerifyPageSize = 1000
+
+// TamperedRow is an entry or transfer that no longer matches its hash, or an entry
+// whose chain shows that an entry before it was changed or deleted
+type TamperedRow struct {
+	Table     string `json:"table"`
+	ID        int64  `json:"id"`
+	AccountID int64  `json:"account_id,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// BrokenSeal is a sealed day whose entries and transfers no longer add up to its seal
+type BrokenSeal struct {
+	Day    time.Time `json:"day"`
+	Reason string    `json:"reason"`
+}
+
+// verifyEntryChains walks the entries of every account in order, recomputing their hashes
+func verifyEntryChains(ctx context.Context, q *Queries) ([]TamperedRow, error) {
+	tampered := []TamperedRow{}
+	arg := ListEntriesByAccountAfterParams{PageLimit: ledgerVerifyPageSize}
+	var previousHash []byte
+	hashed := false
+
+	for {
+		entries, err := q.ListEntriesByAccountAfter(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if entry.AccountID != arg.AccountID {
+				previousHash = nil
+				hashed = false
+			}
+			arg.AccountID = entry.AccountID
+			arg.ID = entry.ID
+
+			row := TamperedRow{Table: "entries", ID: entry.ID, AccountID: entry.AccountID}
+			switch {
+			case entry.Hash == nil:
+				// entries from before hashing have none, any entry after a hashed one must have one
+				if hashed {
+					row.Reason = "entry has no hash"
+					tampered = append(tampered, row)
+				}
+			case !bytes.Equal(entry.Hash, EntryHash(entry)):
+				row.Reason = "entry doesn't match its hash"
+				tampered = append(tampered, row)
+			case !bytes.Equal(entry.PreviousHash, previousHash):
+				row.Reason = "the entry before it on the account was changed or deleted"
+				tampered = append(tampered, row)
+			}
+
+			previousHash = entry.Hash
+			hashed = hashed || entry.Hash != nil
+		}
+
+		if len(entries) < ledgerVerifyPageSize {
+			return tampered, nil
+		}
+	}
+}
+
+func verifyTransferHashes(ctx context.Context, q *Queries) ([]TamperedRow, error) {
+	tampered := []TamperedRow{}
+	arg := ListHashedTransfersAfterParams{Limit: ledgerVerifyPageSize}
+
+	for {
+		transfers, err := q.ListHashedTransfersAfter(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, transfer := range transfers {
+			arg.ID = transfer.ID
+			if !transferHashMatches(transfer) {
+				tampered = append(tampered, TamperedRow{
+					Table:  "transfers",
+					ID:     transfer.ID,
+					Reason: "transfer doesn't match its hash",
+				})
+			}
+		}
+
+		if len(transfers) < ledgerVerifyPageSize {
+			return tampered, nil
+		}
+	}
+}
+
+// verifySeals recomputes the root of every sealed day. Signatures are checked when publicKey is set.
+func verifySeals(ctx context.Context, q *Queries, publicKey ed25519.PublicKey) ([]BrokenSeal, error) {
+	broken := []BrokenSeal{}
+
+	seals, err := q.ListLedgerSeals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, seal := range seals {
+		root, entryCount, transferCount, err := ledgerDayRoot(ctx, q, seal.Day)
+		if err != nil {
+			return nil, err
+		}
+
+		reason := ""
+		switch {
+		case entryCount != seal.EntryCount || transferCount != seal.TransferCount:
+			reason = "entries or transfers were added to or deleted from the day"
+		case !bytes.Equal(root, seal.MerkleRoot):
+			reason = "entries or transfers of the day were changed"
+		case publicKey != nil && seal.Signature == nil:
+			reason = "seal is not signed"
+		case publicKey != nil && !ed25519.Verify(publicKey, seal.MerkleRoot, seal.Signature):
+			reason = "seal signature is invalid"
+		}
+		if reason != "" {
+			broken = append(broken, BrokenSeal{Day: seal.Day, Reason: reason})
+		}
+	}
+
+	return broken, nil
+}
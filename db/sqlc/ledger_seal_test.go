@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryHashChain(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+
+	var fromEntries []Entry
+	for i := 0; i < 2; i++ {
+		result, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
+		require.Equal(t, TransferHash(result.Transfer), result.Transfer.Hash)
+		require.Equal(t, EntryHash(result.FromEntry), result.FromEntry.Hash)
+		fromEntries = append(fromEntries, result.FromEntry)
+	}
+
+	require.Nil(t, fromEntries[0].PreviousHash)
+	require.Equal(t, fromEntries[0].Hash, fromEntries[1].PreviousHash)
+}
+
+func TestVerifyLedgerTamperedTransfer(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	_, err = testDB.Exec("UPDATE transfers SET memo = 'changed' WHERE id = $1", result.Transfer.ID)
+	require.NoError(t, err)
+
+	report, err := store.VerifyLedger(context.Background(), nil)
+	require.NoError(t, err)
+	require.False(t, report.Consistent)
+	require.Contains(t, report.Tampered, TamperedRow{
+		Table:  "transfers",
+		ID:     result.Transfer.ID,
+		Reason: "transfer doesn't match its hash",
+	})
+}
+
+func TestVerifyLedgerTamperedFromAmount(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	_, err = testDB.Exec("UPDATE transfers SET from_amount = 1 WHERE id = $1", result.Transfer.ID)
+	require.NoError(t, err)
+
+	report, err := store.VerifyLedger(context.Background(), nil)
+	require.NoError(t, err)
+	require.False(t, report.Consistent)
+	require.Contains(t, report.Tampered, TamperedRow{
+		Table:  "transfers",
+		ID:     result.Transfer.ID,
+		Reason: "transfer doesn't match its hash",
+	})
+}
+
+func TestVerifyLedgerTransferHashV1(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// a transfer hashed before from_amount was still verifies
+	_, err = testDB.Exec("UPDATE transfers SET hash = $2 WHERE id = $1", result.Transfer.ID, transferHashV1(result.Transfer))
+	require.NoError(t, err)
+
+	report, err := store.VerifyLedger(context.Background(), nil)
+	require.NoError(t, err)
+	for _, row := range report.Tampered {
+		require.False(t, row.Table == "transfers" && row.ID == result.Transfer.ID)
+	}
+}
+
+func TestSealLedgerDay(t *testing.T) {
+	store := NewStore(testDB)
+
+	seed := []byte(util.RandomString(ed25519.SeedSize))
+	key := ed25519.NewKeyFromSeed(seed)
+	// a random day long before any entry so reruns don't seal the same day twice
+	day := time.Date(1900+int(util.RandomInt(0, 99)), time.Month(util.RandomInt(1, 12)), int(util.RandomInt(1, 28)), 13, 0, 0, 0, time.UTC)
+
+	seal, err := store.SealLedgerDay(context.Background(), day, key)
+	require.NoError(t, err)
+	require.True(t, seal.Day.Equal(LedgerDay(day)))
+	require.Zero(t, seal.EntryCount)
+	require.Zero(t, seal.TransferCount)
+	require.Equal(t, util.MerkleRoot(nil), seal.MerkleRoot)
+	require.True(t, ed25519.Verify(key.Public().(ed25519.PublicKey), seal.MerkleRoot, seal.Signature))
+
+	_, err = store.SealLedgerDay(context.Background(), day, key)
+	require.Error(t, err)
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyLedger(t *testing.T) {
+	store := NewStore(testDB)
+
+	// test accounts are opened with a balance no entry accounts for
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	report, err := store.VerifyLedger(context.Background(), nil)
+	require.NoError(t, err)
+	require.False(t, report.Consistent)
+	require.NotZero(t, report.CheckedAt)
+
+	mismatches := map[int64]ListAccountBalanceMismatchesRow{}
+	for _, mismatch := range report.AccountBalances {
+		mismatches[mismatch.AccountID] = mismatch
+	}
+	require.Contains(t, mismatches, account1.ID)
+	require.Equal(t, account1.Balance-10, mismatches[account1.ID].Balance)
+	require.Equal(t, int64(-10), mismatches[account1.ID].EntriesBalance)
+
+	for _, mismatch := range report.Transfers {
+		require.NotEqual(t, result.Transfer.ID, mismatch.TransferID)
+	}
+	for _, journal := range report.Journals {
+		require.NotEqual(t, result.Journal.ID, journal.JournalID.Int64)
+	}
+}
+
+func TestVerifyLedgerTransferEntriesFX(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.EUR)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        90,
+		FromAmount:    100,
+	})
+	require.NoError(t, err)
+
+	report, err := store.VerifyLedger(context.Background(), nil)
+	require.NoError(t, err)
+	for _, mismatch := range report.Transfers {
+		require.NotEqual(t, result.Transfer.ID, mismatch.TransferID)
+	}
+
+	// the sender's debit has to be the whole from_amount, not just any debit
+	_, err = testDB.Exec("UPDATE transfers SET from_amount = 110 WHERE id = $1", result.Transfer.ID)
+	require.NoError(t, err)
+
+	report, err = store.VerifyLedger(context.Background(), nil)
+	require.NoError(t, err)
+	require.Contains(t, report.Transfers, ListTransferEntryMismatchesRow{
+		TransferID: result.Transfer.ID,
+		Amount:     90,
+		FromAmount: 110,
+		Credited:   90,
+		Debited:    -100,
+	})
+}
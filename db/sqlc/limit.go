@@ -0,0 +1,216 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// GetTransferAllowanceParams contains the input parameters for working out a transfer allowance
+type GetTransferAllowanceParams struct {
+	Username string `json:"username"`
+	Currency string `json:"currency"`
+}
+
+// TransferAllowance holds a user's limits in a currency and how much of them is used up.
+// A limit of 0 means there is no limit, in which case the remaining amount is nil.
+type TransferAllowance struct {
+	Currency            string `json:"currency"`
+	Tier                string `json:"tier"`
+	Overridden          bool   `json:"overridden"`
+	PerTransactionLimit int64  `json:"per_transaction_limit"`
+	DailyLimit          int64  `json:"daily_limit"`
+	MonthlyLimit        int64  `json:"monthly_limit"`
+	DailyUsed           int64  `json:"daily_used"`
+	MonthlyUsed         int64  `json:"monthly_used"`
+	DailyRemaining      *int64 `json:"daily_remaining"`
+	MonthlyRemaining    *int64 `json:"monthly_remaining"`
+}
+
+// ErrTransferLimitExceeded is matched by every error Check returns, and by a transfer going over
+// the daily limit of a beneficiary
+var ErrTransferLimitExceeded = errors.New("transfer limit exceeded")
+
+// limitError says which limit a transfer went over
+type limitError struct {
+	message string
+}
+
+func (err limitError) Error() string {
+	return err.message
+}
+
+func (err limitError) Is(target error) bool {
+	return target == ErrTransferLimitExceeded
+}
+
+// Check returns an error if sending amount would go over any of the limits
+func (allowance TransferAllowance) Check(amount int64) error {
+	if allowance.PerTransactionLimit > 0 && amount > allowance.PerTransactionLimit {
+		return limitError{fmt.Sprintf("amount exceeds the per transaction limit of %d %s", allowance.PerTransactionLimit, allowance.Currency)}
+	}
+	if allowance.DailyRemaining != nil && amount > *allowance.DailyRemaining {
+		return limitError{fmt.Sprintf("amount exceeds the remaining daily limit of %d %s", *allowance.DailyRemaining, allowance.Currency)}
+	}
+	if allowance.MonthlyRemaining != nil && amount > *allowance.MonthlyRemaining {
+		return limitError{fmt.Sprintf("amount exceeds the remaining monthly limit of %d %s", *allowance.MonthlyRemaining, allowance.Currency)}
+	}
+	return nil
+}
+
+// GetTransferAllowance sums today's and this month's outgoing transfers and pending holds of a user
+// in a currency and compares them against the user's override, or the limits of the user's tier
+func (store *SQLStore) GetTransferAllowance(ctx context.Context, arg GetTransferAllowanceParams) (TransferAllowance, error) {
+	return transferAllowance(ctx, store.Queries, arg)
+}
+
+// checkTransferLimit makes sure username can still send amount out of an account within an open
+// transaction. The user's limits are locked until the transaction ends so concurrent transfers
+// are counted one after the other instead of both fitting in the same remaining allowance.
+func checkTransferLimit(ctx context.Context, q *Queries, username string, accountID int64, amount int64) error {
+	if err := q.LockTransferLimit(ctx, username); err != nil {
+		return err
+	}
+
+	account, err := q.GetAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	allowance, err := transferAllowance(ctx, q, GetTransferAllowanceParams{
+		Username: username,
+		Currency: account.Currency,
+	})
+	if err != nil {
+		return err
+	}
+	return allowance.Check(amount)
+}
+
+// checkBeneficiaryLimit makes sure amount still fits in the daily limit username set on the beneficiary
+// saved for an account, if there is one. It relies on the lock checkTransferLimit took on username's limits.
+func checkBeneficiaryLimit(ctx context.Context, q *Queries, username string, toAccountID int64, amount int64) error {
+	beneficiary, err := q.GetBeneficiaryByAccount(ctx, GetBeneficiaryByAccountParams{
+		Owner:     username,
+		AccountID: toAccountID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if beneficiary.DailyLimit == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	year, month, day := now.Date()
+	sent, err := q.GetBeneficiaryTransferTotal(ctx, GetBeneficiaryTransferTotalParams{
+		Owner:       username,
+		ToAccountID: toAccountID,
+		Since:       time.Date(year, month, day, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		return err
+	}
+
+	if sent+amount > beneficiary.DailyLimit {
+		return limitError{fmt.Sprintf("amount exceeds the daily limit of %d %s for %s, %d has been sent today", beneficiary.DailyLimit, beneficiary.Currency, beneficiary.Nickname, sent)}
+	}
+	return nil
+}
+
+func transferAllowance(ctx context.Context, q *Queries, arg GetTransferAllowanceParams) (TransferAllowance, error) {
+	allowance := TransferAllowance{Currency: arg.Currency}
+
+	user, err := q.GetUser(ctx, arg.Username)
+	if err != nil {
+		return allowance, err
+	}
+	allowance.Tier = user.Tier
+
+	override, err := q.GetTransferLimitOverride(ctx, GetTransferLimitOverrideParams{
+		Username: arg.Username,
+		Currency: arg.Currency,
+	})
+	switch {
+	case err == nil:
+		allowance.Overridden = true
+		allowance.PerTransactionLimit = override.PerTransaction
+		allowance.DailyLimit = override.Daily
+		allowance.MonthlyLimit = override.Monthly
+	case errors.Is(err, sql.ErrNoRows):
+		limit, err := q.GetTransferLimit(ctx, GetTransferLimitParams{
+			Tier:     user.Tier,
+			Currency: arg.Currency,
+		})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return allowance, err
+		}
+		allowance.PerTransactionLimit = limit.PerTransaction
+		allowance.DailyLimit = limit.Daily
+		allowance.MonthlyLimit = limit.Monthly
+	default:
+		return allowance, err
+	}
+
+	// days and months start at midnight UTC whatever the server's timezone
+	now := time.Now().UTC()
+	year, month, day := now.Date()
+	startOfDay := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	startOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+
+	allowance.DailyUsed, err = usedSince(ctx, q, arg, startOfDay)
+	if err != nil {
+		return allowance, err
+	}
+
+	allowance.MonthlyUsed, err = usedSince(ctx, q, arg, startOfMonth)
+	if err != nil {
+		return allowance, err
+	}
+
+	allowance.DailyRemaining = remaining(allowance.DailyLimit, allowance.DailyUsed)
+	allowance.MonthlyRemaining = remaining(allowance.MonthlyLimit, allowance.MonthlyUsed)
+
+	return allowance, nil
+}
+
+// usedSince sums a user's outgoing transfers and pending holds in a currency since a point in time,
+// a hold counts until it is captured into a transfer or released
+func usedSince(ctx context.Context, q *Queries, arg GetTransferAllowanceParams, since time.Time) (int64, error) {
+	transferred, err := q.GetOutgoingTransferTotal(ctx, GetOutgoingTransferTotalParams{
+		Username: arg.Username,
+		Currency: arg.Currency,
+		Since:    since,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	held, err := q.GetPendingHoldTotal(ctx, GetPendingHoldTotalParams{
+		Username: arg.Username,
+		Currency: arg.Currency,
+		Since:    since,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return transferred + held, nil
+}
+
+func remaining(limit int64, used int64) *int64 {
+	if limit == 0 {
+		return nil
+	}
+
+	left := limit - used
+	if left < 0 {
+		left = 0
+	}
+	return &left
+}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferAllowanceCheck(t *testing.T) {
+	remaining := int64(50)
+
+	allowance := TransferAllowance{
+		Currency:            util.USD,
+		PerTransactionLimit: 100,
+		DailyRemaining:      &remaining,
+	}
+
+	require.NoError(t, allowance.Check(50))
+	require.ErrorIs(t, allowance.Check(51), ErrTransferLimitExceeded)
+	require.ErrorIs(t, allowance.Check(101), ErrTransferLimitExceeded)
+
+	// no limits at all
+	require.NoError(t, TransferAllowance{}.Check(1000000))
+}
+
+func TestGetTransferAllowance(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	override, err := testQueries.UpsertTransferLimitOverride(context.Background(), UpsertTransferLimitOverrideParams{
+		Username: account1.Owner,
+		Currency: account1.Currency,
+		Daily:    1000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), override.Daily)
+
+	amount := int64(300)
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		LimitUsername: account1.Owner,
+	})
+	require.NoError(t, err)
+
+	allowance, err := store.GetTransferAllowance(context.Background(), GetTransferAllowanceParams{
+		Username: account1.Owner,
+		Currency: account1.Currency,
+	})
+	require.NoError(t, err)
+	require.True(t, allowance.Overridden)
+	require.Equal(t, util.StandardTier, allowance.Tier)
+	require.Equal(t, amount, allowance.DailyUsed)
+	require.Equal(t, amount, allowance.MonthlyUsed)
+	require.NotNil(t, allowance.DailyRemaining)
+	require.Equal(t, int64(700), *allowance.DailyRemaining)
+	require.Nil(t, allowance.MonthlyRemaining)
+}
+
+func TestTransferTxLimit(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := testQueries.UpsertTransferLimitOverride(context.Background(), UpsertTransferLimitOverrideParams{
+		Username: account1.Owner,
+		Currency: account1.Currency,
+		Daily:    500,
+	})
+	require.NoError(t, err)
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        300,
+		LimitUsername: account1.Owner,
+	}
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Amount, result.Transfer.FromAmount)
+
+	// the first transfer is counted within the second one's transaction
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	// transfers that aren't counted against anyone's limits aren't checked
+	arg.LimitUsername = ""
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+}
+
+func TestTransferLimitsCountAgainstInitiator(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	// a joint member of account1 pays out of it
+	member := createRandomUser(t)
+	_, err := testQueries.CreateAccountMember(context.Background(), CreateAccountMemberParams{
+		AccountID:    account1.ID,
+		Username:     member.Username,
+		Role:         util.AccountRolePayer,
+		PaymentLimit: 1000,
+		InvitedBy:    account1.Owner,
+	})
+	require.NoError(t, err)
+	_, err = testQueries.AcceptAccountMember(context.Background(), AcceptAccountMemberParams{
+		AccountID: account1.ID,
+		Username:  member.Username,
+	})
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        30,
+		LimitUsername: member.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, member.Username, result.Transfer.InitiatedBy)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        20,
+		LimitUsername: account1.Owner,
+	})
+	require.NoError(t, err)
+
+	// each payment counts against the user who made it, not the account's owner
+	memberAllowance, err := store.GetTransferAllowance(context.Background(), GetTransferAllowanceParams{
+		Username: member.Username,
+		Currency: account1.Currency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(30), memberAllowance.DailyUsed)
+	require.Equal(t, int64(30), memberAllowance.MonthlyUsed)
+
+	ownerAllowance, err := store.GetTransferAllowance(context.Background(), GetTransferAllowanceParams{
+		Username: account1.Owner,
+		Currency: account1.Currency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(20), ownerAllowance.DailyUsed)
+	require.Equal(t, int64(20), ownerAllowance.MonthlyUsed)
+}
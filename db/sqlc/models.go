@@ -5,25 +5,102 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Account struct {
-	ID        int64     `json:"id"`
-	Owner     string    `json:"owner"`
-	Balance   int64     `json:"balance"`
-	Currency  string    `json:"currency"`
+	ID          int64     `json:"id"`
+	Owner       string    `json:"owner"`
+	Balance     int64     `json:"balance"`
+	Currency    string    `json:"currency"`
+	CreatedAt   time.Time `json:"created_at"`
+	AccountType string    `json:"account_type"`
+	// balance less pending holds
+	AvailableBalance int64 `json:"available_balance"`
+	// set on business accounts, their payments go through the organization
+	OrganizationID sql.NullInt64 `json:"organization_id"`
+}
+
+type AccountMember struct {
+	AccountID int64  `json:"account_id"`
+	Username  string `json:"username"`
+	// co_owner, viewer or payer, the owner is accounts.owner
+	Role string `json:"role"`
+	// largest single payment a payer can make, 0 means no limit
+	PaymentLimit int64  `json:"payment_limit"`
+	InvitedBy    string `json:"invited_by"`
+	// null until the invited user accepts
+	AcceptedAt sql.NullTime `json:"accepted_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type Alias struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	// email or phone, usernames are aliases of themselves
+	AliasType string `json:"alias_type"`
+	// lower case email or E.164 phone number
+	Value string `json:"value"`
+	// bcrypt hash of the verification code sent to the alias
+	HashedCode     string       `json:"hashed_code"`
+	CodeExpiresAt  time.Time    `json:"code_expires_at"`
+	FailedAttempts int32        `json:"failed_attempts"`
+	VerifiedAt     sql.NullTime `json:"verified_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+type BalanceSnapshot struct {
+	AccountID int64 `json:"account_id"`
+	// last entry of the account the balance includes
+	EntryID int64 `json:"entry_id"`
+	Balance int64 `json:"balance"`
+	// latest created_at of the entries the balance includes
+	TakenAt   time.Time `json:"taken_at"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type Beneficiary struct {
+	ID        int64  `json:"id"`
+	Owner     string `json:"owner"`
+	Nickname  string `json:"nickname"`
+	AccountID int64  `json:"account_id"`
+	// the alias the beneficiary was saved by, empty if saved by account
+	Alias    string `json:"alias"`
+	Currency string `json:"currency"`
+	// 0 means no limit
+	DailyLimit int64 `json:"daily_limit"`
+	// end of the cooling-off period, transfers before it need a confirmation code
+	TrustedAt time.Time `json:"trusted_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DefaultAccount struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+	// the account payments to the owner's aliases go to in this currency
+	AccountID int64     `json:"account_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type Entry struct {
 	ID        int64 `json:"id"`
 	AccountID int64 `json:"account_id"`
 	// can be positive or negative
-	Amount    int64     `json:"amount"`
-	CreatedAt time.Time `json:"created_at"`
+	Amount     int64         `json:"amount"`
+	CreatedAt  time.Time     `json:"created_at"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	// set on moves between the account and one of its pots
+	PotID sql.NullInt64 `json:"pot_id"`
+	// empty on entries posted before journals were introduced
+	JournalID sql.NullInt64 `json:"journal_id"`
+	// hash of the entry before it on the same account, empty on an account's first hashed entry
+	PreviousHash []byte `json:"previous_hash"`
+	// SHA-256 of the entry and its previous_hash, empty on entries posted before hashing was introduced
+	Hash []byte `json:"hash"`
 }
 
 type ExchangeRate struct {
@@ -32,6 +109,185 @@ type ExchangeRate struct {
 	TargetCurrency string    `json:"target_currency"`
 	ExchangeRate   string    `json:"exchange_rate"`
 	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type FeeSchedule struct {
+	ID           int64  `json:"id"`
+	TransferType string `json:"transfer_type"`
+	Currency     string `json:"currency"`
+	// lower bound of the tier this schedule applies to
+	MinAmount int64 `json:"min_amount"`
+	FlatFee   int64 `json:"flat_fee"`
+	// basis points, 100 = 1%
+	PercentageBps int64 `json:"percentage_bps"`
+	MinFee        int64 `json:"min_fee"`
+	// 0 means no cap
+	MaxFee    int64     `json:"max_fee"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type FeeWaiver struct {
+	ID           int64     `json:"id"`
+	AccountType  string    `json:"account_type"`
+	TransferType string    `json:"transfer_type"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Hold struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// must be positive
+	Amount         int64 `json:"amount"`
+	Fee            int64 `json:"fee"`
+	CapturedAmount int64 `json:"captured_amount"`
+	// pending, captured, partially_captured, voided or expired
+	Status     string        `json:"status"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+	// the user who placed the hold and whose transfer limits it counts against
+	InitiatedBy string `json:"initiated_by"`
+}
+
+type Journal struct {
+	ID         int64         `json:"id"`
+	Kind       string        `json:"kind"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	Memo       string        `json:"memo"`
+	// ops user who posted a manual journal, empty for postings made by the bank
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LedgerSeal struct {
+	Day           time.Time `json:"day"`
+	EntryCount    int64     `json:"entry_count"`
+	TransferCount int64     `json:"transfer_count"`
+	MerkleRoot    []byte    `json:"merkle_root"`
+	// ed25519 signature of merkle_root, empty when no signing key is configured
+	Signature []byte    `json:"signature"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Organization struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// transfers above this amount, in the account's currency, wait for approval
+	ApprovalThreshold int64 `json:"approval_threshold"`
+	// distinct approvers, other than the initiator, a transfer needs
+	RequiredApprovals int32     `json:"required_approvals"`
+	CreatedBy         string    `json:"created_by"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type OrganizationMember struct {
+	OrganizationID int64  `json:"organization_id"`
+	Username       string `json:"username"`
+	// admin, approver or initiator
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OrganizationTransfer struct {
+	ID             int64  `json:"id"`
+	OrganizationID int64  `json:"organization_id"`
+	FromAccountID  int64  `json:"from_account_id"`
+	ToAccountID    int64  `json:"to_account_id"`
+	Amount         int64  `json:"amount"`
+	Memo           string `json:"memo"`
+	InitiatedBy    string `json:"initiated_by"`
+	// pending_approval, executed or rejected
+	Status string `json:"status"`
+	// set once the quorum is met and the transfer is executed
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+type OrganizationTransferApproval struct {
+	OrganizationTransferID int64     `json:"organization_transfer_id"`
+	Username               string    `json:"username"`
+	CreatedAt              time.Time `json:"created_at"`
+}
+
+type OutboxEvent struct {
+	ID int64 `json:"id"`
+	// TransferCompleted, AccountCreated, UserCreated or RateUpdated
+	EventType     string `json:"event_type"`
+	AggregateType string `json:"aggregate_type"`
+	// id of the transfer, account or exchange rate, username of the user
+	AggregateID string          `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"created_at"`
+	// null until the relay has handed the event to the publisher
+	PublishedAt sql.NullTime `json:"published_at"`
+	// failed attempts to publish the event
+	Attempts  int32  `json:"attempts"`
+	LastError string `json:"last_error"`
+	// a failed event is not published again before this
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	// set once the event has failed too many times, it is no longer published
+	FailedAt sql.NullTime `json:"failed_at"`
+}
+
+type PaymentRequest struct {
+	ID        int64  `json:"id"`
+	Requester string `json:"requester"`
+	Payer     string `json:"payer"`
+	// the requester's account the money is paid into
+	ToAccountID int64 `json:"to_account_id"`
+	// must be positive
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+	Memo     string `json:"memo"`
+	// pending, paid, declined, cancelled or expired
+	Status string `json:"status"`
+	// set once the request is paid
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+type Pot struct {
+	ID        int64  `json:"id"`
+	AccountID int64  `json:"account_id"`
+	Name      string `json:"name"`
+	// money set aside from the account, not part of its balance
+	Balance int64 `json:"balance"`
+	// 0 means no target
+	TargetAmount int64        `json:"target_amount"`
+	TargetDate   sql.NullTime `json:"target_date"`
+	// outgoing transfers from the account are rounded up into this pot, one pot per account
+	RoundUp bool `json:"round_up"`
+	// pots are closed rather than deleted so their entries keep pointing at them
+	ClosedAt  sql.NullTime `json:"closed_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	// must be positive
+	Amount int64 `json:"amount"`
+	// once, daily, weekly, monthly or end_of_month
+	Frequency string `json:"frequency"`
+	// only used by monthly schedules, clamped to the length of the month
+	DayOfMonth int32     `json:"day_of_month"`
+	NextRunAt  time.Time `json:"next_run_at"`
+	// set while a failed run is waiting to be retried
+	RetryAt sql.NullTime `json:"retry_at"`
+	// active, paused, completed or failed
+	Status         string        `json:"status"`
+	FailedAttempts int32         `json:"failed_attempts"`
+	LastError      string        `json:"last_error"`
+	LastRunAt      sql.NullTime  `json:"last_run_at"`
+	LastTransferID sql.NullInt64 `json:"last_transfer_id"`
+	CreatedAt      time.Time     `json:"created_at"`
 }
 
 type Session struct {
@@ -52,6 +308,103 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// charged to the sender on top of amount
+	Fee int64 `json:"fee"`
+	// transfer, reversal or refund
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+	// the transfer a reversal or refund compensates
+	OriginalTransferID sql.NullInt64 `json:"original_transfer_id"`
+	// sum of reversals and refunds, never above amount
+	ReversedAmount int64 `json:"reversed_amount"`
+	// free text from the sender, visible to both parties
+	Memo string `json:"memo"`
+	// end-to-end reference used for reconciliation
+	Reference string `json:"reference"`
+	// only shown on the sender's statement
+	SenderDescription string `json:"sender_description"`
+	// only shown on the recipient's statement
+	RecipientDescription string `json:"recipient_description"`
+	// set and edited by the sender, empty if uncategorised
+	SenderCategory string `json:"sender_category"`
+	// set and edited by the recipient, empty if uncategorised
+	RecipientCategory string `json:"recipient_category"`
+	// SHA-256 of the fields of the transfer that never change
+	Hash []byte `json:"hash"`
+	// debited from the sender before the fee, in the sender's currency where amount is in the recipient's
+	FromAmount int64 `json:"from_amount"`
+	// the user who started the transfer and whose transfer limits it counts against, empty for transfers the bank makes
+	InitiatedBy string `json:"initiated_by"`
+}
+
+type TransferBatch struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	// atomic or best_effort
+	Mode string `json:"mode"`
+	// pending, processing, completed, partially_completed or failed
+	Status         string       `json:"status"`
+	ItemCount      int32        `json:"item_count"`
+	SucceededCount int32        `json:"succeeded_count"`
+	FailedCount    int32        `json:"failed_count"`
+	TotalAmount    int64        `json:"total_amount"`
+	Error          string       `json:"error"`
+	CreatedAt      time.Time    `json:"created_at"`
+	CompletedAt    sql.NullTime `json:"completed_at"`
+	// when a processor last claimed the batch, one left processing for too long is claimed again
+	ClaimedAt sql.NullTime `json:"claimed_at"`
+}
+
+type TransferBatchItem struct {
+	ID      int64 `json:"id"`
+	BatchID int64 `json:"batch_id"`
+	// the row of the uploaded file, starting at 1
+	LineNumber  int32  `json:"line_number"`
+	ToAccountID int64  `json:"to_account_id"`
+	Amount      int64  `json:"amount"`
+	Currency    string `json:"currency"`
+	Reference   string `json:"reference"`
+	// pending, succeeded or failed
+	Status     string        `json:"status"`
+	Error      string        `json:"error"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type TransferConfirmation struct {
+	ID            int64  `json:"id"`
+	Username      string `json:"username"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	// bcrypt hash of the code sent to one of the user's verified aliases
+	HashedCode     string    `json:"hashed_code"`
+	FailedAttempts int32     `json:"failed_attempts"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type TransferLimit struct {
+	ID       int64  `json:"id"`
+	Tier     string `json:"tier"`
+	Currency string `json:"currency"`
+	// 0 means no limit
+	PerTransaction int64     `json:"per_transaction"`
+	Daily          int64     `json:"daily"`
+	Monthly        int64     `json:"monthly"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type TransferLimitOverride struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Currency string `json:"currency"`
+	// 0 means no limit
+	PerTransaction int64     `json:"per_transaction"`
+	Daily          int64     `json:"daily"`
+	Monthly        int64     `json:"monthly"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 type User struct {
@@ -61,4 +414,6 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	Tier              string    `json:"tier"`
+	Role              string    `json:"role"`
 }
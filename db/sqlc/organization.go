@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+var (
+	ErrOrganizationTransferNotPending = errors.New("organization transfer is no longer pending approval")
+	ErrSelfApproval                   = errors.New("the initiator of a transfer cannot approve it")
+	ErrAlreadyApproved                = errors.New("transfer has already been approved by this user")
+)
+
+// CreateOrganizationTxParams contains the input parameters for creating an organization
+type CreateOrganizationTxParams struct {
+	Name              string `json:"name"`
+	ApprovalThreshold int64  `json:"approval_threshold"`
+	RequiredApprovals int32  `json:"required_approvals"`
+	CreatedBy         string `json:"created_by"`
+}
+
+// CreateOrganizationTx creates an organization with its creator as the first admin
+func (store *SQLStore) CreateOrganizationTx(ctx context.Context, arg CreateOrganizationTxParams) (Organization, error) {
+	var organization Organization
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		organization, err = q.CreateOrganization(ctx, CreateOrganizationParams(arg))
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateOrganizationMember(ctx, CreateOrganizationMemberParams{
+			OrganizationID: organization.ID,
+			Username:       arg.CreatedBy,
+			Role:           util.OrganizationRoleAdmin,
+		})
+		return err
+	})
+
+	return organization, err
+}
+
+// ApproveOrganizationTransferTxParams contains the input parameters for approving an organization transfer
+type ApproveOrganizationTransferTxParams struct {
+	OrganizationTransferID int64  `json:"organization_transfer_id"`
+	Approver               string `json:"approver"`
+	Fee                    int64  `json:"fee"`
+	FeeAccountID           int64  `json:"fee_account_id"`
+}
+
+// ApproveOrganizationTransferTxResult contains the transfer, everyone who approved it so far
+// and, once the quorum was met, the executed transfer
+type ApproveOrganizationTransferTxResult struct {
+	OrganizationTransfer OrganizationTransfer           `json:"organization_transfer"`
+	Approvals            []OrganizationTransferApproval `json:"approvals"`
+	Transfer             *TransferTxResult              `json:"transfer,omitempty"`
+}
+
+// ApproveOrganizationTransferTx records an approval and executes the transfer once the organization's
+// required number of distinct approvers, other than the initiator and still members who can approve, is met. The transfer is locked so
+// two approvals arriving together can't both execute it.
+func (store *SQLStore) ApproveOrganizationTransferTx(ctx context.Context, arg ApproveOrganizationTransferTxParams) (ApproveOrganizationTransferTxResult, error) {
+	var result ApproveOrganizationTransferTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		pending, err := q.GetOrganizationTransferForUpdate(ctx, arg.OrganizationTransferID)
+		if err != nil {
+			return err
+		}
+		result.OrganizationTransfer = pending
+
+		if pending.Status != util.OrganizationTransferStatusPending {
+			return ErrOrganizationTransferNotPending
+		}
+		if pending.InitiatedBy == arg.Approver {
+			return ErrSelfApproval
+		}
+
+		result.Approvals, err = q.ListOrganizationTransferApprovals(ctx, pending.ID)
+		if err != nil {
+			return err
+		}
+		for _, approval := range result.Approvals {
+			if approval.Username == arg.Approver {
+				return ErrAlreadyApproved
+			}
+		}
+
+		approval, err := q.CreateOrganizationTransferApproval(ctx, CreateOrganizationTransferApprovalParams{
+			OrganizationTransferID: pending.ID,
+			Username:               arg.Approver,
+		})
+		if err != nil {
+			return err
+		}
+		result.Approvals = append(result.Approvals, approval)
+
+		organization, err := q.GetOrganization(ctx, pending.OrganizationID)
+		if err != nil {
+			return err
+		}
+
+		// approvals given by members who have since been removed or lost the approver role don't count
+		approvals, err := q.CountCurrentOrganizationTransferApprovals(ctx, pending.ID)
+		if err != nil {
+			return err
+		}
+		if approvals < int64(organization.RequiredApprovals) {
+			return nil
+		}
+
+		executed, err := transfer(ctx, q, TransferTxParams{
+			FromAccountID: pending.FromAccountID,
+			ToAccountID:   pending.ToAccountID,
+			Amount:        pending.Amount,
+			Fee:           arg.Fee,
+			FeeAccountID:  arg.FeeAccountID,
+			Memo:          pending.Memo,
+			LimitUsername: pending.InitiatedBy,
+		}, util.TransferKindTransfer, sql.NullInt64{})
+		if err != nil {
+			return err
+		}
+		result.Transfer = &executed
+
+		result.OrganizationTransfer, err = q.CloseOrganizationTransfer(ctx, CloseOrganizationTransferParams{
+			ID:         pending.ID,
+			Status:     util.OrganizationTransferStatusExecuted,
+			TransferID: sql.NullInt64{Int64: executed.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
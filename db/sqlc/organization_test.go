@@ -0,0 +1,184 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApproveOrganizationTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	admin := createRandomUser(t)
+	approver := createRandomUser(t)
+	initiator := createRandomUser(t)
+
+	organization, err := store.CreateOrganizationTx(context.Background(), CreateOrganizationTxParams{
+		Name:              util.RandomOwner(),
+		ApprovalThreshold: 100,
+		RequiredApprovals: 2,
+		CreatedBy:         admin.Username,
+	})
+	require.NoError(t, err)
+
+	member, err := testQueries.GetOrganizationMember(context.Background(), GetOrganizationMemberParams{
+		OrganizationID: organization.ID,
+		Username:       admin.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.OrganizationRoleAdmin, member.Role)
+
+	for _, user := range []User{approver, initiator} {
+		_, err := testQueries.CreateOrganizationMember(context.Background(), CreateOrganizationMemberParams{
+			OrganizationID: organization.ID,
+			Username:       user.Username,
+			Role:           util.OrganizationRoleApprover,
+		})
+		require.NoError(t, err)
+	}
+
+	fromAccount, err := testQueries.CreateOrganizationAccount(context.Background(), CreateOrganizationAccountParams{
+		Owner:          admin.Username,
+		Currency:       util.USD,
+		OrganizationID: sql.NullInt64{Int64: organization.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	fromAccount, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		Amount: 1000,
+		ID:     fromAccount.ID,
+	})
+	require.NoError(t, err)
+	toAccount := createRandomAccountInCurrency(t, util.USD)
+
+	pending, err := testQueries.CreateOrganizationTransfer(context.Background(), CreateOrganizationTransferParams{
+		OrganizationID: organization.ID,
+		FromAccountID:  fromAccount.ID,
+		ToAccountID:    toAccount.ID,
+		Amount:         500,
+		InitiatedBy:    initiator.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.OrganizationTransferStatusPending, pending.Status)
+
+	approve := func(username string) (ApproveOrganizationTransferTxResult, error) {
+		return store.ApproveOrganizationTransferTx(context.Background(), ApproveOrganizationTransferTxParams{
+			OrganizationTransferID: pending.ID,
+			Approver:               username,
+		})
+	}
+
+	// the initiator doesn't count towards the quorum
+	_, err = approve(initiator.Username)
+	require.ErrorIs(t, err, ErrSelfApproval)
+
+	result, err := approve(approver.Username)
+	require.NoError(t, err)
+	require.Len(t, result.Approvals, 1)
+	require.Nil(t, result.Transfer)
+	require.Equal(t, util.OrganizationTransferStatusPending, result.OrganizationTransfer.Status)
+
+	_, err = approve(approver.Username)
+	require.ErrorIs(t, err, ErrAlreadyApproved)
+
+	result, err = approve(admin.Username)
+	require.NoError(t, err)
+	require.Len(t, result.Approvals, 2)
+	require.NotNil(t, result.Transfer)
+	require.Equal(t, util.OrganizationTransferStatusExecuted, result.OrganizationTransfer.Status)
+	require.Equal(t, result.Transfer.Transfer.ID, result.OrganizationTransfer.TransferID.Int64)
+	require.Equal(t, fromAccount.Balance-500, result.Transfer.FromAccount.Balance)
+
+	_, err = approve(admin.Username)
+	require.ErrorIs(t, err, ErrOrganizationTransferNotPending)
+}
+
+func TestApproveOrganizationTransferTxFormerApprover(t *testing.T) {
+	store := NewStore(testDB)
+
+	admin := createRandomUser(t)
+	removed := createRandomUser(t)
+	demoted := createRandomUser(t)
+	approver := createRandomUser(t)
+
+	organization, err := store.CreateOrganizationTx(context.Background(), CreateOrganizationTxParams{
+		Name:              util.RandomOwner(),
+		ApprovalThreshold: 100,
+		RequiredApprovals: 2,
+		CreatedBy:         admin.Username,
+	})
+	require.NoError(t, err)
+
+	for _, user := range []User{removed, demoted, approver} {
+		_, err := testQueries.CreateOrganizationMember(context.Background(), CreateOrganizationMemberParams{
+			OrganizationID: organization.ID,
+			Username:       user.Username,
+			Role:           util.OrganizationRoleApprover,
+		})
+		require.NoError(t, err)
+	}
+
+	fromAccount, err := testQueries.CreateOrganizationAccount(context.Background(), CreateOrganizationAccountParams{
+		Owner:          admin.Username,
+		Currency:       util.USD,
+		OrganizationID: sql.NullInt64{Int64: organization.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	_, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		Amount: 1000,
+		ID:     fromAccount.ID,
+	})
+	require.NoError(t, err)
+	toAccount := createRandomAccountInCurrency(t, util.USD)
+
+	pending, err := testQueries.CreateOrganizationTransfer(context.Background(), CreateOrganizationTransferParams{
+		OrganizationID: organization.ID,
+		FromAccountID:  fromAccount.ID,
+		ToAccountID:    toAccount.ID,
+		Amount:         500,
+		InitiatedBy:    admin.Username,
+	})
+	require.NoError(t, err)
+
+	approve := func(username string) (ApproveOrganizationTransferTxResult, error) {
+		return store.ApproveOrganizationTransferTx(context.Background(), ApproveOrganizationTransferTxParams{
+			OrganizationTransferID: pending.ID,
+			Approver:               username,
+		})
+	}
+
+	for _, user := range []User{removed, demoted} {
+		_, err := approve(user.Username)
+		require.NoError(t, err)
+	}
+
+	// one approver leaves and the other is made an initiator before the quorum is met
+	err = testQueries.DeleteOrganizationMember(context.Background(), DeleteOrganizationMemberParams{
+		OrganizationID: organization.ID,
+		Username:       removed.Username,
+	})
+	require.NoError(t, err)
+	err = testQueries.DeleteOrganizationMember(context.Background(), DeleteOrganizationMemberParams{
+		OrganizationID: organization.ID,
+		Username:       demoted.Username,
+	})
+	require.NoError(t, err)
+	_, err = testQueries.CreateOrganizationMember(context.Background(), CreateOrganizationMemberParams{
+		OrganizationID: organization.ID,
+		Username:       demoted.Username,
+		Role:           util.OrganizationRoleInitiator,
+	})
+	require.NoError(t, err)
+
+	result, err := approve(approver.Username)
+	require.NoError(t, err)
+	require.Len(t, result.Approvals, 3)
+	require.Nil(t, result.Transfer)
+	require.Equal(t, util.OrganizationTransferStatusPending, result.OrganizationTransfer.Status)
+
+	count, err := testQueries.CountCurrentOrganizationTransferApprovals(context.Background(), pending.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
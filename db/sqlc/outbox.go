@@ -0,0 +1,251 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// TransferCompletedEvent is the payload of a TransferCompleted event, written for every transfer
+// including reversals and refunds. Amount is in the recipient's currency and FromAmount, what the
+// sender was debited before the fee, in the sender's.
+type TransferCompletedEvent struct {
+	TransferID         int64     `json:"transfer_id"`
+	Kind               string    `json:"kind"`
+	FromAccountID      int64     `json:"from_account_id"`
+	ToAccountID        int64     `json:"to_account_id"`
+	Amount             int64     `json:"amount"`
+	FromAmount         int64     `json:"from_amount"`
+	Fee                int64     `json:"fee"`
+	FromCurrency       string    `json:"from_currency"`
+	ToCurrency         string    `json:"to_currency"`
+	OriginalTransferID int64     `json:"original_transfer_id,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// AccountCreatedEvent is the payload of an AccountCreated event
+type AccountCreatedEvent struct {
+	AccountID      int64     `json:"account_id"`
+	Owner          string    `json:"owner"`
+	Currency       string    `json:"currency"`
+	AccountType    string    `json:"account_type"`
+	OrganizationID int64     `json:"organization_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// UserCreatedEvent is the payload of a UserCreated event, it leaves out the user's contact details
+// so they don't end up wherever events are published
+type UserCreatedEvent struct {
+	Username  string    `json:"username"`
+	Tier      string    `json:"tier"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RateUpdatedEvent is the payload of a RateUpdated event, written when a rate is created or changed
+type RateUpdatedEvent struct {
+	ExchangeRateID int64     `json:"exchange_rate_id"`
+	BaseCurrency   string    `json:"base_currency"`
+	TargetCurrency string    `json:"target_currency"`
+	ExchangeRate   string    `json:"exchange_rate"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// recordEvent writes a domain event to the outbox within an open transaction
+func recordEvent(ctx context.Context, q *Queries, eventType string, aggregateType string, aggregateID string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		EventType:     eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       data,
+	})
+	return err
+}
+
+func recordAccountCreated(ctx context.Context, q *Queries, account Account) error {
+	return recordEvent(ctx, q, util.EventAccountCreated, util.AggregateAccount, strconv.FormatInt(account.ID, 10), AccountCreatedEvent{
+		AccountID:      account.ID,
+		Owner:          account.Owner,
+		Currency:       account.Currency,
+		AccountType:    account.AccountType,
+		OrganizationID: account.OrganizationID.Int64,
+		CreatedAt:      account.CreatedAt,
+	})
+}
+
+func recordRateUpdated(ctx context.Context, q *Queries, rate ExchangeRate) error {
+	return recordEvent(ctx, q, util.EventRateUpdated, util.AggregateExchangeRate, strconv.FormatInt(rate.ID, 10), RateUpdatedEvent{
+		ExchangeRateID: rate.ID,
+		BaseCurrency:   rate.BaseCurrency,
+		TargetCurrency: rate.TargetCurrency,
+		ExchangeRate:   rate.ExchangeRate,
+		UpdatedAt:      rate.UpdatedAt,
+	})
+}
+
+// CreateAccountTx creates a personal account and records an AccountCreated event
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var account Account
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.CreateAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAccountCreated(ctx, q, account)
+	})
+
+	return account, err
+}
+
+// CreateOrganizationAccountTx creates a business account and records an AccountCreated event
+func (store *SQLStore) CreateOrganizationAccountTx(ctx context.Context, arg CreateOrganizationAccountParams) (Account, error) {
+	var account Account
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.CreateOrganizationAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAccountCreated(ctx, q, account)
+	})
+
+	return account, err
+}
+
+// CreateUserTx creates a user and records a UserCreated event
+func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error) {
+	var user User
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordEvent(ctx, q, util.EventUserCreated, util.AggregateUser, user.Username, UserCreatedEvent{
+			Username:  user.Username,
+			Tier:      user.Tier,
+			CreatedAt: user.CreatedAt,
+		})
+	})
+
+	return user, err
+}
+
+// CreateExchangeRateTx creates an exchange rate and records a RateUpdated event
+func (store *SQLStore) CreateExchangeRateTx(ctx context.Context, arg CreateExchangeRateParams) (ExchangeRate, error) {
+	var rate ExchangeRate
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		rate, err = q.CreateExchangeRate(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordRateUpdated(ctx, q, rate)
+	})
+
+	return rate, err
+}
+
+// UpdateExchangeRateTx changes an exchange rate and records a RateUpdated event
+func (store *SQLStore) UpdateExchangeRateTx(ctx context.Context, arg UpdateExchangeRateParams) (ExchangeRate, error) {
+	var rate ExchangeRate
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		rate, err = q.UpdateExchangeRate(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordRateUpdated(ctx, q, rate)
+	})
+
+	return rate, err
+}
+
+// PublishOutboxEventsParams contains the input parameters for publishing outbox events
+type PublishOutboxEventsParams struct {
+	Limit int32 `json:"limit"`
+	// an event that fails this many times is given up on and no longer published
+	MaxAttempts int32 `json:"max_attempts"`
+	// wait before a failed event is published again, multiplied by the number of failed attempts
+	RetryDelay time.Duration `json:"retry_delay"`
+}
+
+// PublishOutboxEventsResult is how many events were published and how many failed
+type PublishOutboxEventsResult struct {
+	Published int `json:"published"`
+	Failed    int `json:"failed"`
+}
+
+// PublishOutboxEvents hands up to limit unpublished events to publish in the order they were written
+// and marks each one published once publish returns. The events stay locked until the transaction
+// commits so two relays never publish the same event. An event publish fails on has its attempt
+// recorded and waits out the retry delay, or is given up on after the maximum attempts. Later events
+// of the same aggregate wait behind it so they are never published ahead of it, events of other
+// aggregates carry on.
+func (store *SQLStore) PublishOutboxEvents(ctx context.Context, arg PublishOutboxEventsParams, publish func(event OutboxEvent) error) (PublishOutboxEventsResult, error) {
+	var result PublishOutboxEventsResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		events, err := q.ListPendingOutboxEvents(ctx, arg.Limit)
+		if err != nil {
+			return err
+		}
+
+		blocked := make(map[[2]string]bool)
+		for _, event := range events {
+			aggregate := [2]string{event.AggregateType, event.AggregateID}
+			if blocked[aggregate] {
+				continue
+			}
+
+			if publishErr := publish(event); publishErr != nil {
+				blocked[aggregate] = true
+				if err := recordPublishFailure(ctx, q, arg, event, publishErr); err != nil {
+					return err
+				}
+				result.Failed++
+				continue
+			}
+
+			if err := q.MarkOutboxEventPublished(ctx, event.ID); err != nil {
+				return err
+			}
+			result.Published++
+		}
+		return nil
+	})
+	if err != nil {
+		// nothing was marked published, the events will be published again
+		return PublishOutboxEventsResult{}, err
+	}
+
+	return result, nil
+}
+
+// recordPublishFailure counts a failed attempt to publish event and gives up on it after the maximum attempts
+func recordPublishFailure(ctx context.Context, q *Queries, arg PublishOutboxEventsParams, event OutboxEvent, publishErr error) error {
+	attempts := event.Attempts + 1
+	failed := MarkOutboxEventFailedParams{
+		ID:            event.ID,
+		LastError:     publishErr.Error(),
+		NextAttemptAt: time.Now().Add(time.Duration(attempts) * arg.RetryDelay),
+	}
+	if attempts >= arg.MaxAttempts {
+		failed.FailedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+
+	return q.MarkOutboxEventFailed(ctx, failed)
+}
@@ -0,0 +1,179 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// publishAll publishes every pending event, including ones left by other tests, and returns them
+// by aggregate type and id
+func publishAll(t *testing.T, store Store) map[string]OutboxEvent {
+	published := make(map[string]OutboxEvent)
+	for {
+		result, err := store.PublishOutboxEvents(context.Background(), PublishOutboxEventsParams{Limit: 100}, func(event OutboxEvent) error {
+			published[event.AggregateType+"/"+event.AggregateID+"/"+event.EventType] = event
+			return nil
+		})
+		require.NoError(t, err)
+		if result.Published == 0 {
+			return published
+		}
+	}
+}
+
+func TestOutboxEvents(t *testing.T) {
+	store := NewStore(testDB)
+	publishAll(t, store)
+
+	user, err := store.CreateUserTx(context.Background(), CreateUserParams{
+		Username:       util.RandomOwner(),
+		HashedPassword: "secret",
+		FullName:       util.RandomOwner(),
+		Email:          util.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	account1, err := store.CreateAccountTx(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  100,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	published := publishAll(t, store)
+
+	userEvent, ok := published[util.AggregateUser+"/"+user.Username+"/"+util.EventUserCreated]
+	require.True(t, ok)
+	var userPayload UserCreatedEvent
+	require.NoError(t, json.Unmarshal(userEvent.Payload, &userPayload))
+	require.Equal(t, user.Username, userPayload.Username)
+	require.NotContains(t, string(userEvent.Payload), user.Email)
+
+	accountEvent, ok := published[util.AggregateAccount+"/"+strconv.FormatInt(account1.ID, 10)+"/"+util.EventAccountCreated]
+	require.True(t, ok)
+	var accountPayload AccountCreatedEvent
+	require.NoError(t, json.Unmarshal(accountEvent.Payload, &accountPayload))
+	require.Equal(t, account1.ID, accountPayload.AccountID)
+	require.Equal(t, util.USD, accountPayload.Currency)
+
+	transferEvent, ok := published[util.AggregateTransfer+"/"+strconv.FormatInt(result.Transfer.ID, 10)+"/"+util.EventTransferCompleted]
+	require.True(t, ok)
+	var transferPayload TransferCompletedEvent
+	require.NoError(t, json.Unmarshal(transferEvent.Payload, &transferPayload))
+	require.Equal(t, util.TransferKindTransfer, transferPayload.Kind)
+	require.Equal(t, account1.ID, transferPayload.FromAccountID)
+	require.Equal(t, int64(10), transferPayload.Amount)
+
+	// events are published in the order they were written
+	require.Less(t, userEvent.ID, accountEvent.ID)
+	require.Less(t, accountEvent.ID, transferEvent.ID)
+
+	// nothing is left to publish
+	require.Empty(t, publishAll(t, store))
+}
+
+func TestPublishOutboxEventsFailure(t *testing.T) {
+	store := NewStore(testDB)
+	publishAll(t, store)
+
+	// two events of the same exchange rate and one of an account
+	rate, err := testQueries.GetExchangeRate(context.Background(), GetExchangeRateParams{
+		BaseCurrency:   util.USD,
+		TargetCurrency: util.EUR,
+	})
+	if err == sql.ErrNoRows {
+		rate, err = testQueries.CreateExchangeRate(context.Background(), CreateExchangeRateParams{
+			BaseCurrency:   util.USD,
+			TargetCurrency: util.EUR,
+			ExchangeRate:   "0.90",
+		})
+	}
+	require.NoError(t, err)
+	for _, exchangeRate := range []string{"0.91", "0.92"} {
+		_, err = store.UpdateExchangeRateTx(context.Background(), UpdateExchangeRateParams{ID: rate.ID, ExchangeRate: exchangeRate})
+		require.NoError(t, err)
+	}
+	account, err := store.CreateAccountTx(context.Background(), CreateAccountParams{
+		Owner:    createRandomUser(t).Username,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+
+	rateID := strconv.FormatInt(rate.ID, 10)
+	arg := PublishOutboxEventsParams{Limit: 100, MaxAttempts: 2}
+	publish := func(attempted *[]OutboxEvent) func(event OutboxEvent) error {
+		return func(event OutboxEvent) error {
+			*attempted = append(*attempted, event)
+			if event.AggregateType == util.AggregateExchangeRate && event.AggregateID == rateID {
+				return errors.New("broker unavailable")
+			}
+			return nil
+		}
+	}
+
+	// the first rate event fails, the second one waits behind it and the account event goes ahead
+	var attempted []OutboxEvent
+	result, err := store.PublishOutboxEvents(context.Background(), arg, publish(&attempted))
+	require.NoError(t, err)
+	require.Equal(t, PublishOutboxEventsResult{Published: 1, Failed: 1}, result)
+	require.Len(t, attempted, 2)
+	failedID := attempted[0].ID
+	require.Equal(t, util.AggregateExchangeRate, attempted[0].AggregateType)
+	require.Equal(t, strconv.FormatInt(account.ID, 10), attempted[1].AggregateID)
+
+	// it is retried with its failed attempt recorded, and given up on after the last one
+	attempted = nil
+	result, err = store.PublishOutboxEvents(context.Background(), arg, publish(&attempted))
+	require.NoError(t, err)
+	require.Equal(t, PublishOutboxEventsResult{Failed: 1}, result)
+	require.Len(t, attempted, 1)
+	require.Equal(t, failedID, attempted[0].ID)
+	require.Equal(t, int32(1), attempted[0].Attempts)
+	require.Equal(t, "broker unavailable", attempted[0].LastError)
+
+	// the second rate event is no longer held up
+	published := publishAll(t, store)
+	event, ok := published[util.AggregateExchangeRate+"/"+rateID+"/"+util.EventRateUpdated]
+	require.True(t, ok)
+	require.Greater(t, event.ID, failedID)
+	require.Zero(t, event.Attempts)
+}
+
+func TestPublishOutboxEventsRetryDelay(t *testing.T) {
+	store := NewStore(testDB)
+	publishAll(t, store)
+
+	account, err := store.CreateAccountTx(context.Background(), CreateAccountParams{
+		Owner:    createRandomUser(t).Username,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+
+	arg := PublishOutboxEventsParams{Limit: 100, MaxAttempts: 3, RetryDelay: time.Hour}
+	result, err := store.PublishOutboxEvents(context.Background(), arg, func(event OutboxEvent) error {
+		return errors.New("broker unavailable")
+	})
+	require.NoError(t, err)
+	require.Equal(t, PublishOutboxEventsResult{Failed: 1}, result)
+
+	// the event isn't handed out again before its retry delay is up
+	published := publishAll(t, store)
+	_, ok := published[util.AggregateAccount+"/"+strconv.FormatInt(account.ID, 10)+"/"+util.EventAccountCreated]
+	require.False(t, ok)
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+var (
+	ErrPaymentRequestNotPending = errors.New("payment request is no longer pending")
+	ErrPaymentRequestExpired    = errors.New("payment request has expired")
+)
+
+// PayPaymentRequestTxParams contains the input parameters for paying a payment request
+type PayPaymentRequestTxParams struct {
+	PaymentRequestID int64 `json:"payment_request_id"`
+	FromAccountID    int64 `json:"from_account_id"`
+	Fee              int64 `json:"fee"`
+	FeeAccountID     int64 `json:"fee_account_id"`
+	// the payer, whose transfer limits the payment counts against
+	Username string `json:"username"`
+}
+
+// PayPaymentRequestTxResult contains the paid request and the transfer that paid it
+type PayPaymentRequestTxResult struct {
+	PaymentRequest PaymentRequest   `json:"payment_request"`
+	Transfer       TransferTxResult `json:"transfer"`
+}
+
+// PayPaymentRequestTx transfers the requested amount to the requester and marks the request paid,
+// the request is locked so it can't be paid twice or declined while it is being paid
+func (store *SQLStore) PayPaymentRequestTx(ctx context.Context, arg PayPaymentRequestTxParams) (PayPaymentRequestTxResult, error) {
+	var result PayPaymentRequestTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		request, err := q.GetPaymentRequestForUpdate(ctx, arg.PaymentRequestID)
+		if err != nil {
+			return err
+		}
+
+		if request.Status != util.PaymentRequestStatusPending {
+			return ErrPaymentRequestNotPending
+		}
+		if !request.ExpiresAt.After(time.Now()) {
+			return ErrPaymentRequestExpired
+		}
+
+		result.Transfer, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   request.ToAccountID,
+			Amount:        request.Amount,
+			Fee:           arg.Fee,
+			FeeAccountID:  arg.FeeAccountID,
+			Memo:          request.Memo,
+			LimitUsername: arg.Username,
+		}, util.TransferKindTransfer, sql.NullInt64{})
+		if err != nil {
+			return err
+		}
+
+		result.PaymentRequest, err = q.ClosePaymentRequest(ctx, ClosePaymentRequestParams{
+			ID:         request.ID,
+			Status:     util.PaymentRequestStatusPaid,
+			TransferID: sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
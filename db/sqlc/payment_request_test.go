@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPaymentRequest(t *testing.T, payer Account, to Account, expiresAt time.Time) PaymentRequest {
+	arg := CreatePaymentRequestParams{
+		Requester:   to.Owner,
+		Payer:       payer.Owner,
+		ToAccountID: to.ID,
+		Amount:      10,
+		Currency:    to.Currency,
+		Memo:        "concert tickets",
+		ExpiresAt:   expiresAt,
+	}
+
+	paymentRequest, err := testQueries.CreatePaymentRequest(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, util.PaymentRequestStatusPending, paymentRequest.Status)
+	require.False(t, paymentRequest.TransferID.Valid)
+
+	return paymentRequest
+}
+
+func TestPayPaymentRequestTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+	paymentRequest := createRandomPaymentRequest(t, account1, account2, time.Now().Add(time.Hour))
+
+	result, err := store.PayPaymentRequestTx(context.Background(), PayPaymentRequestTxParams{
+		PaymentRequestID: paymentRequest.ID,
+		FromAccountID:    account1.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.PaymentRequestStatusPaid, result.PaymentRequest.Status)
+	require.Equal(t, result.Transfer.Transfer.ID, result.PaymentRequest.TransferID.Int64)
+	require.Equal(t, "concert tickets", result.Transfer.Transfer.Memo)
+	require.Equal(t, account1.Balance-10, result.Transfer.FromAccount.Balance)
+	require.Equal(t, account2.Balance+10, result.Transfer.ToAccount.Balance)
+
+	// a request is only paid once
+	_, err = store.PayPaymentRequestTx(context.Background(), PayPaymentRequestTxParams{
+		PaymentRequestID: paymentRequest.ID,
+		FromAccountID:    account1.ID,
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+
+	_, err = testQueries.ClosePaymentRequest(context.Background(), ClosePaymentRequestParams{
+		ID:     paymentRequest.ID,
+		Status: util.PaymentRequestStatusDeclined,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestExpirePaymentRequests(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+	paymentRequest := createRandomPaymentRequest(t, account1, account2, time.Now().Add(-time.Minute))
+
+	_, err := store.PayPaymentRequestTx(context.Background(), PayPaymentRequestTxParams{
+		PaymentRequestID: paymentRequest.ID,
+		FromAccountID:    account1.ID,
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestExpired)
+
+	expired, err := testQueries.ExpirePaymentRequests(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, expired, int64(1))
+
+	paymentRequest, err = testQueries.GetPaymentRequest(context.Background(), paymentRequest.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.PaymentRequestStatusExpired, paymentRequest.Status)
+}
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+var (
+	ErrInsufficientPotBalance = errors.New("insufficient pot balance")
+	ErrPotClosed              = errors.New("pot is closed")
+)
+
+// MovePotMoneyTxParams contains the input parameters for moving money between an account and one of its pots.
+// A positive Amount moves money into the pot, a negative one takes it back out to the account.
+type MovePotMoneyTxParams struct {
+	PotID  int64 `json:"pot_id"`
+	Amount int64 `json:"amount"`
+}
+
+// MovePotMoneyTxResult contains the pot and account after the move and the entry recording it
+type MovePotMoneyTxResult struct {
+	Pot     Pot     `json:"pot"`
+	Account Account `json:"account"`
+	Entry   Entry   `json:"entry"`
+}
+
+// MovePotMoneyTx moves money between an account's balance and one of its pots
+func (store *SQLStore) MovePotMoneyTx(ctx context.Context, arg MovePotMoneyTxParams) (MovePotMoneyTxResult, error) {
+	var result MovePotMoneyTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		pot, err := q.GetPot(ctx, arg.PotID)
+		if err != nil {
+			return err
+		}
+
+		result, err = movePotMoney(ctx, q, pot, arg.Amount)
+		return err
+	})
+
+	return result, err
+}
+
+// movePotMoney moves amount from the pot's account into the pot, or back out when it is negative,
+// within an open transaction. Pots are a sub-ledger of the bank's pot holding account in the
+// account's currency, which takes the other side of the move's journal. The account is always
+// locked before the pot and the holding account last.
+func movePotMoney(ctx context.Context, q *Queries, pot Pot, amount int64) (MovePotMoneyTxResult, error) {
+	var result MovePotMoneyTxResult
+	var err error
+
+	result.Account, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     pot.AccountID,
+		Amount: -amount,
+	})
+	if err != nil {
+		return result, err
+	}
+	if result.Account.AvailableBalance < 0 {
+		return result, ErrInsufficientAvailableBalance
+	}
+
+	// the pot was closed since it was read
+	result.Pot, err = q.AddPotBalance(ctx, AddPotBalanceParams{
+		ID:     pot.ID,
+		Amount: amount,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return result, ErrPotClosed
+	}
+	if err != nil {
+		return result, err
+	}
+	if result.Pot.Balance < 0 {
+		return result, ErrInsufficientPotBalance
+	}
+
+	holding, err := q.GetInternalAccount(ctx, GetInternalAccountParams{
+		AccountType: util.PotHoldingAccount,
+		Currency:    result.Account.Currency,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	_, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     holding.ID,
+		Amount: amount,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	journal, err := q.CreateJournal(ctx, CreateJournalParams{
+		Kind: util.JournalKindPot,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	potID := sql.NullInt64{Int64: pot.ID, Valid: true}
+	result.Entry, err = chainEntry(ctx, q, CreateEntryParams{
+		AccountID: pot.AccountID,
+		Amount:    -amount,
+		PotID:     potID,
+		JournalID: journalID(journal),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	_, err = chainEntry(ctx, q, CreateEntryParams{
+		AccountID: holding.ID,
+		Amount:    amount,
+		PotID:     potID,
+		JournalID: journalID(journal),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// roundUp sets aside what it takes to bring an outgoing transfer up to a whole unit in the sender's
+// round-up pot, if it has one. Nothing is moved when the sender can't cover the round-up. The pot is
+// locked as it is read, after the sender, so it can't be closed before the money reaches it.
+func roundUp(ctx context.Context, q *Queries, fromAccount Account, amount int64) (Account, Entry, error) {
+	var entry Entry
+	if amount <= 0 || fromAccount.AvailableBalance < amount {
+		return fromAccount, entry, nil
+	}
+
+	pot, err := q.GetRoundUpPot(ctx, fromAccount.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fromAccount, entry, nil
+		}
+		return fromAccount, entry, err
+	}
+
+	result, err := movePotMoney(ctx, q, pot, amount)
+	return result.Account, result.Entry, err
+}
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPot(t *testing.T, account Account, roundUp bool) Pot {
+	pot, err := testQueries.CreatePot(context.Background(), CreatePotParams{
+		AccountID:    account.ID,
+		Name:         util.RandomOwner(),
+		TargetAmount: 1000,
+		RoundUp:      roundUp,
+	})
+	require.NoError(t, err)
+	require.Zero(t, pot.Balance)
+	require.False(t, pot.TargetDate.Valid)
+
+	return pot
+}
+
+func TestMovePotMoneyTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccountInCurrency(t, util.USD)
+	pot := createRandomPot(t, account, false)
+
+	result, err := store.MovePotMoneyTx(context.Background(), MovePotMoneyTxParams{
+		PotID:  pot.ID,
+		Amount: 10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(10), result.Pot.Balance)
+	require.Equal(t, account.Balance-10, result.Account.Balance)
+	require.Equal(t, int64(-10), result.Entry.Amount)
+	require.Equal(t, pot.ID, result.Entry.PotID.Int64)
+	require.False(t, result.Entry.TransferID.Valid)
+
+	// the pot holding account takes the other side of the move
+	entries := requireBalancedJournal(t, Journal{ID: result.Entry.JournalID.Int64})
+	require.Len(t, entries, 2)
+
+	// a pot can't give back more than was put in
+	_, err = store.MovePotMoneyTx(context.Background(), MovePotMoneyTxParams{
+		PotID:  pot.ID,
+		Amount: -11,
+	})
+	require.ErrorIs(t, err, ErrInsufficientPotBalance)
+
+	// nor can more be put in than the account has available
+	_, err = store.MovePotMoneyTx(context.Background(), MovePotMoneyTxParams{
+		PotID:  pot.ID,
+		Amount: account.Balance,
+	})
+	require.ErrorIs(t, err, ErrInsufficientAvailableBalance)
+
+	// only an empty pot can be closed
+	_, err = testQueries.ClosePot(context.Background(), pot.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	result, err = store.MovePotMoneyTx(context.Background(), MovePotMoneyTxParams{
+		PotID:  pot.ID,
+		Amount: -10,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Pot.Balance)
+	require.Equal(t, account.Balance, result.Account.Balance)
+
+	closed, err := testQueries.ClosePot(context.Background(), pot.ID)
+	require.NoError(t, err)
+	require.True(t, closed.ClosedAt.Valid)
+
+	_, err = testQueries.GetPot(context.Background(), pot.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestMovePotMoneyClosedPot(t *testing.T) {
+	account := createRandomAccountInCurrency(t, util.USD)
+	pot := createRandomPot(t, account, false)
+
+	// the pot is closed after a deposit read it but before the money reaches it
+	_, err := testQueries.ClosePot(context.Background(), pot.ID)
+	require.NoError(t, err)
+
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	_, err = movePotMoney(context.Background(), New(tx), pot, 10)
+	require.ErrorIs(t, err, ErrPotClosed)
+}
+
+func TestListPotTotals(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccountInCurrency(t, util.USD)
+	other := createRandomAccountInCurrency(t, util.USD)
+
+	for _, amount := range []int64{30, 20} {
+		pot := createRandomPot(t, account, false)
+		_, err := store.MovePotMoneyTx(context.Background(), MovePotMoneyTxParams{
+			PotID:  pot.ID,
+			Amount: amount,
+		})
+		require.NoError(t, err)
+	}
+	otherPot := createRandomPot(t, other, false)
+	_, err := store.MovePotMoneyTx(context.Background(), MovePotMoneyTxParams{
+		PotID:  otherPot.ID,
+		Amount: 5,
+	})
+	require.NoError(t, err)
+
+	totals, err := testQueries.ListPotTotals(context.Background(), account.Owner)
+	require.NoError(t, err)
+	require.Equal(t, []ListPotTotalsRow{{AccountID: account.ID, Balance: 50}}, totals)
+}
+
+func TestTransferTxRoundUp(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+	pot := createRandomPot(t, account1, true)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        130,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-70), result.RoundUpEntry.Amount)
+	require.Equal(t, pot.ID, result.RoundUpEntry.PotID.Int64)
+	require.Equal(t, account1.Balance-200, result.FromAccount.Balance)
+
+	pot, err = testQueries.GetPot(context.Background(), pot.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(70), pot.Balance)
+
+	// whole amounts aren't rounded up
+	result, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.RoundUpEntry.ID)
+}
+
+func TestMultiCurrencyTransferTxRoundUp(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.EUR)
+	createRandomPot(t, account1, true)
+
+	// the sender's debit in their own currency is rounded up, not the converted amount
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        120,
+		FromAmount:    130,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-70), result.RoundUpEntry.Amount)
+	require.Equal(t, account1.Balance-200, result.FromAccount.Balance)
+}
@@ -6,34 +6,173 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AcceptAccountMember(ctx context.Context, arg AcceptAccountMemberParams) (AccountMember, error)
+	AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAliasFailedAttempt(ctx context.Context, id int64) (Alias, error)
+	AddPotBalance(ctx context.Context, arg AddPotBalanceParams) (Pot, error)
+	AddTransferConfirmationFailedAttempt(ctx context.Context, id int64) (TransferConfirmation, error)
+	ClaimTransferBatch(ctx context.Context, staleBefore time.Time) (TransferBatch, error)
+	CloseOrganizationTransfer(ctx context.Context, arg CloseOrganizationTransferParams) (OrganizationTransfer, error)
+	ClosePaymentRequest(ctx context.Context, arg ClosePaymentRequestParams) (PaymentRequest, error)
+	ClosePot(ctx context.Context, id int64) (Pot, error)
+	CountCurrentOrganizationTransferApprovals(ctx context.Context, organizationTransferID int64) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountMember(ctx context.Context, arg CreateAccountMemberParams) (AccountMember, error)
+	CreateAlias(ctx context.Context, arg CreateAliasParams) (Alias, error)
+	CreateBalanceSnapshots(ctx context.Context, before time.Time) (int64, error)
+	CreateBeneficiary(ctx context.Context, arg CreateBeneficiaryParams) (Beneficiary, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
 	CreateExchangeRate(ctx context.Context, arg CreateExchangeRateParams) (ExchangeRate, error)
+	CreateFeeSchedule(ctx context.Context, arg CreateFeeScheduleParams) (FeeSchedule, error)
+	CreateFeeWaiver(ctx context.Context, arg CreateFeeWaiverParams) (FeeWaiver, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateJournal(ctx context.Context, arg CreateJournalParams) (Journal, error)
+	CreateLedgerSeal(ctx context.Context, arg CreateLedgerSealParams) (LedgerSeal, error)
+	CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Organization, error)
+	CreateOrganizationAccount(ctx context.Context, arg CreateOrganizationAccountParams) (Account, error)
+	CreateOrganizationMember(ctx context.Context, arg CreateOrganizationMemberParams) (OrganizationMember, error)
+	CreateOrganizationTransfer(ctx context.Context, arg CreateOrganizationTransferParams) (OrganizationTransfer, error)
+	CreateOrganizationTransferApproval(ctx context.Context, arg CreateOrganizationTransferApprovalParams) (OrganizationTransferApproval, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (OutboxEvent, error)
+	CreatePaymentRequest(ctx context.Context, arg CreatePaymentRequestParams) (PaymentRequest, error)
+	CreatePot(ctx context.Context, arg CreatePotParams) (Pot, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferBatch(ctx context.Context, arg CreateTransferBatchParams) (TransferBatch, error)
+	CreateTransferBatchItem(ctx context.Context, arg CreateTransferBatchItemParams) (TransferBatchItem, error)
+	CreateTransferConfirmation(ctx context.Context, arg CreateTransferConfirmationParams) (TransferConfirmation, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteAccountMember(ctx context.Context, arg DeleteAccountMemberParams) error
+	DeleteAlias(ctx context.Context, id int64) error
+	DeleteBeneficiary(ctx context.Context, id int64) error
 	DeleteExchangeRate(ctx context.Context, id int64) error
+	DeleteFeeSchedule(ctx context.Context, id int64) error
+	DeleteFeeWaiver(ctx context.Context, id int64) error
+	DeleteOrganizationMember(ctx context.Context, arg DeleteOrganizationMemberParams) error
+	DeleteScheduledTransfer(ctx context.Context, id int64) error
+	DeleteTransferConfirmation(ctx context.Context, id int64) error
+	DeleteTransferLimitOverride(ctx context.Context, arg DeleteTransferLimitOverrideParams) error
+	ExpirePaymentRequests(ctx context.Context) (int64, error)
+	FinishTransferBatch(ctx context.Context, arg FinishTransferBatchParams) (TransferBatch, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountBalanceAt(ctx context.Context, arg GetAccountBalanceAtParams) (int64, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountMember(ctx context.Context, arg GetAccountMemberParams) (AccountMember, error)
+	GetAlias(ctx context.Context, id int64) (Alias, error)
+	GetAnalyticsTotals(ctx context.Context, arg GetAnalyticsTotalsParams) (GetAnalyticsTotalsRow, error)
+	GetApplicableFeeSchedule(ctx context.Context, arg GetApplicableFeeScheduleParams) (FeeSchedule, error)
+	GetBeneficiary(ctx context.Context, id int64) (Beneficiary, error)
+	GetBeneficiaryByAccount(ctx context.Context, arg GetBeneficiaryByAccountParams) (Beneficiary, error)
+	GetBeneficiaryTransferTotal(ctx context.Context, arg GetBeneficiaryTransferTotalParams) (int64, error)
+	GetDefaultAccount(ctx context.Context, arg GetDefaultAccountParams) (Account, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
 	GetExchangeRate(ctx context.Context, arg GetExchangeRateParams) (ExchangeRate, error)
+	GetFeeWaiver(ctx context.Context, arg GetFeeWaiverParams) (FeeWaiver, error)
+	GetFirstHashedEntryTime(ctx context.Context) (time.Time, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetInternalAccount(ctx context.Context, arg GetInternalAccountParams) (Account, error)
+	GetJournal(ctx context.Context, id int64) (Journal, error)
+	GetLastBalanceSnapshot(ctx context.Context, accountID int64) (BalanceSnapshot, error)
+	GetLastEntryHash(ctx context.Context, accountID int64) ([]byte, error)
+	GetLastLedgerSeal(ctx context.Context) (LedgerSeal, error)
+	GetOrganization(ctx context.Context, id int64) (Organization, error)
+	GetOrganizationMember(ctx context.Context, arg GetOrganizationMemberParams) (OrganizationMember, error)
+	GetOrganizationTransfer(ctx context.Context, id int64) (OrganizationTransfer, error)
+	GetOrganizationTransferForUpdate(ctx context.Context, id int64) (OrganizationTransfer, error)
+	GetOutgoingTransferTotal(ctx context.Context, arg GetOutgoingTransferTotalParams) (int64, error)
+	GetPaymentRequest(ctx context.Context, id int64) (PaymentRequest, error)
+	GetPaymentRequestForUpdate(ctx context.Context, id int64) (PaymentRequest, error)
+	GetPendingHoldTotal(ctx context.Context, arg GetPendingHoldTotalParams) (int64, error)
+	GetPot(ctx context.Context, id int64) (Pot, error)
+	GetRoundUpPot(ctx context.Context, accountID int64) (Pot, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetStatementTotals(ctx context.Context, arg GetStatementTotalsParams) (GetStatementTotalsRow, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferBatch(ctx context.Context, id int64) (TransferBatch, error)
+	GetTransferBatchForUpdate(ctx context.Context, id int64) (TransferBatch, error)
+	GetTransferConfirmation(ctx context.Context, arg GetTransferConfirmationParams) (TransferConfirmation, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTransferLimit(ctx context.Context, arg GetTransferLimitParams) (TransferLimit, error)
+	GetTransferLimitOverride(ctx context.Context, arg GetTransferLimitOverrideParams) (TransferLimitOverride, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetVerifiedAlias(ctx context.Context, arg GetVerifiedAliasParams) (Alias, error)
+	ListAccountBalanceMismatches(ctx context.Context) ([]ListAccountBalanceMismatchesRow, error)
+	ListAccountInvitations(ctx context.Context, username string) ([]AccountMember, error)
+	ListAccountMembers(ctx context.Context, accountID int64) ([]AccountMember, error)
+	ListAccountTransfers(ctx context.Context, arg ListAccountTransfersParams) ([]Transfer, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAliases(ctx context.Context, owner string) ([]Alias, error)
+	ListBeneficiaries(ctx context.Context, arg ListBeneficiariesParams) ([]Beneficiary, error)
+	ListCategoryAnalytics(ctx context.Context, arg ListCategoryAnalyticsParams) ([]ListCategoryAnalyticsRow, error)
+	ListCounterpartyAnalytics(ctx context.Context, arg ListCounterpartyAnalyticsParams) ([]ListCounterpartyAnalyticsRow, error)
+	ListDueScheduledTransfers(ctx context.Context, limit int32) ([]ScheduledTransfer, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEntriesByAccountAfter(ctx context.Context, arg ListEntriesByAccountAfterParams) ([]Entry, error)
+	ListEntryHashes(ctx context.Context, arg ListEntryHashesParams) ([][]byte, error)
 	ListExchangeRates(ctx context.Context) ([]ExchangeRate, error)
-	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
-	ListTransfersByDate(ctx context.Context, arg ListTransfersByDateParams) ([]Transfer, error)
+	ListExpiredHolds(ctx context.Context, limit int32) ([]Hold, error)
+	ListFeeSchedules(ctx context.Context) ([]FeeSchedule, error)
+	ListFeeWaivers(ctx context.Context) ([]FeeWaiver, error)
+	ListHashedTransfersAfter(ctx context.Context, arg ListHashedTransfersAfterParams) ([]Transfer, error)
+	ListIncomingPaymentRequests(ctx context.Context, arg ListIncomingPaymentRequestsParams) ([]PaymentRequest, error)
+	ListInternalAccounts(ctx context.Context) ([]Account, error)
+	ListJournalEntries(ctx context.Context, journalID sql.NullInt64) ([]Entry, error)
+	ListLedgerSeals(ctx context.Context) ([]LedgerSeal, error)
+	ListMonthlyAnalytics(ctx context.Context, arg ListMonthlyAnalyticsParams) ([]ListMonthlyAnalyticsRow, error)
+	ListOrganizationAccounts(ctx context.Context, organizationID sql.NullInt64) ([]Account, error)
+	ListOrganizationMembers(ctx context.Context, organizationID int64) ([]OrganizationMember, error)
+	ListOrganizationTransferApprovals(ctx context.Context, organizationTransferID int64) ([]OrganizationTransferApproval, error)
+	ListOrganizationTransfers(ctx context.Context, arg ListOrganizationTransfersParams) ([]OrganizationTransfer, error)
+	ListOrganizations(ctx context.Context, username string) ([]Organization, error)
+	ListOutgoingPaymentRequests(ctx context.Context, arg ListOutgoingPaymentRequestsParams) ([]PaymentRequest, error)
+	ListPendingOutboxEvents(ctx context.Context, limit int32) ([]OutboxEvent, error)
+	ListPotHoldingMismatches(ctx context.Context) ([]ListPotHoldingMismatchesRow, error)
+	ListPotTotals(ctx context.Context, username string) ([]ListPotTotalsRow, error)
+	ListPots(ctx context.Context, accountID int64) ([]Pot, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error)
+	ListTransferBatchItems(ctx context.Context, batchID int64) ([]TransferBatchItem, error)
+	ListTransferEntryMismatches(ctx context.Context) ([]ListTransferEntryMismatchesRow, error)
+	ListTransferHashes(ctx context.Context, arg ListTransferHashesParams) ([][]byte, error)
+	ListTransferJournals(ctx context.Context, transferID sql.NullInt64) ([]Journal, error)
+	ListTransferLimits(ctx context.Context) ([]TransferLimit, error)
+	ListTransferReversals(ctx context.Context, originalTransferID sql.NullInt64) ([]Transfer, error)
+	ListUnbalancedJournals(ctx context.Context) ([]ListUnbalancedJournalsRow, error)
+	LockTransferLimit(ctx context.Context, username string) error
+	MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error
+	MarkOutboxEventPublished(ctx context.Context, id int64) error
+	SetDefaultAccount(ctx context.Context, arg SetDefaultAccountParams) (DefaultAccount, error)
+	SetEntryHash(ctx context.Context, arg SetEntryHashParams) (Entry, error)
+	SetTransferHash(ctx context.Context, arg SetTransferHashParams) (Transfer, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateBeneficiary(ctx context.Context, arg UpdateBeneficiaryParams) (Beneficiary, error)
 	UpdateExchangeRate(ctx context.Context, arg UpdateExchangeRateParams) (ExchangeRate, error)
+	UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error)
+	UpdatePot(ctx context.Context, arg UpdatePotParams) (Pot, error)
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateScheduledTransferRun(ctx context.Context, arg UpdateScheduledTransferRunParams) (ScheduledTransfer, error)
+	UpdateTransferBatchItem(ctx context.Context, arg UpdateTransferBatchItemParams) (TransferBatchItem, error)
+	UpdateTransferRecipientDetails(ctx context.Context, arg UpdateTransferRecipientDetailsParams) (Transfer, error)
+	UpdateTransferReversal(ctx context.Context, arg UpdateTransferReversalParams) (Transfer, error)
+	UpdateTransferSenderDetails(ctx context.Context, arg UpdateTransferSenderDetailsParams) (Transfer, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpdateUserTier(ctx context.Context, arg UpdateUserTierParams) (User, error)
+	UpsertTransferLimit(ctx context.Context, arg UpsertTransferLimitParams) (TransferLimit, error)
+	UpsertTransferLimitOverride(ctx context.Context, arg UpsertTransferLimitOverrideParams) (TransferLimitOverride, error)
+	VerifyAlias(ctx context.Context, id int64) (Alias, error)
 }
 
 var _ Querier = (*Queries)(nil)
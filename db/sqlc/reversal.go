@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+var (
+	ErrTransferNotReversible   = errors.New("transfer cannot be reversed")
+	ErrTransferAlreadyReversed = errors.New("transfer has already been reversed in full")
+	ErrReversalAmountTooLarge  = errors.New("amount exceeds what is left to reverse on the transfer")
+)
+
+// ReverseTransferTxParams contains the input parameters for reversing or refunding a transfer.
+// An Amount of 0 sends back whatever is left of the original transfer.
+type ReverseTransferTxParams struct {
+	TransferID int64  `json:"transfer_id"`
+	Amount     int64  `json:"amount"`
+	Kind       string `json:"kind"`
+}
+
+// ReverseTransferTxResult contains the updated original transfer and the compensating transfer
+type ReverseTransferTxResult struct {
+	OriginalTransfer Transfer         `json:"original_transfer"`
+	Reversal         TransferTxResult `json:"reversal"`
+}
+
+// ReverseTransferTx sends all or part of a transfer back from the recipient to the sender.
+// The original transfer is locked so it can never be reversed for more than its amount.
+// Fees are not given back. The amount is in the recipient's currency, a multi currency
+// transfer is reversed at its original rate so the sender gets back their share of what
+// they were debited, and all of it once the transfer is reversed in full.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		if original.Kind != util.TransferKindTransfer {
+			return ErrTransferNotReversible
+		}
+
+		remaining := original.Amount - original.ReversedAmount
+		if remaining <= 0 {
+			return ErrTransferAlreadyReversed
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount > remaining {
+			return ErrReversalAmountTooLarge
+		}
+
+		result.OriginalTransfer, err = q.UpdateTransferReversal(ctx, UpdateTransferReversalParams{
+			Amount: amount,
+			Status: util.ReversedTransferStatus(arg.Kind, original.Amount, original.ReversedAmount+amount),
+			ID:     original.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		// worked out from the running total so partial reversals never add up to more or less
+		// than the sender was debited
+		fromAmount := original.FromAmount * (original.ReversedAmount + amount) / original.Amount
+		fromAmount -= original.FromAmount * original.ReversedAmount / original.Amount
+
+		result.Reversal, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: original.ToAccountID,
+			ToAccountID:   original.FromAccountID,
+			Amount:        fromAmount,
+			FromAmount:    amount,
+			// keep the reference so the reversal reconciles against the original
+			Reference: original.Reference,
+		}, arg.Kind, sql.NullInt64{Int64: original.ID, Valid: true})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAccountInCurrency(t *testing.T, currency string) Account {
+	user := createRandomUser(t)
+
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  util.RandomMoney(),
+		Currency: currency,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestReverseTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+
+	amount := int64(10)
+	transferResult, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+	require.Equal(t, transferResult.Transfer.ID, transferResult.FromEntry.TransferID.Int64)
+
+	// partial reversal
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transferResult.Transfer.ID,
+		Amount:     4,
+		Kind:       util.TransferKindReversal,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), result.OriginalTransfer.ReversedAmount)
+	require.Equal(t, util.TransferStatusPartiallyReversed, result.OriginalTransfer.Status)
+
+	reversal := result.Reversal.Transfer
+	require.Equal(t, util.TransferKindReversal, reversal.Kind)
+	require.Equal(t, account2.ID, reversal.FromAccountID)
+	require.Equal(t, account1.ID, reversal.ToAccountID)
+	require.Equal(t, transferResult.Transfer.ID, reversal.OriginalTransferID.Int64)
+
+	// going over what is left fails
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transferResult.Transfer.ID,
+		Amount:     amount,
+		Kind:       util.TransferKindRefund,
+	})
+	require.ErrorIs(t, err, ErrReversalAmountTooLarge)
+
+	// refund the rest
+	result, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transferResult.Transfer.ID,
+		Kind:       util.TransferKindRefund,
+	})
+	require.NoError(t, err)
+	require.Equal(t, amount, result.OriginalTransfer.ReversedAmount)
+	require.Equal(t, util.TransferStatusRefunded, result.OriginalTransfer.Status)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transferResult.Transfer.ID,
+		Kind:       util.TransferKindReversal,
+	})
+	require.ErrorIs(t, err, ErrTransferAlreadyReversed)
+
+	// reversals themselves can't be reversed
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: reversal.ID,
+		Kind:       util.TransferKindReversal,
+	})
+	require.ErrorIs(t, err, ErrTransferNotReversible)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	reversals, err := testQueries.ListTransferReversals(context.Background(), result.Reversal.Transfer.OriginalTransferID)
+	require.NoError(t, err)
+	require.Len(t, reversals, 2)
+}
+
+func TestReverseMultiCurrencyTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.EUR)
+
+	transferResult, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        90,
+		FromAmount:    100,
+	})
+	require.NoError(t, err)
+
+	// a third of the euros gives back a third of the dollars, rounded down
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transferResult.Transfer.ID,
+		Amount:     30,
+		Kind:       util.TransferKindRefund,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-30), result.Reversal.FromEntry.Amount)
+	require.Equal(t, int64(33), result.Reversal.ToEntry.Amount)
+	requireBalancedJournal(t, result.Reversal.Journal)
+
+	// the rest gives back everything the sender was debited
+	result, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transferResult.Transfer.ID,
+		Kind:       util.TransferKindRefund,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-60), result.Reversal.FromEntry.Amount)
+	require.Equal(t, int64(67), result.Reversal.ToEntry.Amount)
+	require.Equal(t, util.TransferStatusRefunded, result.OriginalTransfer.Status)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+}
+
+func TestReverseTransferTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.EUR)
+	account2 := createRandomAccountInCurrency(t, util.EUR)
+
+	transferResult, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	n := 5
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+				TransferID: transferResult.Transfer.ID,
+				Kind:       util.TransferKindReversal,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrTransferAlreadyReversed)
+	}
+	require.Equal(t, 1, succeeded)
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+var ErrScheduledTransferNotDue = errors.New("scheduled transfer is no longer due")
+
+// ExecuteScheduledTransferTxParams contains the input parameters for running a scheduled transfer.
+// ScheduledTransfer is the schedule as it was when it was found due, Run is what it is moved on to
+// once the transfer is made.
+type ExecuteScheduledTransferTxParams struct {
+	ScheduledTransfer ScheduledTransfer                `json:"scheduled_transfer"`
+	Transfer          TransferTxParams                 `json:"transfer"`
+	Run               UpdateScheduledTransferRunParams `json:"run"`
+}
+
+// ExecuteScheduledTransferTxResult contains the schedule after the run and the transfer it made
+type ExecuteScheduledTransferTxResult struct {
+	ScheduledTransfer ScheduledTransfer `json:"scheduled_transfer"`
+	Transfer          TransferTxResult  `json:"transfer"`
+}
+
+// ExecuteScheduledTransferTx makes a scheduled transfer and moves its schedule on in one transaction.
+// The schedule is locked first, so a run that was already made, or a schedule that was paused or
+// changed since it was found due, is never paid out again.
+func (store *SQLStore) ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error) {
+	var result ExecuteScheduledTransferTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		scheduledTransfer, err := q.GetScheduledTransferForUpdate(ctx, arg.ScheduledTransfer.ID)
+		if err != nil {
+			return err
+		}
+
+		if scheduledTransfer.Status != util.ScheduleStatusActive ||
+			scheduledTransfer.Amount != arg.ScheduledTransfer.Amount ||
+			!scheduledTransfer.NextRunAt.Equal(arg.ScheduledTransfer.NextRunAt) ||
+			scheduledTransfer.RetryAt.Valid != arg.ScheduledTransfer.RetryAt.Valid ||
+			!scheduledTransfer.RetryAt.Time.Equal(arg.ScheduledTransfer.RetryAt.Time) {
+			return ErrScheduledTransferNotDue
+		}
+
+		result.Transfer, err = transfer(ctx, q, arg.Transfer, util.TransferKindTransfer, sql.NullInt64{})
+		if err != nil {
+			return err
+		}
+
+		run := arg.Run
+		run.LastTransferID = sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true}
+		result.ScheduledTransfer, err = q.UpdateScheduledTransferRun(ctx, run)
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomScheduledTransfer(t *testing.T, nextRunAt time.Time) ScheduledTransfer {
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+
+	arg := CreateScheduledTransferParams{
+		Owner:         account1.Owner,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        util.RandomMoney(),
+		Frequency:     util.FrequencyDaily,
+		NextRunAt:     nextRunAt,
+	}
+	scheduledTransfer, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Owner, scheduledTransfer.Owner)
+	require.Equal(t, util.ScheduleStatusActive, scheduledTransfer.Status)
+	require.WithinDuration(t, arg.NextRunAt, scheduledTransfer.NextRunAt, time.Second)
+
+	return scheduledTransfer
+}
+
+func TestListDueScheduledTransfers(t *testing.T) {
+	due := createRandomScheduledTransfer(t, time.Now().Add(-time.Minute))
+	notDue := createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+
+	// a failed run waiting for its retry isn't due yet
+	retrying := createRandomScheduledTransfer(t, time.Now().Add(-time.Minute))
+	_, err := testQueries.UpdateScheduledTransferRun(context.Background(), UpdateScheduledTransferRunParams{
+		ID:             retrying.ID,
+		NextRunAt:      retrying.NextRunAt,
+		RetryAt:        sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+		Status:         util.ScheduleStatusActive,
+		FailedAttempts: 1,
+	})
+	require.NoError(t, err)
+
+	scheduledTransfers, err := testQueries.ListDueScheduledTransfers(context.Background(), 1000)
+	require.NoError(t, err)
+
+	ids := make(map[int64]bool)
+	for _, scheduledTransfer := range scheduledTransfers {
+		ids[scheduledTransfer.ID] = true
+	}
+	require.True(t, ids[due.ID])
+	require.False(t, ids[notDue.ID])
+	require.False(t, ids[retrying.ID])
+}
+
+func TestExecuteScheduledTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	scheduledTransfer := createRandomScheduledTransfer(t, time.Now().Add(-time.Minute))
+	now := time.Now()
+
+	arg := ExecuteScheduledTransferTxParams{
+		ScheduledTransfer: scheduledTransfer,
+		Transfer: TransferTxParams{
+			FromAccountID: scheduledTransfer.FromAccountID,
+			ToAccountID:   scheduledTransfer.ToAccountID,
+			Amount:        1,
+		},
+		Run: UpdateScheduledTransferRunParams{
+			ID:        scheduledTransfer.ID,
+			NextRunAt: scheduledTransfer.NextRunAt.Add(24 * time.Hour),
+			Status:    util.ScheduleStatusActive,
+			LastRunAt: sql.NullTime{Time: now, Valid: true},
+		},
+	}
+	result, err := store.ExecuteScheduledTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, result.Transfer.Transfer.ID, result.ScheduledTransfer.LastTransferID.Int64)
+	require.WithinDuration(t, arg.Run.NextRunAt, result.ScheduledTransfer.NextRunAt, time.Second)
+
+	// the same run can't be made twice
+	_, err = store.ExecuteScheduledTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrScheduledTransferNotDue)
+
+	updated, err := testQueries.GetScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Transfer.Transfer.ID, updated.LastTransferID.Int64)
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// AccountStatementParams contains the input parameters for an account statement,
+// which covers entries created from Since up to but not including Until
+type AccountStatementParams struct {
+	AccountID int64     `json:"account_id"`
+	Since     time.Time `json:"since"`
+	Until     time.Time `json:"until"`
+}
+
+// StatementLine is an entry of the account with the balance right after it was posted
+type StatementLine struct {
+	ListStatementEntriesRow
+	Balance int64 `json:"balance"`
+}
+
+// AccountStatement lists every entry of an account over a period, so fees, pot moves and journals
+// show up next to transfers. Debits are totalled as a positive amount, the closing balance is the
+// opening balance plus credits minus debits.
+type AccountStatement struct {
+	AccountID      int64           `json:"account_id"`
+	Currency       string          `json:"currency"`
+	Since          time.Time       `json:"since"`
+	Until          time.Time       `json:"until"`
+	OpeningBalance int64           `json:"opening_balance"`
+	TotalCredits   int64           `json:"total_credits"`
+	TotalDebits    int64           `json:"total_debits"`
+	ClosingBalance int64           `json:"closing_balance"`
+	Lines          []StatementLine `json:"lines"`
+}
+
+// number of statement lines read from the database at a time
+const statementPageSize = 500
+
+// AccountStatement builds the statement of an account from its entries
+func (store *SQLStore) AccountStatement(ctx context.Context, arg AccountStatementParams) (AccountStatement, error) {
+	var statement AccountStatement
+	err := store.StreamAccountStatement(ctx, arg, func(header AccountStatement) error {
+		statement = header
+		statement.Lines = []StatementLine{}
+		return nil
+	}, func(line StatementLine) error {
+		statement.Lines = append(statement.Lines, line)
+		return nil
+	})
+
+	return statement, err
+}
+
+// StreamAccountStatement passes the statement without its lines to begin, then hands its lines to
+// line one by one as they are read a page at a time, so long statements never sit in memory.
+// Everything is read from the same snapshot so the lines always add up to the closing balance.
+func (store *SQLStore) StreamAccountStatement(
+	ctx context.Context,
+	arg AccountStatementParams,
+	begin func(header AccountStatement) error,
+	line func(line StatementLine) error,
+) error {
+	tx, err := store.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	q := New(tx)
+
+	account, err := q.GetAccount(ctx, arg.AccountID)
+	if err != nil {
+		return err
+	}
+
+	header := AccountStatement{
+		AccountID: arg.AccountID,
+		Currency:  account.Currency,
+		Since:     arg.Since,
+		Until:     arg.Until,
+	}
+
+	header.OpeningBalance, err = q.GetAccountBalanceAt(ctx, GetAccountBalanceAtParams{
+		AccountID: arg.AccountID,
+		At:        arg.Since,
+	})
+	if err != nil {
+		return err
+	}
+
+	totals, err := q.GetStatementTotals(ctx, GetStatementTotalsParams{
+		AccountID: arg.AccountID,
+		Since:     arg.Since,
+		Until:     arg.Until,
+	})
+	if err != nil {
+		return err
+	}
+	header.TotalCredits = totals.TotalCredits
+	header.TotalDebits = totals.TotalDebits
+	header.ClosingBalance = header.OpeningBalance + totals.TotalCredits - totals.TotalDebits
+
+	if err := begin(header); err != nil {
+		return err
+	}
+
+	balance := header.OpeningBalance
+	page := ListStatementEntriesParams{
+		AccountID: arg.AccountID,
+		Since:     arg.Since,
+		Until:     arg.Until,
+		PageLimit: statementPageSize,
+	}
+	for {
+		entries, err := q.ListStatementEntries(ctx, page)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			balance += entry.Amount
+			err := line(StatementLine{
+				ListStatementEntriesRow: entry,
+				Balance:                 balance,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < statementPageSize {
+			return nil
+		}
+		page.AfterID = entries[len(entries)-1].ID
+	}
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountStatement(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+	feeAccount, err := testQueries.GetInternalAccount(context.Background(), GetInternalAccountParams{
+		AccountType: util.FeeIncomeAccount,
+		Currency:    util.USD,
+	})
+	require.NoError(t, err)
+
+	first, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	second, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        4,
+	})
+	require.NoError(t, err)
+
+	third, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        20,
+		Fee:           1,
+		FeeAccountID:  feeAccount.ID,
+	})
+	require.NoError(t, err)
+
+	// the first transfer is before the statement starts
+	statement, err := store.AccountStatement(context.Background(), AccountStatementParams{
+		AccountID: account1.ID,
+		Since:     second.ToEntry.CreatedAt,
+		Until:     third.FeeEntry.CreatedAt.Add(time.Microsecond),
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.USD, statement.Currency)
+	require.Equal(t, first.FromEntry.Amount, statement.OpeningBalance)
+	require.Equal(t, int64(4), statement.TotalCredits)
+	require.Equal(t, int64(21), statement.TotalDebits)
+	require.Equal(t, int64(-10+4-21), statement.ClosingBalance)
+
+	require.Len(t, statement.Lines, 3)
+	require.Equal(t, second.ToEntry.ID, statement.Lines[0].ID)
+	require.Equal(t, util.TransferKindTransfer, statement.Lines[0].LineType)
+	require.Equal(t, account2.ID, statement.Lines[0].CounterpartyAccountID)
+	require.Equal(t, int64(-6), statement.Lines[0].Balance)
+
+	require.Equal(t, third.FromEntry.ID, statement.Lines[1].ID)
+	require.Equal(t, util.TransferKindTransfer, statement.Lines[1].LineType)
+	require.Equal(t, int64(-26), statement.Lines[1].Balance)
+
+	require.Equal(t, third.FeeEntry.ID, statement.Lines[2].ID)
+	require.Equal(t, util.StatementLineFee, statement.Lines[2].LineType)
+	require.Equal(t, statement.ClosingBalance, statement.Lines[2].Balance)
+}
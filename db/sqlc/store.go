@@ -2,14 +2,46 @@ package db
 
 import (
 	"context"
+	"crypto/ed25519"
 	"database/sql"
 	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/malcolmmaima/maimabank/util"
 )
 
 // Store provides all functions to execute db queries and transactions
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	QuoteFee(ctx context.Context, arg QuoteFeeParams) (FeeQuote, error)
+	GetTransferAllowance(ctx context.Context, arg GetTransferAllowanceParams) (TransferAllowance, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	CreateHoldTx(ctx context.Context, arg CreateHoldTxParams) (CreateHoldTxResult, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error)
+	VoidHoldTx(ctx context.Context, holdID int64) (Hold, error)
+	ExpireHolds(ctx context.Context, limit int32) (int, error)
+	CreateTransferBatchTx(ctx context.Context, arg CreateTransferBatchTxParams) (TransferBatchResult, error)
+	ExecuteAtomicTransferBatchTx(ctx context.Context, batch TransferBatch, transfers []BatchTransfer) (TransferBatch, error)
+	ExecuteTransferBatchItemTx(ctx context.Context, batch TransferBatch, batchTransfer BatchTransfer) (TransferTxResult, error)
+	PayPaymentRequestTx(ctx context.Context, arg PayPaymentRequestTxParams) (PayPaymentRequestTxResult, error)
+	CreateOrganizationTx(ctx context.Context, arg CreateOrganizationTxParams) (Organization, error)
+	ApproveOrganizationTransferTx(ctx context.Context, arg ApproveOrganizationTransferTxParams) (ApproveOrganizationTransferTxResult, error)
+	MovePotMoneyTx(ctx context.Context, arg MovePotMoneyTxParams) (MovePotMoneyTxResult, error)
+	PostJournalTx(ctx context.Context, arg PostJournalTxParams) (JournalResult, error)
+	VerifyLedger(ctx context.Context, publicKey ed25519.PublicKey) (LedgerReport, error)
+	SealLedgerDay(ctx context.Context, day time.Time, key ed25519.PrivateKey) (LedgerSeal, error)
+	AccountStatement(ctx context.Context, arg AccountStatementParams) (AccountStatement, error)
+	StreamAccountStatement(ctx context.Context, arg AccountStatementParams, begin func(header AccountStatement) error, line func(line StatementLine) error) error
+	AccountAnalytics(ctx context.Context, arg AccountAnalyticsParams) (AccountAnalytics, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateOrganizationAccountTx(ctx context.Context, arg CreateOrganizationAccountParams) (Account, error)
+	CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateExchangeRateTx(ctx context.Context, arg CreateExchangeRateParams) (ExchangeRate, error)
+	UpdateExchangeRateTx(ctx context.Context, arg UpdateExchangeRateParams) (ExchangeRate, error)
+	ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error)
+	PublishOutboxEvents(ctx context.Context, arg PublishOutboxEventsParams, publish func(event OutboxEvent) error) (PublishOutboxEventsResult, error)
 }
 
 // SQLStore provides all functions to execute db queries and transactions
@@ -48,15 +80,35 @@ type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	Fee           int64 `json:"fee"`
+	FeeAccountID  int64 `json:"fee_account_id"`
+	// debited from the sender of a multi currency transfer, in the sender's currency where
+	// Amount is in the recipient's. Left at 0 the sender is debited Amount.
+	FromAmount int64 `json:"from_amount"`
+	// optional details shown on statements
+	Memo                 string `json:"memo"`
+	Reference            string `json:"reference"`
+	SenderDescription    string `json:"sender_description"`
+	RecipientDescription string `json:"recipient_description"`
+	SenderCategory       string `json:"sender_category"`
+	// the user who started the transfer, recorded on it and checked against their transfer limits
+	// within the transaction. Left empty no limits are checked.
+	LimitUsername string `json:"limit_username"`
+	// already reserved on the sender's available balance for this transfer by a hold
+	Held int64 `json:"held"`
 }
 
 // TransferTxResult contains the result of the transfer transaction
 type TransferTxResult struct {
 	Transfer    Transfer `json:"transfer"`
+	Journal     Journal  `json:"journal"`
 	FromAccount Account  `json:"from_account"`
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	FeeEntry    Entry    `json:"fee_entry"`
+	// set when the sender has a round-up pot
+	RoundUpEntry Entry `json:"round_up_entry"`
 }
 
 
@@ -65,43 +117,176 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 	var result TransferTxResult
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
+		result, err = transfer(ctx, q, arg, util.TransferKindTransfer, sql.NullInt64{})
+		return err
+	})
+
+	return result, err
+}
+
+// transfer moves money within an open transaction, recording the kind of transfer
+// and the transfer it compensates if it is a reversal or refund
+func transfer(ctx context.Context, q *Queries, arg TransferTxParams, kind string, originalTransferID sql.NullInt64) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
+
+	fromAmount := arg.Amount
+	if arg.FromAmount > 0 {
+		fromAmount = arg.FromAmount
+	}
+
+	// a captured hold was checked against the limits when it was placed
+	if arg.LimitUsername != "" && arg.Held == 0 {
+		err = checkTransferLimit(ctx, q, arg.LimitUsername, arg.FromAccountID, fromAmount)
+		if err != nil {
+			return result, err
+		}
+
+		err = checkBeneficiaryLimit(ctx, q, arg.LimitUsername, arg.ToAccountID, arg.Amount)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID:        arg.FromAccountID,
+		ToAccountID:          arg.ToAccountID,
+		Amount:               arg.Amount,
+		FromAmount:           fromAmount,
+		Fee:                  arg.Fee,
+		Kind:                 kind,
+		OriginalTransferID:   originalTransferID,
+		Memo:                 arg.Memo,
+		Reference:            arg.Reference,
+		SenderDescription:    arg.SenderDescription,
+		RecipientDescription: arg.RecipientDescription,
+		SenderCategory:       arg.SenderCategory,
+		InitiatedBy:          arg.LimitUsername,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Transfer, err = q.SetTransferHash(ctx, SetTransferHashParams{
+		ID:   result.Transfer.ID,
+		Hash: TransferHash(result.Transfer),
+	})
+	if err != nil {
+		return result, err
+	}
+	transferID := sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+
+	// every entry of the transfer, fee and FX legs included, belongs to one balanced journal
+	result.Journal, err = q.CreateJournal(ctx, CreateJournalParams{
+		Kind:       kind,
+		TransferID: transferID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// balances are updated before the entries are written so each account is locked
+	// before its entry is chained to the previous one
+	debit := fromAmount + arg.Fee
+	if arg.FromAccountID < arg.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -debit, arg.ToAccountID, arg.Amount)
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -debit)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	// the sender can't spend money that is held for other transfers
+	if !util.IsInternalAccountType(result.FromAccount.AccountType) && result.FromAccount.AvailableBalance+arg.Held < 0 {
+		return result, ErrInsufficientAvailableBalance
+	}
 
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
+	result.FromEntry, err = chainEntry(ctx, q, CreateEntryParams{
+		AccountID:  arg.FromAccountID,
+		Amount:     -fromAmount,
+		TransferID: transferID,
+		JournalID:  journalID(result.Journal),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.ToEntry, err = chainEntry(ctx, q, CreateEntryParams{
+		AccountID:  arg.ToAccountID,
+		Amount:     arg.Amount,
+		TransferID: transferID,
+		JournalID:  journalID(result.Journal),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// the fee is debited from the sender as its own entry and credited to the fee income account,
+	// which is always locked last so concurrent transfers can't deadlock on it
+	if arg.Fee > 0 {
+		result.FeeEntry, err = chainEntry(ctx, q, CreateEntryParams{
+			AccountID:  arg.FromAccountID,
+			Amount:     -arg.Fee,
+			TransferID: transferID,
+			JournalID:  journalID(result.Journal),
 		})
 		if err != nil {
-			return err
+			return result, err
 		}
-		
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
+
+		_, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     arg.FeeAccountID,
+			Amount: arg.Fee,
 		})
 		if err != nil {
-			return err
+			return result, err
 		}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+		_, err = chainEntry(ctx, q, CreateEntryParams{
+			AccountID:  arg.FeeAccountID,
+			Amount:     arg.Fee,
+			TransferID: transferID,
+			JournalID:  journalID(result.Journal),
 		})
 		if err != nil {
-			return err
+			return result, err
 		}
+	}
 
+	if result.FromAccount.Currency != result.ToAccount.Currency {
+		err = postFX(ctx, q, result.Journal, result.FromAccount.Currency, fromAmount, result.ToAccount.Currency, arg.Amount)
+		if err != nil {
+			return result, err
+		}
+	}
 
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+	// only payments are rounded up, not reversals or refunds of them
+	if kind == util.TransferKindTransfer {
+		result.FromAccount, result.RoundUpEntry, err = roundUp(ctx, q, result.FromAccount, util.RoundUp(fromAmount))
+		if err != nil {
+			return result, err
 		}
-		
-		return nil
+	}
+
+	err = recordEvent(ctx, q, util.EventTransferCompleted, util.AggregateTransfer, strconv.FormatInt(result.Transfer.ID, 10), TransferCompletedEvent{
+		TransferID:         result.Transfer.ID,
+		Kind:               kind,
+		FromAccountID:      arg.FromAccountID,
+		ToAccountID:        arg.ToAccountID,
+		Amount:             arg.Amount,
+		FromAmount:         fromAmount,
+		Fee:                arg.Fee,
+		FromCurrency:       result.FromAccount.Currency,
+		ToCurrency:         result.ToAccount.Currency,
+		OriginalTransferID: originalTransferID.Int64,
+		CreatedAt:          result.Transfer.CreatedAt,
 	})
+	if err != nil {
+		return result, err
+	}
 
-	return result, err
+	return result, nil
 }
 
 func addMoney(
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/malcolmmaima/maimabank/util"
 	"github.com/stretchr/testify/require"
 )
 
@@ -160,4 +161,39 @@ func TestTransferTxDeadlock(t *testing.T) {
 
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
-}
\ No newline at end of file
+}
+func TestTransferTxWithFee(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	feeAccount, err := testQueries.GetInternalAccount(context.Background(), GetInternalAccountParams{
+		AccountType: util.FeeIncomeAccount,
+		Currency:    account1.Currency,
+	})
+	require.NoError(t, err)
+
+	amount := int64(10)
+	fee := int64(2)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		Fee:           fee,
+		FeeAccountID:  feeAccount.ID,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, fee, result.Transfer.Fee)
+	require.Equal(t, account1.ID, result.FeeEntry.AccountID)
+	require.Equal(t, -fee, result.FeeEntry.Amount)
+
+	require.Equal(t, account1.Balance-amount-fee, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+amount, result.ToAccount.Balance)
+
+	updatedFeeAccount, err := testQueries.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, feeAccount.Balance+fee, updatedFeeAccount.Balance)
+}
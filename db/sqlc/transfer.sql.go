@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
@@ -14,20 +15,53 @@ const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  fee,
+  kind,
+  original_transfer_id,
+  memo,
+  reference,
+  sender_description,
+  recipient_description,
+  sender_category,
+  from_amount,
+  initiated_by
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+) RETURNING id, from_account_id, to_account_id, amount, created_at, fee, kind, status, original_transfer_id, reversed_amount, memo, reference, sender_description, recipient_description, sender_category, recipient_category, hash, from_amount, initiated_by
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID        int64         `json:"from_account_id"`
+	ToAccountID          int64         `json:"to_account_id"`
+	Amount               int64         `json:"amount"`
+	Fee                  int64         `json:"fee"`
+	Kind                 string        `json:"kind"`
+	OriginalTransferID   sql.NullInt64 `json:"original_transfer_id"`
+	Memo                 string        `json:"memo"`
+	Reference            string        `json:"reference"`
+	SenderDescription    string        `json:"sender_description"`
+	RecipientDescription string        `json:"recipient_description"`
+	SenderCategory       string        `json:"sender_category"`
+	FromAmount           int64         `json:"from_amount"`
+	InitiatedBy          string        `json:"initiated_by"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Fee,
+		arg.Kind,
+		arg.OriginalTransferID,
+		arg.Memo,
+		arg.Reference,
+		arg.SenderDescription,
+		arg.RecipientDescription,
+		arg.SenderCategory,
+		arg.FromAmount,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -35,12 +69,26 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Fee,
+		&i.Kind,
+		&i.Status,
+		&i.OriginalTransferID,
+		&i.ReversedAmount,
+		&i.Memo,
+		&i.Reference,
+		&i.SenderDescription,
+		&i.RecipientDescription,
+		&i.SenderCategory,
+		&i.RecipientCategory,
+		&i.Hash,
+		&i.FromAmount,
+		&i.InitiatedBy,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, kind, status, original_transfer_id, reversed_amount, memo, reference, sender_description, recipient_description, sender_category, recipient_category, hash, from_amount, initiated_by FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -53,33 +101,133 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Fee,
+		&i.Kind,
+		&i.Status,
+		&i.OriginalTransferID,
+		&i.ReversedAmount,
+		&i.Memo,
+		&i.Reference,
+		&i.SenderDescription,
+		&i.RecipientDescription,
+		&i.SenderCategory,
+		&i.RecipientCategory,
+		&i.Hash,
+		&i.FromAmount,
+		&i.InitiatedBy,
 	)
 	return i, err
 }
 
-const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
-WHERE 
-    from_account_id = $1 OR
-    to_account_id = $2
-ORDER BY id
-LIMIT $3
-OFFSET $4
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, kind, status, original_transfer_id, reversed_amount, memo, reference, sender_description, recipient_description, sender_category, recipient_category, hash, from_amount, initiated_by FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
 `
 
-type ListTransfersParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Limit         int32 `json:"limit"`
-	Offset        int32 `json:"offset"`
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Fee,
+		&i.Kind,
+		&i.Status,
+		&i.OriginalTransferID,
+		&i.ReversedAmount,
+		&i.Memo,
+		&i.Reference,
+		&i.SenderDescription,
+		&i.RecipientDescription,
+		&i.SenderCategory,
+		&i.RecipientCategory,
+		&i.Hash,
+		&i.FromAmount,
+		&i.InitiatedBy,
+	)
+	return i, err
 }
 
-func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
-	rows, err := q.db.QueryContext(ctx, listTransfers,
-		arg.FromAccountID,
-		arg.ToAccountID,
-		arg.Limit,
-		arg.Offset,
+const listAccountTransfers = `-- name: ListAccountTransfers :many
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, kind, status, original_transfer_id, reversed_amount, memo, reference, sender_description, recipient_description, sender_category, recipient_category, hash, from_amount, initiated_by FROM transfers
+WHERE
+    (from_account_id = $1 OR to_account_id = $1)
+    AND (
+        $2::text = '' OR
+        ($2 = 'debit' AND from_account_id = $1) OR
+        ($2 = 'credit' AND to_account_id = $1)
+    )
+    AND (
+        $3::bigint = 0 OR
+        (from_account_id = $1 AND to_account_id = $3) OR
+        (to_account_id = $1 AND from_account_id = $3)
+    )
+    AND (
+        $4::bigint = 0 OR
+        CASE WHEN from_account_id = $1 THEN from_amount ELSE amount END >= $4
+    )
+    AND (
+        $5::bigint = 0 OR
+        CASE WHEN from_account_id = $1 THEN from_amount ELSE amount END <= $5
+    )
+    AND (
+        $6::text = '' OR
+        memo ILIKE '%' || $6 || '%' OR
+        reference ILIKE '%' || $6 || '%' OR
+        (from_account_id = $1 AND sender_description ILIKE '%' || $6 || '%') OR
+        (to_account_id = $1 AND recipient_description ILIKE '%' || $6 || '%')
+    )
+    AND (
+        $7::text = '' OR
+        (from_account_id = $1 AND sender_category = $7) OR
+        (to_account_id = $1 AND recipient_category = $7)
+    )
+    AND ($8::timestamptz IS NULL OR created_at >= $8)
+    AND ($9::timestamptz IS NULL OR created_at < $9)
+    AND (
+        $10::bigint = 0 OR
+        ($11::bool AND id < $10) OR
+        (NOT $11::bool AND id > $10)
+    )
+ORDER BY
+    CASE WHEN $11::bool THEN id END DESC,
+    id
+LIMIT $12
+`
+
+type ListAccountTransfersParams struct {
+	AccountID             int64        `json:"account_id"`
+	Direction             string       `json:"direction"`
+	CounterpartyAccountID int64        `json:"counterparty_account_id"`
+	MinAmount             int64        `json:"min_amount"`
+	MaxAmount             int64        `json:"max_amount"`
+	Query                 string       `json:"query"`
+	Category              string       `json:"category"`
+	StartDate             sql.NullTime `json:"start_date"`
+	EndDate               sql.NullTime `json:"end_date"`
+	AfterID               int64        `json:"after_id"`
+	Descending            bool         `json:"descending"`
+	PageLimit             int32        `json:"page_limit"`
+}
+
+func (q *Queries) ListAccountTransfers(ctx context.Context, arg ListAccountTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountTransfers,
+		arg.AccountID,
+		arg.Direction,
+		arg.CounterpartyAccountID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Query,
+		arg.Category,
+		arg.StartDate,
+		arg.EndDate,
+		arg.AfterID,
+		arg.Descending,
+		arg.PageLimit,
 	)
 	if err != nil {
 		return nil, err
@@ -94,6 +242,20 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Fee,
+			&i.Kind,
+			&i.Status,
+			&i.OriginalTransferID,
+			&i.ReversedAmount,
+			&i.Memo,
+			&i.Reference,
+			&i.SenderDescription,
+			&i.RecipientDescription,
+			&i.SenderCategory,
+			&i.RecipientCategory,
+			&i.Hash,
+			&i.FromAmount,
+			&i.InitiatedBy,
 		); err != nil {
 			return nil, err
 		}
@@ -108,35 +270,21 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 	return items, nil
 }
 
-const listTransfersByDate = `-- name: ListTransfersByDate :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
-WHERE 
-    (from_account_id = $1 OR to_account_id = $2)
-    AND created_at >= $3
-    AND created_at <= $4
+const listHashedTransfersAfter = `-- name: ListHashedTransfersAfter :many
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, kind, status, original_transfer_id, reversed_amount, memo, reference, sender_description, recipient_description, sender_category, recipient_category, hash, from_amount, initiated_by FROM transfers
+WHERE id > $1
+AND hash IS NOT NULL
 ORDER BY id
-LIMIT $5
-OFFSET $6
+LIMIT $2
 `
 
-type ListTransfersByDateParams struct {
-	FromAccountID int64     `json:"from_account_id"`
-	ToAccountID   int64     `json:"to_account_id"`
-	CreatedAt     time.Time `json:"created_at"`
-	CreatedAt_2   time.Time `json:"created_at_2"`
-	Limit         int32     `json:"limit"`
-	Offset        int32     `json:"offset"`
+type ListHashedTransfersAfterParams struct {
+	ID    int64 `json:"id"`
+	Limit int32 `json:"limit"`
 }
 
-func (q *Queries) ListTransfersByDate(ctx context.Context, arg ListTransfersByDateParams) ([]Transfer, error) {
-	rows, err := q.db.QueryContext(ctx, listTransfersByDate,
-		arg.FromAccountID,
-		arg.ToAccountID,
-		arg.CreatedAt,
-		arg.CreatedAt_2,
-		arg.Limit,
-		arg.Offset,
-	)
+func (q *Queries) ListHashedTransfersAfter(ctx context.Context, arg ListHashedTransfersAfterParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listHashedTransfersAfter, arg.ID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -150,6 +298,20 @@ func (q *Queries) ListTransfersByDate(ctx context.Context, arg ListTransfersByDa
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Fee,
+			&i.Kind,
+			&i.Status,
+			&i.OriginalTransferID,
+			&i.ReversedAmount,
+			&i.Memo,
+			&i.Reference,
+			&i.SenderDescription,
+			&i.RecipientDescription,
+			&i.SenderCategory,
+			&i.RecipientCategory,
+			&i.Hash,
+			&i.FromAmount,
+			&i.InitiatedBy,
 		); err != nil {
 			return nil, err
 		}
@@ -163,3 +325,250 @@ func (q *Queries) ListTransfersByDate(ctx context.Context, arg ListTransfersByDa
 	}
 	return items, nil
 }
+
+const listTransferHashes = `-- name: ListTransferHashes :many
+SELECT hash FROM transfers
+WHERE created_at >= $1
+AND created_at < $2
+AND hash IS NOT NULL
+ORDER BY id
+`
+
+type ListTransferHashesParams struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+}
+
+func (q *Queries) ListTransferHashes(ctx context.Context, arg ListTransferHashesParams) ([][]byte, error) {
+	rows, err := q.db.QueryContext(ctx, listTransferHashes, arg.Since, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := [][]byte{}
+	for rows.Next() {
+		var hash []byte
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		items = append(items, hash)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransferReversals = `-- name: ListTransferReversals :many
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, kind, status, original_transfer_id, reversed_amount, memo, reference, sender_description, recipient_description, sender_category, recipient_category, hash, from_amount, initiated_by FROM transfers
+WHERE original_transfer_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListTransferReversals(ctx context.Context, originalTransferID sql.NullInt64) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listTransferReversals, originalTransferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Fee,
+			&i.Kind,
+			&i.Status,
+			&i.OriginalTransferID,
+			&i.ReversedAmount,
+			&i.Memo,
+			&i.Reference,
+			&i.SenderDescription,
+			&i.RecipientDescription,
+			&i.SenderCategory,
+			&i.RecipientCategory,
+			&i.Hash,
+			&i.FromAmount,
+			&i.InitiatedBy,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setTransferHash = `-- name: SetTransferHash :one
+UPDATE transfers
+SET hash = $2
+WHERE id = $1
+RETURNING id, from_account_id, to_account_id, amount, created_at, fee, kind, status, original_transfer_id, reversed_amount, memo, reference, sender_description, recipient_description, sender_category, recipient_category, hash, from_amount, initiated_by
+`
+
+type SetTransferHashParams struct {
+	ID   int64  `json:"id"`
+	Hash []byte `json:"hash"`
+}
+
+func (q *Queries) SetTransferHash(ctx context.Context, arg SetTransferHashParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, setTransferHash, arg.ID, arg.Hash)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Fee,
+		&i.Kind,
+		&i.Status,
+		&i.OriginalTransferID,
+		&i.ReversedAmount,
+		&i.Memo,
+		&i.Reference,
+		&i.SenderDescription,
+		&i.RecipientDescription,
+		&i.SenderCategory,
+		&i.RecipientCategory,
+		&i.Hash,
+		&i.FromAmount,
+		&i.InitiatedBy,
+	)
+	return i, err
+}
+
+const updateTransferRecipientDetails = `-- name: UpdateTransferRecipientDetails :one
+UPDATE transfers
+SET recipient_category = $1,
+    recipient_description = $2
+WHERE id = $3
+RETURNING id, from_account_id, to_account_id, amount, created_at, fee, kind, status, original_transfer_id, reversed_amount, memo, reference, sender_description, recipient_description, sender_category, recipient_category, hash, from_amount, initiated_by
+`
+
+type UpdateTransferRecipientDetailsParams struct {
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	ID          int64  `json:"id"`
+}
+
+func (q *Queries) UpdateTransferRecipientDetails(ctx context.Context, arg UpdateTransferRecipientDetailsParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, updateTransferRecipientDetails, arg.Category, arg.Description, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Fee,
+		&i.Kind,
+		&i.Status,
+		&i.OriginalTransferID,
+		&i.ReversedAmount,
+		&i.Memo,
+		&i.Reference,
+		&i.SenderDescription,
+		&i.RecipientDescription,
+		&i.SenderCategory,
+		&i.RecipientCategory,
+		&i.Hash,
+		&i.FromAmount,
+		&i.InitiatedBy,
+	)
+	return i, err
+}
+
+const updateTransferReversal = `-- name: UpdateTransferReversal :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + $1,
+    status = $2
+WHERE id = $3
+RETURNING id, from_account_id, to_account_id, amount, created_at, fee, kind, status, original_transfer_id, reversed_amount, memo, reference, sender_description, recipient_description, sender_category, recipient_category, hash, from_amount, initiated_by
+`
+
+type UpdateTransferReversalParams struct {
+	Amount int64  `json:"amount"`
+	Status string `json:"status"`
+	ID     int64  `json:"id"`
+}
+
+func (q *Queries) UpdateTransferReversal(ctx context.Context, arg UpdateTransferReversalParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, updateTransferReversal, arg.Amount, arg.Status, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Fee,
+		&i.Kind,
+		&i.Status,
+		&i.OriginalTransferID,
+		&i.ReversedAmount,
+		&i.Memo,
+		&i.Reference,
+		&i.SenderDescription,
+		&i.RecipientDescription,
+		&i.SenderCategory,
+		&i.RecipientCategory,
+		&i.Hash,
+		&i.FromAmount,
+		&i.InitiatedBy,
+	)
+	return i, err
+}
+
+const updateTransferSenderDetails = `-- name: UpdateTransferSenderDetails :one
+UPDATE transfers
+SET sender_category = $1,
+    sender_description = $2
+WHERE id = $3
+RETURNING id, from_account_id, to_account_id, amount, created_at, fee, kind, status, original_transfer_id, reversed_amount, memo, reference, sender_description, recipient_description, sender_category, recipient_category, hash, from_amount, initiated_by
+`
+
+type UpdateTransferSenderDetailsParams struct {
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	ID          int64  `json:"id"`
+}
+
+func (q *Queries) UpdateTransferSenderDetails(ctx context.Context, arg UpdateTransferSenderDetailsParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, updateTransferSenderDetails, arg.Category, arg.Description, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Fee,
+		&i.Kind,
+		&i.Status,
+		&i.OriginalTransferID,
+		&i.ReversedAmount,
+		&i.Memo,
+		&i.Reference,
+		&i.SenderDescription,
+		&i.RecipientDescription,
+		&i.SenderCategory,
+		&i.RecipientCategory,
+		&i.Hash,
+		&i.FromAmount,
+		&i.InitiatedBy,
+	)
+	return i, err
+}
@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// ErrTransferBatchReclaimed is returned to a processor whose batch was claimed by another
+// processor after it took too long
+var ErrTransferBatchReclaimed = errors.New("transfer batch was claimed by another processor")
+
+// CreateTransferBatchTxParams contains the input parameters for creating a transfer batch
+type CreateTransferBatchTxParams struct {
+	Owner         string                    `json:"owner"`
+	FromAccountID int64                     `json:"from_account_id"`
+	Mode          string                    `json:"mode"`
+	Items         []CreateTransferBatchLine `json:"items"`
+}
+
+// CreateTransferBatchLine is a single transfer of a batch
+type CreateTransferBatchLine struct {
+	LineNumber  int32  `json:"line_number"`
+	ToAccountID int64  `json:"to_account_id"`
+	Amount      int64  `json:"amount"`
+	Currency    string `json:"currency"`
+	Reference   string `json:"reference"`
+}
+
+// TransferBatchResult is a transfer batch together with its items
+type TransferBatchResult struct {
+	Batch TransferBatch       `json:"batch"`
+	Items []TransferBatchItem `json:"items"`
+}
+
+// CreateTransferBatchTx stores a batch and all of its items as pending, they are executed later
+func (store *SQLStore) CreateTransferBatchTx(ctx context.Context, arg CreateTransferBatchTxParams) (TransferBatchResult, error) {
+	var result TransferBatchResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		var totalAmount int64
+		for _, item := range arg.Items {
+			totalAmount += item.Amount
+		}
+
+		var err error
+		result.Batch, err = q.CreateTransferBatch(ctx, CreateTransferBatchParams{
+			Owner:         arg.Owner,
+			FromAccountID: arg.FromAccountID,
+			Mode:          arg.Mode,
+			ItemCount:     int32(len(arg.Items)),
+			TotalAmount:   totalAmount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Items = make([]TransferBatchItem, 0, len(arg.Items))
+		for _, line := range arg.Items {
+			item, err := q.CreateTransferBatchItem(ctx, CreateTransferBatchItemParams{
+				BatchID:     result.Batch.ID,
+				LineNumber:  line.LineNumber,
+				ToAccountID: line.ToAccountID,
+				Amount:      line.Amount,
+				Currency:    line.Currency,
+				Reference:   line.Reference,
+			})
+			if err != nil {
+				return err
+			}
+			result.Items = append(result.Items, item)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// BatchTransfer is the transfer to make for an item of a batch
+type BatchTransfer struct {
+	ItemID   int64            `json:"item_id"`
+	Transfer TransferTxParams `json:"transfer"`
+}
+
+// TransferBatchItemError tells which item stopped an atomic batch
+type TransferBatchItemError struct {
+	ItemID int64
+	Err    error
+}
+
+func (err *TransferBatchItemError) Error() string {
+	return fmt.Sprintf("batch item %d: %v", err.ItemID, err.Err)
+}
+
+func (err *TransferBatchItemError) Unwrap() error {
+	return err.Err
+}
+
+// ExecuteAtomicTransferBatchTx makes every transfer of a claimed batch in a single transaction, so
+// either all of them go through or none do. A failing transfer is reported as a *TransferBatchItemError.
+func (store *SQLStore) ExecuteAtomicTransferBatchTx(ctx context.Context, batch TransferBatch, transfers []BatchTransfer) (TransferBatch, error) {
+	var finished TransferBatch
+	err := store.execTx(ctx, func(q *Queries) error {
+		if err := checkClaim(ctx, q, batch); err != nil {
+			return err
+		}
+
+		for _, batchTransfer := range transfers {
+			if _, err := transferBatchItem(ctx, q, batchTransfer); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		finished, err = q.FinishTransferBatch(ctx, FinishTransferBatchParams{
+			ID:             batch.ID,
+			Status:         util.BatchStatusCompleted,
+			SucceededCount: int32(len(transfers)),
+			ClaimedAt:      batch.ClaimedAt,
+		})
+		return err
+	})
+
+	return finished, err
+}
+
+// ExecuteTransferBatchItemTx makes the transfer of one item of a claimed batch and marks the item
+// succeeded in the same transaction, so an item is never paid twice when its batch is claimed again
+func (store *SQLStore) ExecuteTransferBatchItemTx(ctx context.Context, batch TransferBatch, batchTransfer BatchTransfer) (TransferTxResult, error) {
+	var result TransferTxResult
+	err := store.execTx(ctx, func(q *Queries) error {
+		if err := checkClaim(ctx, q, batch); err != nil {
+			return err
+		}
+
+		var err error
+		result, err = transferBatchItem(ctx, q, batchTransfer)
+		return err
+	})
+
+	return result, err
+}
+
+// checkClaim locks a batch and makes sure it is still processing under the claim it was read with
+func checkClaim(ctx context.Context, q *Queries, batch TransferBatch) error {
+	locked, err := q.GetTransferBatchForUpdate(ctx, batch.ID)
+	if err != nil {
+		return err
+	}
+
+	if locked.Status != util.BatchStatusProcessing || !locked.ClaimedAt.Time.Equal(batch.ClaimedAt.Time) {
+		return ErrTransferBatchReclaimed
+	}
+	return nil
+}
+
+func transferBatchItem(ctx context.Context, q *Queries, batchTransfer BatchTransfer) (TransferTxResult, error) {
+	result, err := transfer(ctx, q, batchTransfer.Transfer, util.TransferKindTransfer, sql.NullInt64{})
+	if err != nil {
+		return result, &TransferBatchItemError{ItemID: batchTransfer.ItemID, Err: err}
+	}
+
+	_, err = q.UpdateTransferBatchItem(ctx, UpdateTransferBatchItemParams{
+		ID:         batchTransfer.ItemID,
+		Status:     util.BatchItemStatusSucceeded,
+		TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+	})
+	return result, err
+}
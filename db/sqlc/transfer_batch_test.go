@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// claimBatch claims batches until it gets the one with the given id, other tests may
+// have left batches pending before it
+func claimBatch(t *testing.T, store Store, id int64, staleBefore time.Time) TransferBatch {
+	for {
+		batch, err := store.ClaimTransferBatch(context.Background(), staleBefore)
+		require.NoError(t, err)
+		if batch.ID == id {
+			return batch
+		}
+	}
+}
+
+func TestReclaimTransferBatch(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+	created, err := store.CreateTransferBatchTx(context.Background(), CreateTransferBatchTxParams{
+		Owner:         account1.Owner,
+		FromAccountID: account1.ID,
+		Mode:          util.BatchModeBestEffort,
+		Items: []CreateTransferBatchLine{
+			{LineNumber: 1, ToAccountID: account2.ID, Amount: 1, Currency: util.USD},
+		},
+	})
+	require.NoError(t, err)
+
+	stale := claimBatch(t, store, created.Batch.ID, time.Now().Add(-time.Hour))
+	require.Equal(t, util.BatchStatusProcessing, stale.Status)
+	require.True(t, stale.ClaimedAt.Valid)
+
+	// the claim is old enough to be taken over by another processor
+	time.Sleep(10 * time.Millisecond)
+	batch := claimBatch(t, store, created.Batch.ID, time.Now())
+	require.True(t, batch.ClaimedAt.Time.After(stale.ClaimedAt.Time))
+
+	batchTransfer := BatchTransfer{
+		ItemID: created.Items[0].ID,
+		Transfer: TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        1,
+		},
+	}
+
+	// the processor that lost the batch can't make its transfers or finish it
+	_, err = store.ExecuteTransferBatchItemTx(context.Background(), stale, batchTransfer)
+	require.ErrorIs(t, err, ErrTransferBatchReclaimed)
+	_, err = store.FinishTransferBatch(context.Background(), FinishTransferBatchParams{
+		ID:        stale.ID,
+		Status:    util.BatchStatusCompleted,
+		ClaimedAt: stale.ClaimedAt,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	result, err := store.ExecuteTransferBatchItemTx(context.Background(), batch, batchTransfer)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-1, result.FromAccount.Balance)
+
+	items, err := store.ListTransferBatchItems(context.Background(), batch.ID)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, util.BatchItemStatusSucceeded, items[0].Status)
+	require.Equal(t, result.Transfer.ID, items[0].TransferID.Int64)
+
+	finished, err := store.FinishTransferBatch(context.Background(), FinishTransferBatchParams{
+		ID:             batch.ID,
+		Status:         util.BatchStatusCompleted,
+		SucceededCount: 1,
+		ClaimedAt:      batch.ClaimedAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.BatchStatusCompleted, finished.Status)
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferDetails(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+
+	reference := util.RandomString(12)
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID:        account1.ID,
+		ToAccountID:          account2.ID,
+		Amount:               10,
+		Memo:                 "Lunch on Friday",
+		Reference:            reference,
+		SenderDescription:    "Lunch",
+		RecipientDescription: "Paid back for lunch",
+		SenderCategory:       util.CategoryGroceries,
+	})
+	require.NoError(t, err)
+
+	transfer := result.Transfer
+	require.Equal(t, "Lunch on Friday", transfer.Memo)
+	require.Equal(t, reference, transfer.Reference)
+	require.Equal(t, util.CategoryGroceries, transfer.SenderCategory)
+	require.Empty(t, transfer.RecipientCategory)
+
+	// the recipient files it under their own category without touching the sender's
+	transfer, err = testQueries.UpdateTransferRecipientDetails(context.Background(), UpdateTransferRecipientDetailsParams{
+		Category:    util.CategoryOther,
+		Description: "Lunch money",
+		ID:          transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.CategoryOther, transfer.RecipientCategory)
+	require.Equal(t, "Lunch money", transfer.RecipientDescription)
+	require.Equal(t, util.CategoryGroceries, transfer.SenderCategory)
+	require.Equal(t, "Lunch", transfer.SenderDescription)
+
+	// the reference matches for both parties, descriptions only for their owner
+	search := func(accountID int64, query string, category string) []Transfer {
+		transfers, err := testQueries.ListAccountTransfers(context.Background(), ListAccountTransfersParams{
+			AccountID: accountID,
+			Query:     query,
+			Category:  category,
+			PageLimit: 5,
+		})
+		require.NoError(t, err)
+		return transfers
+	}
+
+	require.Len(t, search(account1.ID, reference, ""), 1)
+	require.Len(t, search(account2.ID, reference, ""), 1)
+	require.Len(t, search(account1.ID, "lunch money", ""), 0)
+	require.Len(t, search(account2.ID, "lunch money", ""), 1)
+	require.Len(t, search(account1.ID, "", util.CategoryGroceries), 1)
+	require.Len(t, search(account2.ID, "", util.CategoryGroceries), 0)
+	require.Len(t, search(account2.ID, "", util.CategoryOther), 1)
+}
+
+func TestListAccountTransfers(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountInCurrency(t, util.USD)
+	account2 := createRandomAccountInCurrency(t, util.USD)
+	account3 := createRandomAccountInCurrency(t, util.USD)
+
+	transfer := func(from, to Account, amount int64) Transfer {
+		result, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        amount,
+		})
+		require.NoError(t, err)
+		return result.Transfer
+	}
+
+	sent := transfer(account1, account2, 10)
+	received := transfer(account2, account1, 5)
+	other := transfer(account1, account3, 20)
+
+	list := func(arg ListAccountTransfersParams) []int64 {
+		arg.AccountID = account1.ID
+		if arg.PageLimit == 0 {
+			arg.PageLimit = 10
+		}
+		transfers, err := testQueries.ListAccountTransfers(context.Background(), arg)
+		require.NoError(t, err)
+
+		ids := []int64{}
+		for _, transfer := range transfers {
+			ids = append(ids, transfer.ID)
+		}
+		return ids
+	}
+
+	require.Equal(t, []int64{sent.ID, received.ID, other.ID}, list(ListAccountTransfersParams{}))
+	require.Equal(t, []int64{other.ID, received.ID, sent.ID}, list(ListAccountTransfersParams{Descending: true}))
+	require.Equal(t, []int64{sent.ID, other.ID}, list(ListAccountTransfersParams{Direction: "debit"}))
+	require.Equal(t, []int64{received.ID}, list(ListAccountTransfersParams{Direction: "credit"}))
+	require.Equal(t, []int64{sent.ID, received.ID}, list(ListAccountTransfersParams{CounterpartyAccountID: account2.ID}))
+	require.Equal(t, []int64{sent.ID, other.ID}, list(ListAccountTransfersParams{MinAmount: 10}))
+	require.Equal(t, []int64{received.ID, sent.ID}, list(ListAccountTransfersParams{MaxAmount: 10, Descending: true}))
+
+	// a page picks up after the cursor in either direction
+	require.Equal(t, []int64{sent.ID, received.ID}, list(ListAccountTransfersParams{PageLimit: 2}))
+	require.Equal(t, []int64{other.ID}, list(ListAccountTransfersParams{AfterID: received.ID}))
+	require.Equal(t, []int64{sent.ID}, list(ListAccountTransfersParams{AfterID: received.ID, Descending: true}))
+
+	// a debit is filtered on what the account was charged in its own currency
+	account4 := createRandomAccountInCurrency(t, util.EUR)
+	fx, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account4.ID,
+		Amount:        90,
+		FromAmount:    100,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{fx.Transfer.ID}, list(ListAccountTransfersParams{MinAmount: 95}))
+	require.Equal(t, []int64{sent.ID, received.ID, other.ID}, list(ListAccountTransfersParams{MaxAmount: 95}))
+}
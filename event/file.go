@@ -0,0 +1,46 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// FilePublisher appends events to a file as JSON, one event per line
+type FilePublisher struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFilePublisher creates a file publisher writing to path, creating the file if it doesn't exist
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FilePublisher{file: file}, nil
+}
+
+// Publish writes the event as a line of JSON and syncs the file, so an event is on disk
+// before the relay marks it published
+func (publisher *FilePublisher) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	if _, err := publisher.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return publisher.file.Sync()
+}
+
+// Close closes the file
+func (publisher *FilePublisher) Close() error {
+	return publisher.file.Close()
+}
@@ -0,0 +1,60 @@
+package event
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	events := []Event{
+		{
+			ID:            1,
+			Type:          "AccountCreated",
+			AggregateType: "account",
+			AggregateID:   "7",
+			Payload:       json.RawMessage(`{"account_id":7}`),
+			OccurredAt:    time.Now().Truncate(time.Second).UTC(),
+		},
+		{
+			ID:            2,
+			Type:          "TransferCompleted",
+			AggregateType: "transfer",
+			AggregateID:   "3",
+			Payload:       json.RawMessage(`{"transfer_id":3}`),
+			OccurredAt:    time.Now().Truncate(time.Second).UTC(),
+		},
+	}
+
+	publisher, err := NewFilePublisher(path)
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(context.Background(), events[0]))
+	require.NoError(t, publisher.Close())
+
+	// reopening appends rather than truncating
+	publisher, err = NewFilePublisher(path)
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(context.Background(), events[1]))
+	require.NoError(t, publisher.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var got []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		got = append(got, event)
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, events, got)
+}
@@ -0,0 +1,67 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+// Event is a domain event as it leaves the outbox, e.g. a TransferCompleted event about a transfer
+type Event struct {
+	ID            int64           `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+}
+
+// EventPublisher delivers domain events to whatever listens for them outside the database.
+// Events are delivered at least once, so consumers should ignore an ID they have already seen.
+type EventPublisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// LogPublisher writes events to the server log until a message broker is set up
+type LogPublisher struct{}
+
+// NewLogPublisher creates a new log publisher
+func NewLogPublisher() EventPublisher {
+	return &LogPublisher{}
+}
+
+// Publish logs the event
+func (publisher *LogPublisher) Publish(ctx context.Context, event Event) error {
+	log.Printf("event %d %s %s/%s: %s", event.ID, event.Type, event.AggregateType, event.AggregateID, event.Payload)
+	return nil
+}
+
+// MemoryPublisher keeps published events in memory, for tests
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// NewMemoryPublisher creates a new memory publisher
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// Publish appends the event to the ones published so far
+func (publisher *MemoryPublisher) Publish(ctx context.Context, event Event) error {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	publisher.events = append(publisher.events, event)
+	return nil
+}
+
+// Events returns the events published so far in the order they were published
+func (publisher *MemoryPublisher) Events() []Event {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	return append([]Event{}, publisher.events...)
+}
@@ -0,0 +1,25 @@
+package event
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryPublisher(t *testing.T) {
+	publisher := NewMemoryPublisher()
+	require.Empty(t, publisher.Events())
+
+	require.NoError(t, publisher.Publish(context.Background(), Event{ID: 1}))
+	require.NoError(t, publisher.Publish(context.Background(), Event{ID: 2}))
+
+	events := publisher.Events()
+	require.Len(t, events, 2)
+	require.Equal(t, int64(1), events[0].ID)
+	require.Equal(t, int64(2), events[1].ID)
+
+	// the returned slice is a copy
+	events[0].ID = 10
+	require.Equal(t, int64(1), publisher.Events()[0].ID)
+}
@@ -1,13 +1,20 @@
 package main
 
 import (
+	"context"
+	"crypto/ed25519"
 	"database/sql"
+	"encoding/json"
 	"log"
+	"os"
 
 	_ "github.com/lib/pq"
 	"github.com/malcolmmaima/maimabank/api"
 	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/event"
+	"github.com/malcolmmaima/maimabank/notify"
 	"github.com/malcolmmaima/maimabank/util"
+	"github.com/malcolmmaima/maimabank/worker"
 )
 
 func main() {
@@ -22,7 +29,41 @@ func main() {
 	}
 
 	store := db.NewStore(conn)
-	server, err := api.NewServer(config,store)
+
+	ledgerKey, err := util.ParseLedgerSigningKey(config.LedgerSigningKey)
+	if err != nil {
+		log.Fatal("cannot parse ledger signing key: ", err)
+	}
+
+	// go run main.go verify-ledger checks the ledger once and exits instead of starting the server
+	if len(os.Args) > 1 && os.Args[1] == "verify-ledger" {
+		verifyLedger(store, ledgerKey)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go worker.NewHoldSweeper(store, config.HoldSweepInterval).Start(ctx)
+	go worker.NewPaymentRequestSweeper(store, config.PaymentRequestSweepInterval).Start(ctx)
+	go worker.NewLedgerSealer(store, ledgerKey, config.LedgerSealInterval).Start(ctx)
+	go worker.NewBalanceSnapshotter(store, config.BalanceSnapshotInterval).Start(ctx)
+
+	notifier := notify.NewLogNotifier()
+	go worker.NewScheduledTransferRunner(store, notifier, config.ScheduledTransferInterval).Start(ctx)
+	go worker.NewTransferBatchProcessor(store, notifier, config.TransferBatchInterval).Start(ctx)
+
+	publisher := event.NewLogPublisher()
+	if config.EventLogFile != "" {
+		filePublisher, err := event.NewFilePublisher(config.EventLogFile)
+		if err != nil {
+			log.Fatal("cannot open event log file: ", err)
+		}
+		defer filePublisher.Close()
+		publisher = filePublisher
+	}
+	go worker.NewOutboxRelay(store, publisher, config.OutboxRelayInterval).Start(ctx)
+
+	server, err := api.NewServer(config, store, notify.NewLogSender())
 	if err != nil {
 		log.Fatal("cannot create server: ", err)
 	}
@@ -31,4 +72,28 @@ func main() {
 	if err != nil {
 		log.Fatal("cannot start server: ", err)
 	}
+}
+
+// verifyLedger prints the ledger report as JSON and exits with 1 if it found any discrepancy,
+// seal signatures are checked when a signing key is configured
+func verifyLedger(store db.Store, ledgerKey ed25519.PrivateKey) {
+	var publicKey ed25519.PublicKey
+	if ledgerKey != nil {
+		publicKey = ledgerKey.Public().(ed25519.PublicKey)
+	}
+
+	report, err := store.VerifyLedger(context.Background(), publicKey)
+	if err != nil {
+		log.Fatal("cannot verify ledger: ", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal("cannot write ledger report: ", err)
+	}
+
+	if !report.Consistent {
+		os.Exit(1)
+	}
 }
\ No newline at end of file
@@ -0,0 +1,25 @@
+package notify
+
+import (
+	"context"
+	"log"
+)
+
+// Notifier tells a user about something that happened to their money
+type Notifier interface {
+	Notify(ctx context.Context, username string, subject string, message string) error
+}
+
+// LogNotifier writes notifications to the server log until a real delivery channel is set up
+type LogNotifier struct{}
+
+// NewLogNotifier creates a new log notifier
+func NewLogNotifier() Notifier {
+	return &LogNotifier{}
+}
+
+// Notify logs the notification
+func (notifier *LogNotifier) Notify(ctx context.Context, username string, subject string, message string) error {
+	log.Printf("notify %s: %s: %s", username, subject, message)
+	return nil
+}
@@ -0,0 +1,26 @@
+package notify
+
+import (
+	"context"
+	"log"
+)
+
+// Sender delivers a message straight to an email address or phone number,
+// used to prove a user controls it before it is linked to their account
+type Sender interface {
+	Send(ctx context.Context, channel string, address string, message string) error
+}
+
+// LogSender writes messages to the server log until a real email and SMS provider is set up
+type LogSender struct{}
+
+// NewLogSender creates a new log sender
+func NewLogSender() Sender {
+	return &LogSender{}
+}
+
+// Send logs the message
+func (sender *LogSender) Send(ctx context.Context, channel string, address string, message string) error {
+	log.Printf("send %s to %s: %s", channel, address, message)
+	return nil
+}
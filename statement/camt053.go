@@ -0,0 +1,249 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+const (
+	camtDateFormat     = "2006-01-02"
+	camtDateTimeFormat = "2006-01-02T15:04:05Z"
+)
+
+// camt.053 caps most texts at 35 characters and names and remittance information at 140
+const (
+	camtTextLength = 35
+	camtNameLength = 140
+)
+
+// Camt053Writer writes an ISO 20022 camt.053.001.02 bank to customer statement. The statement
+// elements are written as they come, in the order the schema defines them.
+type Camt053Writer struct {
+	w       io.Writer
+	encoder *xml.Encoder
+	header  Header
+}
+
+// NewCamt053Writer creates a new camt.053 statement writer
+func NewCamt053Writer(w io.Writer) *Camt053Writer {
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return &Camt053Writer{w: w, encoder: encoder}
+}
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtDate struct {
+	Date string `xml:"Dt"`
+}
+
+type camtGroupHeader struct {
+	XMLName   xml.Name `xml:"GrpHdr"`
+	MessageID string   `xml:"MsgId"`
+	CreatedAt string   `xml:"CreDtTm"`
+}
+
+type camtAccount struct {
+	XMLName  xml.Name `xml:"Acct"`
+	ID       string   `xml:"Id>Othr>Id"`
+	Currency string   `xml:"Ccy"`
+	Owner    string   `xml:"Ownr>Nm,omitempty"`
+	BIC      string   `xml:"Svcr>FinInstnId>BIC"`
+}
+
+type camtBalance struct {
+	XMLName     xml.Name   `xml:"Bal"`
+	Type        string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount      camtAmount `xml:"Amt"`
+	CreditDebit string     `xml:"CdtDbtInd"`
+	Date        camtDate   `xml:"Dt"`
+}
+
+type camtEntry struct {
+	XMLName           xml.Name   `xml:"Ntry"`
+	Reference         string     `xml:"NtryRef"`
+	Amount            camtAmount `xml:"Amt"`
+	CreditDebit       string     `xml:"CdtDbtInd"`
+	Reversal          bool       `xml:"RvslInd,omitempty"`
+	Status            string     `xml:"Sts"`
+	BookingDate       camtDate   `xml:"BookgDt"`
+	ValueDate         camtDate   `xml:"ValDt"`
+	ServicerRef       string     `xml:"AcctSvcrRef"`
+	TransactionCode   string     `xml:"BkTxCd>Prtry>Cd"`
+	TransactionIssuer string     `xml:"BkTxCd>Prtry>Issr"`
+	Details           camtDetail `xml:"NtryDtls>TxDtls"`
+	AdditionalInfo    string     `xml:"AddtlNtryInf,omitempty"`
+}
+
+type camtDetail struct {
+	ServicerRef string `xml:"Refs>AcctSvcrRef"`
+	EndToEndID  string `xml:"Refs>EndToEndId"`
+	Remittance  string `xml:"RmtInf>Ustrd,omitempty"`
+}
+
+// Begin opens the document and the statement and writes the account with its opening and closing balances
+func (writer *Camt053Writer) Begin(header Header) error {
+	writer.header = header
+	statement := header.Statement
+	id := camtStatementID(statement)
+
+	if _, err := io.WriteString(writer.w, xml.Header); err != nil {
+		return err
+	}
+
+	tokens := []xml.Token{
+		xml.StartElement{
+			Name: xml.Name{Local: "Document"},
+			Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: camt053Namespace}},
+		},
+		xml.StartElement{Name: xml.Name{Local: "BkToCstmrStmt"}},
+	}
+	for _, token := range tokens {
+		if err := writer.encoder.EncodeToken(token); err != nil {
+			return err
+		}
+	}
+
+	createdAt := header.GeneratedAt.UTC().Format(camtDateTimeFormat)
+	err := writer.encoder.Encode(camtGroupHeader{MessageID: id, CreatedAt: createdAt})
+	if err != nil {
+		return err
+	}
+
+	if err := writer.encoder.EncodeToken(xml.StartElement{Name: xml.Name{Local: "Stmt"}}); err != nil {
+		return err
+	}
+
+	elements := []struct {
+		name  string
+		value string
+	}{
+		{"Id", id},
+		{"CreDtTm", createdAt},
+	}
+	for _, element := range elements {
+		err := writer.encoder.EncodeElement(element.value, xml.StartElement{Name: xml.Name{Local: element.name}})
+		if err != nil {
+			return err
+		}
+	}
+
+	period := struct {
+		From string `xml:"FrDtTm"`
+		To   string `xml:"ToDtTm"`
+	}{
+		From: statement.Since.UTC().Format(camtDateTimeFormat),
+		To:   statement.Until.UTC().Add(-time.Second).Format(camtDateTimeFormat),
+	}
+	err = writer.encoder.EncodeElement(period, xml.StartElement{Name: xml.Name{Local: "FrToDt"}})
+	if err != nil {
+		return err
+	}
+
+	err = writer.encoder.Encode(camtAccount{
+		ID:       strconv.FormatInt(statement.AccountID, 10),
+		Currency: statement.Currency,
+		Owner:    truncate(header.HolderName, camtNameLength),
+		BIC:      BankBIC,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the opening balance is booked at the start of the period, the closing balance on its last day
+	balances := []camtBalance{
+		camtBookedBalance("OPBD", statement.Currency, statement.OpeningBalance, statement.Since),
+		camtBookedBalance("CLBD", statement.Currency, statement.ClosingBalance, statement.Until.AddDate(0, 0, -1)),
+	}
+	for _, balance := range balances {
+		if err := writer.encoder.Encode(balance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Line writes an entry
+func (writer *Camt053Writer) Line(line db.StatementLine) error {
+	reference := strconv.FormatInt(line.ID, 10)
+	endToEnd := line.Reference
+	if endToEnd == "" {
+		endToEnd = "NOTPROVIDED"
+	}
+	bookingDate := line.CreatedAt.UTC().Format(camtDateFormat)
+
+	return writer.encoder.Encode(camtEntry{
+		Reference:         reference,
+		Amount:            camtAmount{Currency: writer.header.Statement.Currency, Value: util.FormatAmount(abs(line.Amount))},
+		CreditDebit:       camtCreditDebit(line.Amount),
+		Reversal:          line.LineType == util.TransferKindReversal,
+		Status:            "BOOK",
+		BookingDate:       camtDate{Date: bookingDate},
+		ValueDate:         camtDate{Date: bookingDate},
+		ServicerRef:       reference,
+		TransactionCode:   line.LineType,
+		TransactionIssuer: BankID,
+		Details: camtDetail{
+			ServicerRef: reference,
+			EndToEndID:  truncate(endToEnd, camtTextLength),
+			Remittance:  truncate(line.Memo, camtNameLength),
+		},
+		AdditionalInfo: truncate(Describe(line), camtNameLength),
+	})
+}
+
+// End closes the statement and the document
+func (writer *Camt053Writer) End() error {
+	for _, name := range []string{"Stmt", "BkToCstmrStmt", "Document"} {
+		if err := writer.encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+	if err := writer.encoder.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer.w, "\n")
+	return err
+}
+
+func camtBookedBalance(code string, currency string, amount int64, date time.Time) camtBalance {
+	return camtBalance{
+		Type:        code,
+		Amount:      camtAmount{Currency: currency, Value: util.FormatAmount(abs(amount))},
+		CreditDebit: camtCreditDebit(amount),
+		Date:        camtDate{Date: date.UTC().Format(camtDateFormat)},
+	}
+}
+
+// a zero balance counts as a credit
+func camtCreditDebit(amount int64) string {
+	if amount < 0 {
+		return "DBIT"
+	}
+	return "CRDT"
+}
+
+// camtStatementID identifies the statement of an account over a period, the same statement
+// exported twice gets the same id
+func camtStatementID(statement db.AccountStatement) string {
+	return fmt.Sprintf("%d-%s-%s", statement.AccountID,
+		statement.Since.UTC().Format("20060102"), statement.Until.UTC().AddDate(0, 0, -1).Format("20060102"))
+}
+
+func abs(amount int64) int64 {
+	if amount < 0 {
+		return -amount
+	}
+	return amount
+}
@@ -0,0 +1,159 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"testing"
+
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// the children of the camt.053.001.02 elements the writer produces, in the order the schema defines them
+var camt053Sequences = map[string][]string{
+	"Document":      {"BkToCstmrStmt"},
+	"BkToCstmrStmt": {"GrpHdr", "Stmt"},
+	"GrpHdr":        {"MsgId", "CreDtTm", "MsgRcpt", "MsgPgntn", "AddtlInf"},
+	"Stmt": {"Id", "ElctrncSeqNb", "LglSeqNb", "CreDtTm", "FrToDt", "CpyDplctInd", "RptgSrc", "Acct",
+		"RltdAcct", "Intrst", "Bal", "TxsSummry", "Ntry", "AddtlStmtInf"},
+	"FrToDt": {"FrDtTm", "ToDtTm"},
+	"Acct":   {"Id", "Tp", "Ccy", "Nm", "Ownr", "Svcr"},
+	"Bal":    {"Tp", "CdtLine", "Amt", "CdtDbtInd", "Dt", "Avlbty"},
+	"Ntry": {"NtryRef", "Amt", "CdtDbtInd", "RvslInd", "Sts", "BookgDt", "ValDt", "AcctSvcrRef", "Avlbty",
+		"BkTxCd", "ComssnWvrInd", "AddtlInfInd", "AmtDtls", "Chrgs", "TechInptChanl", "Intrst", "NtryDtls",
+		"AddtlNtryInf"},
+	"NtryDtls": {"Btch", "TxDtls"},
+	"TxDtls": {"Refs", "AmtDtls", "Avlbty", "BkTxCd", "Chrgs", "Intrst", "RltdPties", "RltdAgts", "Purp",
+		"RltdRmtInf", "RmtInf", "RltdDts", "RltdPric", "RltdQties", "FinInstrmId", "Tax", "RtrInf",
+		"CorpActn", "SfkpgAcct", "AddtlTxInf"},
+	"Refs":   {"MsgId", "AcctSvcrRef", "PmtInfId", "InstrId", "EndToEndId", "TxId"},
+	"BkTxCd": {"Domn", "Prtry"},
+	"Prtry":  {"Cd", "Issr"},
+}
+
+// elements that can repeat in their parent, the others in the sequences can only appear once
+var camt053Repeating = map[string]bool{"Stmt": true, "Bal": true, "Ntry": true, "TxDtls": true, "Intrst": true, "Avlbty": true}
+
+// requireCamt053Structure checks every element with a known sequence only has children the schema allows,
+// in the order it allows them and no more than once unless they repeat
+func requireCamt053Structure(t *testing.T, out []byte) {
+	decoder := xml.NewDecoder(bytes.NewReader(out))
+	var path []string
+	var positions []int
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			if len(path) == 0 {
+				require.Equal(t, "Document", element.Name.Local)
+				require.Equal(t, camt053Namespace, element.Name.Space)
+			} else if sequence, ok := camt053Sequences[path[len(path)-1]]; ok {
+				position := -1
+				for i, name := range sequence {
+					if name == element.Name.Local {
+						position = i
+					}
+				}
+				require.NotEqual(t, -1, position, "%s is not allowed in %s", element.Name.Local, path[len(path)-1])
+
+				last := positions[len(positions)-1]
+				if camt053Repeating[element.Name.Local] {
+					require.GreaterOrEqual(t, position, last, "%s is out of order in %s", element.Name.Local, path[len(path)-1])
+				} else {
+					require.Greater(t, position, last, "%s is out of order or repeated in %s", element.Name.Local, path[len(path)-1])
+				}
+				positions[len(positions)-1] = position
+			}
+			path = append(path, element.Name.Local)
+			positions = append(positions, -1)
+		case xml.EndElement:
+			path = path[:len(path)-1]
+			positions = positions[:len(positions)-1]
+		}
+	}
+	require.Empty(t, path)
+}
+
+type parsedCamt053 struct {
+	MessageID string `xml:"BkToCstmrStmt>GrpHdr>MsgId"`
+	Statement struct {
+		ID       string `xml:"Id"`
+		Account  string `xml:"Acct>Id>Othr>Id"`
+		Currency string `xml:"Acct>Ccy"`
+		Owner    string `xml:"Acct>Ownr>Nm"`
+		Balances []struct {
+			Code        string     `xml:"Tp>CdOrPrtry>Cd"`
+			Amount      camtAmount `xml:"Amt"`
+			CreditDebit string     `xml:"CdtDbtInd"`
+			Date        string     `xml:"Dt>Dt"`
+		} `xml:"Bal"`
+		Entries []struct {
+			Reference   string     `xml:"NtryRef"`
+			Amount      camtAmount `xml:"Amt"`
+			CreditDebit string     `xml:"CdtDbtInd"`
+			Reversal    bool       `xml:"RvslInd"`
+			BookingDate string     `xml:"BookgDt>Dt"`
+			EndToEndID  string     `xml:"NtryDtls>TxDtls>Refs>EndToEndId"`
+			Remittance  string     `xml:"NtryDtls>TxDtls>RmtInf>Ustrd"`
+			Info        string     `xml:"AddtlNtryInf"`
+		} `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+func TestCamt053Writer(t *testing.T) {
+	header := randomHeader()
+	header.Statement.OpeningBalance = -300
+	out := writeStatement(t, FormatCamt053, header, sampleLines())
+	requireCamt053Structure(t, out)
+
+	var parsed parsedCamt053
+	require.NoError(t, xml.Unmarshal(out, &parsed))
+	statement := parsed.Statement
+	require.Equal(t, parsed.MessageID, statement.ID)
+	require.Equal(t, fmt.Sprint(header.Statement.AccountID), statement.Account)
+	require.Equal(t, util.USD, statement.Currency)
+	require.Equal(t, header.HolderName, statement.Owner)
+
+	require.Len(t, statement.Balances, 2)
+	require.Equal(t, "OPBD", statement.Balances[0].Code)
+	require.Equal(t, camtAmount{Currency: util.USD, Value: "3.00"}, statement.Balances[0].Amount)
+	require.Equal(t, "DBIT", statement.Balances[0].CreditDebit)
+	require.Equal(t, "2024-03-01", statement.Balances[0].Date)
+	require.Equal(t, "CLBD", statement.Balances[1].Code)
+	require.Equal(t, camtAmount{Currency: util.USD, Value: "114.50"}, statement.Balances[1].Amount)
+	require.Equal(t, "CRDT", statement.Balances[1].CreditDebit)
+	require.Equal(t, "2024-03-31", statement.Balances[1].Date)
+
+	require.Len(t, statement.Entries, 3)
+	require.Equal(t, "1", statement.Entries[0].Reference)
+	require.Equal(t, "25.00", statement.Entries[0].Amount.Value)
+	require.Equal(t, "CRDT", statement.Entries[0].CreditDebit)
+	require.Equal(t, "2024-03-05", statement.Entries[0].BookingDate)
+	require.Equal(t, "REF-1", statement.Entries[0].EndToEndID)
+	require.Equal(t, "Salary, March", statement.Entries[0].Remittance)
+	require.Equal(t, "DBIT", statement.Entries[1].CreditDebit)
+	require.Equal(t, "NOTPROVIDED", statement.Entries[1].EndToEndID)
+	require.Equal(t, "Rent <March> & bills", statement.Entries[1].Info)
+	require.Equal(t, "0.50", statement.Entries[2].Amount.Value)
+}
+
+func TestCamt053WriterReversal(t *testing.T) {
+	line := sampleLines()[0]
+	line.LineType = util.TransferKindReversal
+	out := writeStatement(t, FormatCamt053, randomHeader(), []db.StatementLine{line})
+	requireCamt053Structure(t, out)
+
+	var parsed parsedCamt053
+	require.NoError(t, xml.Unmarshal(out, &parsed))
+	require.Len(t, parsed.Statement.Entries, 1)
+	require.True(t, parsed.Statement.Entries[0].Reversal)
+}
@@ -0,0 +1,61 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// CSVWriter writes one row per statement line under a header row, which is what accounting
+// tools and spreadsheets import. The period balances follow from the first and last rows.
+type CSVWriter struct {
+	w *csv.Writer
+}
+
+// NewCSVWriter creates a new CSV statement writer
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+// Begin writes the header row
+func (writer *CSVWriter) Begin(header Header) error {
+	return writer.w.Write([]string{
+		"date",
+		"entry_id",
+		"type",
+		"description",
+		"reference",
+		"counterparty_account_id",
+		"amount",
+		"balance",
+	})
+}
+
+// Line writes a row
+func (writer *CSVWriter) Line(line db.StatementLine) error {
+	counterparty := ""
+	if line.CounterpartyAccountID != 0 {
+		counterparty = strconv.FormatInt(line.CounterpartyAccountID, 10)
+	}
+
+	return writer.w.Write([]string{
+		line.CreatedAt.UTC().Format(time.RFC3339),
+		strconv.FormatInt(line.ID, 10),
+		line.LineType,
+		Describe(line),
+		line.Reference,
+		counterparty,
+		util.FormatAmount(line.Amount),
+		util.FormatAmount(line.Balance),
+	})
+}
+
+// End flushes the rows
+func (writer *CSVWriter) End() error {
+	writer.w.Flush()
+	return writer.w.Error()
+}
@@ -0,0 +1,20 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVWriter(t *testing.T) {
+	out := writeStatement(t, FormatCSV, randomHeader(), sampleLines())
+
+	records, err := csv.NewReader(bytes.NewReader(out)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	require.Equal(t, []string{"date", "entry_id", "type", "description", "reference", "counterparty_account_id", "amount", "balance"}, records[0])
+	require.Equal(t, []string{"2024-03-05T10:30:00Z", "1", "transfer", "Salary, March", "REF-1", "12", "25.00", "125.00"}, records[1])
+	require.Equal(t, []string{"2024-03-05T11:30:00Z", "3", "fee", "Transfer fee", "", "", "-0.50", "114.50"}, records[3])
+}
@@ -0,0 +1,170 @@
+package statement
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// MT940 references are at most 16 characters, :86: holds up to 6 lines of 65
+const (
+	mt940ReferenceLength = 16
+	mt940InfoLines       = 6
+	mt940InfoLineLength  = 65
+)
+
+// MT940Writer writes the text block of a SWIFT MT940 customer statement message, which is what
+// ERP systems import from MT940 files. Fields end with CRLF and only use the SWIFT character set.
+type MT940Writer struct {
+	w      *bufio.Writer
+	header Header
+}
+
+// NewMT940Writer creates a new MT940 statement writer
+func NewMT940Writer(w io.Writer) *MT940Writer {
+	return &MT940Writer{w: bufio.NewWriter(w)}
+}
+
+// Begin writes the statement reference, the account and the opening balance
+func (writer *MT940Writer) Begin(header Header) error {
+	writer.header = header
+	statement := header.Statement
+
+	fields := []string{
+		":20:" + mt940Text(fmt.Sprintf("%d-%s", statement.AccountID, statement.Since.UTC().Format("060102")), mt940ReferenceLength),
+		fmt.Sprintf(":25:%s/%d", BankBIC, statement.AccountID),
+		":28C:1/1",
+		":60F:" + mt940Balance(statement.OpeningBalance, statement.Since, statement.Currency),
+	}
+	return writer.writeFields(fields)
+}
+
+// Line writes a statement line and the information to the account owner that goes with it
+func (writer *MT940Writer) Line(line db.StatementLine) error {
+	mark := "C"
+	if line.Amount < 0 {
+		mark = "D"
+	}
+	// a reversal crediting the account reverses a debit and the other way round
+	if line.LineType == util.TransferKindReversal {
+		if line.Amount < 0 {
+			mark = "RC"
+		} else {
+			mark = "RD"
+		}
+	}
+
+	ownerReference := "NONREF"
+	if line.Reference != "" {
+		ownerReference = mt940Text(line.Reference, mt940ReferenceLength)
+	}
+
+	bookingDate := line.CreatedAt.UTC()
+	fields := []string{
+		fmt.Sprintf(":61:%s%s%s%s%s%s//%d",
+			bookingDate.Format("060102"),
+			bookingDate.Format("0102"),
+			mark,
+			mt940Amount(abs(line.Amount)),
+			mt940TransactionType(line),
+			ownerReference,
+			line.ID,
+		),
+		":86:" + mt940Info(Describe(line)),
+	}
+	return writer.writeFields(fields)
+}
+
+// End writes the closing balance and the end of the message
+func (writer *MT940Writer) End() error {
+	statement := writer.header.Statement
+	fields := []string{
+		":62F:" + mt940Balance(statement.ClosingBalance, statement.Until.AddDate(0, 0, -1), statement.Currency),
+		"-",
+	}
+	if err := writer.writeFields(fields); err != nil {
+		return err
+	}
+	return writer.w.Flush()
+}
+
+func (writer *MT940Writer) writeFields(fields []string) error {
+	for _, field := range fields {
+		if _, err := writer.w.WriteString(field + "\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mt940Balance is a booked balance: credit or debit mark, date, currency and amount
+func mt940Balance(amount int64, date time.Time, currency string) string {
+	mark := "C"
+	if amount < 0 {
+		mark = "D"
+	}
+	return mark + date.UTC().Format("060102") + currency + mt940Amount(abs(amount))
+}
+
+// mt940Amount uses a comma as the decimal separator, as SWIFT does
+func mt940Amount(amount int64) string {
+	return strconv.FormatInt(amount/100, 10) + "," + fmt.Sprintf("%02d", amount%100)
+}
+
+// mt940TransactionType is the N followed by the SWIFT transaction type code of a line
+func mt940TransactionType(line db.StatementLine) string {
+	switch line.LineType {
+	case util.StatementLineFee:
+		return "NCHG"
+	case util.JournalKindInterest:
+		return "NINT"
+	case util.StatementLinePot, util.JournalKindAdjustment, util.StatementLineEntry:
+		return "NMSC"
+	}
+	return "NTRF"
+}
+
+// mt940Info splits text over the lines of a :86: field. A line starting with a colon or a dash
+// would be read as the next field or the end of the message, so those are replaced with a space.
+func mt940Info(text string) string {
+	text = mt940Text(text, mt940InfoLines*mt940InfoLineLength)
+	var lines []string
+	for len(text) > mt940InfoLineLength {
+		lines = append(lines, text[:mt940InfoLineLength])
+		text = text[mt940InfoLineLength:]
+	}
+	lines = append(lines, text)
+
+	for i := 1; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], ":") || strings.HasPrefix(lines[i], "-") {
+			lines[i] = " " + lines[i][1:]
+		}
+	}
+	return strings.Join(lines, "\r\n")
+}
+
+// mt940Text replaces the characters outside the SWIFT character set with a space and cuts the
+// text to at most length characters
+func mt940Text(text string, length int) string {
+	var b strings.Builder
+	for _, r := range text {
+		if b.Len() == length {
+			break
+		}
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case strings.ContainsRune("/-?:().,'+ ", r):
+			b.WriteRune(r)
+		default:
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
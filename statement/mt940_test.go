@@ -0,0 +1,49 @@
+package statement
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMT940Writer(t *testing.T) {
+	header := randomHeader()
+	out := string(writeStatement(t, FormatMT940, header, sampleLines()))
+
+	fields := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+	require.Equal(t, []string{
+		fmt.Sprintf(":20:%d-240301", header.Statement.AccountID),
+		fmt.Sprintf(":25:%s/%d", BankBIC, header.Statement.AccountID),
+		":28C:1/1",
+		":60F:C240301USD100,00",
+		":61:2403050305C25,00NTRFREF-1//1",
+		":86:Salary, March",
+		":61:2403050305D10,00NTRFNONREF//2",
+		":86:Rent  March    bills",
+		":61:2403050305D0,50NCHGNONREF//3",
+		":86:Transfer fee",
+		":62F:C240331USD114,50",
+		"-",
+	}, fields)
+}
+
+func TestMT940WriterReversal(t *testing.T) {
+	line := sampleLines()[0]
+	line.LineType = util.TransferKindReversal
+	out := string(writeStatement(t, FormatMT940, randomHeader(), []db.StatementLine{line}))
+	require.Contains(t, out, ":61:2403050305RD25,00NTRFREF-1//1\r\n")
+}
+
+func TestMT940Info(t *testing.T) {
+	text := strings.Repeat("a", 65) + "-" + strings.Repeat("b", 500)
+	lines := strings.Split(mt940Info(text), "\r\n")
+	require.Len(t, lines, mt940InfoLines)
+	require.True(t, strings.HasPrefix(lines[1], " "))
+	for _, line := range lines {
+		require.LessOrEqual(t, len(line), mt940InfoLineLength)
+	}
+}
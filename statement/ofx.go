@@ -0,0 +1,198 @@
+package statement
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+const ofxTimeFormat = "20060102150405"
+
+// OFX caps transaction names at 32 characters and memos at 255
+const (
+	ofxNameLength = 32
+	ofxMemoLength = 255
+)
+
+// OFXWriter writes an OFX 1.02 bank statement download, which accounting tools such as
+// GnuCash and QuickBooks import. The closing balance is the statement's ledger balance.
+type OFXWriter struct {
+	w      *bufio.Writer
+	header Header
+}
+
+// NewOFXWriter creates a new OFX statement writer
+func NewOFXWriter(w io.Writer) *OFXWriter {
+	return &OFXWriter{w: bufio.NewWriter(w)}
+}
+
+// Begin writes the OFX headers, the sign on response and the start of the transaction list
+func (writer *OFXWriter) Begin(header Header) error {
+	writer.header = header
+	statement := header.Statement
+
+	_, err := fmt.Fprintf(writer.w, `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+SECURITY:NONE
+ENCODING:USASCII
+CHARSET:1252
+COMPRESSION:NONE
+OLDFILEUID:NONE
+NEWFILEUID:NONE
+
+<OFX>
+<SIGNONMSGSRSV1>
+<SONRS>
+<STATUS>
+<CODE>0
+<SEVERITY>INFO
+</STATUS>
+<DTSERVER>%s
+<LANGUAGE>ENG
+<FI>
+<ORG>%s
+<FID>%s
+</FI>
+</SONRS>
+</SIGNONMSGSRSV1>
+<BANKMSGSRSV1>
+<STMTTRNRS>
+<TRNUID>%d
+<STATUS>
+<CODE>0
+<SEVERITY>INFO
+</STATUS>
+<STMTRS>
+<CURDEF>%s
+<BANKACCTFROM>
+<BANKID>%s
+<ACCTID>%d
+<ACCTTYPE>CHECKING
+</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>%s
+<DTEND>%s
+`,
+		ofxTime(header.GeneratedAt),
+		ofxText(BankName, ofxNameLength),
+		BankID,
+		statement.AccountID,
+		statement.Currency,
+		BankID,
+		statement.AccountID,
+		ofxTime(statement.Since),
+		ofxTime(statement.Until),
+	)
+	return err
+}
+
+// Line writes a transaction
+func (writer *OFXWriter) Line(line db.StatementLine) error {
+	name := Describe(line)
+	memo := line.Memo
+	if line.Reference != "" {
+		memo = strings.TrimSpace(memo + " " + line.Reference)
+	}
+
+	_, err := fmt.Fprintf(writer.w, `<STMTTRN>
+<TRNTYPE>%s
+<DTPOSTED>%s
+<TRNAMT>%s
+<FITID>%d
+<NAME>%s
+`,
+		ofxTransactionType(line),
+		ofxTime(line.CreatedAt),
+		util.FormatAmount(line.Amount),
+		line.ID,
+		ofxText(name, ofxNameLength),
+	)
+	if err != nil {
+		return err
+	}
+
+	if memo != "" {
+		if _, err := fmt.Fprintf(writer.w, "<MEMO>%s\n", ofxText(memo, ofxMemoLength)); err != nil {
+			return err
+		}
+	}
+
+	_, err = writer.w.WriteString("</STMTTRN>\n")
+	return err
+}
+
+// End closes the transaction list and writes the closing balance
+func (writer *OFXWriter) End() error {
+	statement := writer.header.Statement
+
+	_, err := fmt.Fprintf(writer.w, `</BANKTRANLIST>
+<LEDGERBAL>
+<BALAMT>%s
+<DTASOF>%s
+</LEDGERBAL>
+</STMTRS>
+</STMTTRNRS>
+</BANKMSGSRSV1>
+</OFX>
+`,
+		util.FormatAmount(statement.ClosingBalance),
+		ofxTime(statement.Until),
+	)
+	if err != nil {
+		return err
+	}
+	return writer.w.Flush()
+}
+
+func ofxTransactionType(line db.StatementLine) string {
+	switch line.LineType {
+	case util.StatementLineFee:
+		return "FEE"
+	case util.JournalKindInterest:
+		return "INT"
+	case util.StatementLinePot:
+		return "XFER"
+	}
+	if line.Amount < 0 {
+		return "DEBIT"
+	}
+	return "CREDIT"
+}
+
+func ofxTime(t time.Time) string {
+	return t.UTC().Format(ofxTimeFormat) + "[0:GMT]"
+}
+
+// ofxText escapes the characters SGML gives a meaning to, drops characters the declared
+// character set can't hold and cuts the text to at most length characters
+func ofxText(text string, length int) string {
+	var b strings.Builder
+	count := 0
+	for _, r := range text {
+		if count == length {
+			break
+		}
+		switch {
+		case r == '&':
+			b.WriteString("&amp;")
+		case r == '<':
+			b.WriteString("&lt;")
+		case r == '>':
+			b.WriteString("&gt;")
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+		count++
+	}
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package statement
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOFXWriter(t *testing.T) {
+	header := randomHeader()
+	out := string(writeStatement(t, FormatOFX, header, sampleLines()))
+
+	require.True(t, strings.HasPrefix(out, "OFXHEADER:100\n"))
+	require.Contains(t, out, fmt.Sprintf("<ACCTID>%d\n", header.Statement.AccountID))
+	require.Contains(t, out, "<CURDEF>USD\n")
+	require.Contains(t, out, "<DTSTART>20240301000000[0:GMT]\n")
+	require.Equal(t, 3, strings.Count(out, "<STMTTRN>"))
+	require.Equal(t, 3, strings.Count(out, "</STMTTRN>"))
+	require.Contains(t, out, "<TRNTYPE>CREDIT\n<DTPOSTED>20240305103000[0:GMT]\n<TRNAMT>25.00\n<FITID>1\n<NAME>Salary, March\n<MEMO>Salary, March REF-1\n")
+	require.Contains(t, out, "<NAME>Rent &lt;March&gt; &amp; bills\n")
+	require.Contains(t, out, "<TRNTYPE>FEE\n")
+	require.Contains(t, out, "<LEDGERBAL>\n<BALAMT>114.50\n")
+	require.True(t, strings.HasSuffix(out, "</OFX>\n"))
+}
+
+func TestOFXText(t *testing.T) {
+	require.Equal(t, "caf? &amp; bar", ofxText("café & bar", 32))
+	require.Equal(t, "abc", ofxText("abcdef", 3))
+}
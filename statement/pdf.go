@@ -0,0 +1,330 @@
+package statement
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// A4 in points, with the margins the statement is laid out in
+const (
+	pdfPageWidth    = 595.0
+	pdfPageHeight   = 842.0
+	pdfMargin       = 50.0
+	pdfFooterHeight = 60.0
+	pdfRowHeight    = 14.0
+)
+
+// objects written before the pages, the page tree is written last once every page is known
+const (
+	pdfCatalogObject = iota + 1
+	pdfPagesObject
+	pdfInfoObject
+	pdfRegularFontObject
+	pdfBoldFontObject
+	pdfMonoFontObject
+	pdfMonoBoldFontObject
+	pdfFirstPageObject
+)
+
+// fonts every PDF reader has, so none have to be embedded
+const (
+	pdfRegularFont = "F1"
+	pdfBoldFont    = "F2"
+	// amounts are set in fixed width fonts so they can be right aligned without font metrics
+	pdfMonoFont     = "F3"
+	pdfMonoBoldFont = "F4"
+	// width of a Courier character as a fraction of the font size
+	pdfMonoCharWidth = 0.6
+)
+
+// descriptions longer than this are cut so they don't run into the amounts
+const pdfDescriptionLength = 48
+
+// right edges of the amount columns
+const (
+	pdfMoneyInRight  = 405.0
+	pdfMoneyOutRight = 475.0
+	pdfBalanceRight  = pdfPageWidth - pdfMargin
+)
+
+// PDFWriter writes a branded, printable statement with the account holder's details and the period
+// balances on the first page. Every page is written out as soon as it is full, only the xref offsets
+// of the objects written so far are kept until the end.
+type PDFWriter struct {
+	w       *bufio.Writer
+	offset  int64
+	offsets []int64
+	pages   []int
+	header  Header
+	content bytes.Buffer
+	y       float64
+}
+
+// NewPDFWriter creates a new PDF statement writer
+func NewPDFWriter(w io.Writer) *PDFWriter {
+	return &PDFWriter{w: bufio.NewWriter(w)}
+}
+
+// Begin writes the document header and the objects every page refers to, then starts the
+// first page with the bank, the account holder and the period balances
+func (writer *PDFWriter) Begin(header Header) error {
+	writer.header = header
+	writer.offsets = make([]int64, pdfFirstPageObject)
+
+	if err := writer.write("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n"); err != nil {
+		return err
+	}
+
+	err := writer.writeObject(pdfCatalogObject, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesObject))
+	if err != nil {
+		return err
+	}
+
+	title := fmt.Sprintf("%s statement for account %d", BankName, header.Statement.AccountID)
+	err = writer.writeObject(pdfInfoObject, fmt.Sprintf("<< /Title %s /Producer %s /CreationDate (D:%s) >>",
+		pdfString(title), pdfString(BankName), header.GeneratedAt.UTC().Format("20060102150405Z")))
+	if err != nil {
+		return err
+	}
+
+	fonts := []struct {
+		object int
+		name   string
+	}{
+		{pdfRegularFontObject, "Helvetica"},
+		{pdfBoldFontObject, "Helvetica-Bold"},
+		{pdfMonoFontObject, "Courier"},
+		{pdfMonoBoldFontObject, "Courier-Bold"},
+	}
+	for _, font := range fonts {
+		err := writer.writeObject(font.object, fmt.Sprintf(
+			"<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font.name))
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.startPage()
+	writer.writeSummary()
+	writer.writeColumnHeadings()
+	return nil
+}
+
+// Line writes a row, starting a new page when the current one is full
+func (writer *PDFWriter) Line(line db.StatementLine) error {
+	if writer.y-pdfRowHeight < pdfFooterHeight {
+		if err := writer.finishPage(); err != nil {
+			return err
+		}
+		writer.startPage()
+		writer.writeColumnHeadings()
+	}
+
+	writer.y -= pdfRowHeight
+	writer.text(pdfRegularFont, 9, pdfMargin, writer.y, line.CreatedAt.UTC().Format("02 Jan 2006"))
+	writer.text(pdfRegularFont, 9, pdfMargin+70, writer.y, truncate(Describe(line), pdfDescriptionLength))
+	if line.Amount >= 0 {
+		writer.amount(9, pdfMoneyInRight, writer.y, util.FormatAmount(line.Amount))
+	} else {
+		writer.amount(9, pdfMoneyOutRight, writer.y, util.FormatAmount(-line.Amount))
+	}
+	writer.amount(9, pdfBalanceRight, writer.y, util.FormatAmount(line.Balance))
+	return nil
+}
+
+// End closes the last page with the closing balance and writes the page tree, the xref table and the trailer
+func (writer *PDFWriter) End() error {
+	statement := writer.header.Statement
+	if writer.y-2*pdfRowHeight < pdfFooterHeight {
+		if err := writer.finishPage(); err != nil {
+			return err
+		}
+		writer.startPage()
+	}
+	writer.y -= 2 * pdfRowHeight
+	writer.text(pdfBoldFont, 9, pdfMargin+70, writer.y, "Closing balance")
+	writer.amount(9, pdfBalanceRight, writer.y, util.FormatAmount(statement.ClosingBalance))
+
+	if err := writer.finishPage(); err != nil {
+		return err
+	}
+
+	kids := make([]string, len(writer.pages))
+	for i, page := range writer.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+	err := writer.writeObject(pdfPagesObject, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>",
+		strings.Join(kids, " "), len(writer.pages)))
+	if err != nil {
+		return err
+	}
+
+	xref := writer.offset
+	var b strings.Builder
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(writer.offsets))
+	for _, offset := range writer.offsets[1:] {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(writer.offsets), pdfCatalogObject, pdfInfoObject, xref)
+	if err := writer.write(b.String()); err != nil {
+		return err
+	}
+
+	return writer.w.Flush()
+}
+
+func (writer *PDFWriter) writeSummary() {
+	statement := writer.header.Statement
+
+	writer.text(pdfBoldFont, 20, pdfMargin, writer.y, BankName)
+	writer.text(pdfRegularFont, 9, pdfMargin, writer.y-14, BankWebsite)
+	writer.y -= 50
+
+	writer.text(pdfBoldFont, 14, pdfMargin, writer.y, "Account statement")
+	writer.y -= 24
+
+	lastDay := statement.Until.AddDate(0, 0, -1)
+	details := [][2]string{
+		{"Account holder", writer.header.HolderName},
+		{"Email", writer.header.HolderEmail},
+		{"Account", fmt.Sprintf("%d (%s)", statement.AccountID, statement.Currency)},
+		{"Period", fmt.Sprintf("%s to %s", statement.Since.UTC().Format("02 Jan 2006"), lastDay.UTC().Format("02 Jan 2006"))},
+		{"Generated", writer.header.GeneratedAt.UTC().Format("02 Jan 2006 15:04 MST")},
+	}
+	for _, detail := range details {
+		writer.text(pdfBoldFont, 9, pdfMargin, writer.y, detail[0])
+		writer.text(pdfRegularFont, 9, pdfMargin+90, writer.y, detail[1])
+		writer.y -= pdfRowHeight
+	}
+	writer.y -= pdfRowHeight
+
+	balances := [][2]string{
+		{"Opening balance", util.FormatAmount(statement.OpeningBalance)},
+		{"Money in", util.FormatAmount(statement.TotalCredits)},
+		{"Money out", util.FormatAmount(statement.TotalDebits)},
+		{"Closing balance", util.FormatAmount(statement.ClosingBalance)},
+	}
+	for _, balance := range balances {
+		writer.text(pdfBoldFont, 9, pdfMargin, writer.y, balance[0])
+		writer.amount(9, pdfMargin+200, writer.y, balance[1])
+		writer.y -= pdfRowHeight
+	}
+	writer.y -= pdfRowHeight
+}
+
+func (writer *PDFWriter) writeColumnHeadings() {
+	writer.text(pdfBoldFont, 9, pdfMargin, writer.y, "Date")
+	writer.text(pdfBoldFont, 9, pdfMargin+70, writer.y, "Description")
+	writer.rightText(pdfMonoBoldFont, 9, pdfMoneyInRight, writer.y, "Money in")
+	writer.rightText(pdfMonoBoldFont, 9, pdfMoneyOutRight, writer.y, "Money out")
+	writer.rightText(pdfMonoBoldFont, 9, pdfBalanceRight, writer.y, "Balance")
+	fmt.Fprintf(&writer.content, "%.2f %.2f m %.2f %.2f l S\n",
+		pdfMargin, writer.y-4, pdfPageWidth-pdfMargin, writer.y-4)
+	writer.y -= 4
+}
+
+func (writer *PDFWriter) startPage() {
+	writer.content.Reset()
+	writer.y = pdfPageHeight - pdfMargin
+
+	footer := fmt.Sprintf("%s - account %d - page %d", BankName, writer.header.Statement.AccountID, len(writer.pages)+1)
+	writer.text(pdfRegularFont, 8, pdfMargin, pdfFooterHeight-30, footer)
+}
+
+// finishPage writes the current page's content stream and page object
+func (writer *PDFWriter) finishPage() error {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(writer.content.Bytes()); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	contentObject := len(writer.offsets)
+	pageObject := contentObject + 1
+	writer.offsets = append(writer.offsets, 0, 0)
+
+	err := writer.writeObject(contentObject, fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream",
+		compressed.Len(), compressed.Bytes()))
+	if err != nil {
+		return err
+	}
+
+	err = writer.writeObject(pageObject, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.0f %.0f] /Contents %d 0 R "+
+			"/Resources << /Font << /%s %d 0 R /%s %d 0 R /%s %d 0 R /%s %d 0 R >> >> >>",
+		pdfPagesObject, pdfPageWidth, pdfPageHeight, contentObject,
+		pdfRegularFont, pdfRegularFontObject, pdfBoldFont, pdfBoldFontObject,
+		pdfMonoFont, pdfMonoFontObject, pdfMonoBoldFont, pdfMonoBoldFontObject))
+	if err != nil {
+		return err
+	}
+
+	writer.pages = append(writer.pages, pageObject)
+	return nil
+}
+
+func (writer *PDFWriter) text(font string, size float64, x float64, y float64, text string) {
+	fmt.Fprintf(&writer.content, "BT /%s %.0f Tf %.2f %.2f Td %s Tj ET\n", font, size, x, y, pdfString(text))
+}
+
+// rightText writes text in one of the fixed width fonts so it ends at right
+func (writer *PDFWriter) rightText(font string, size float64, right float64, y float64, text string) {
+	width := float64(len([]rune(text))) * pdfMonoCharWidth * size
+	writer.text(font, size, right-width, y, text)
+}
+
+func (writer *PDFWriter) amount(size float64, right float64, y float64, amount string) {
+	writer.rightText(pdfMonoFont, size, right, y, amount)
+}
+
+func (writer *PDFWriter) writeObject(object int, body string) error {
+	writer.offsets[object] = writer.offset
+	return writer.write(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", object, body))
+}
+
+func (writer *PDFWriter) write(s string) error {
+	n, err := writer.w.WriteString(s)
+	writer.offset += int64(n)
+	return err
+}
+
+// pdfString is text as a PDF literal string in WinAnsiEncoding, characters it doesn't have are
+// replaced with a question mark
+func pdfString(text string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r <= 0x7e:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+func truncate(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length-3]) + "..."
+}
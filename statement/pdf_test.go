@@ -0,0 +1,64 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPDFWriter(t *testing.T) {
+	out := writeStatement(t, FormatPDF, randomHeader(), sampleLines())
+
+	require.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	require.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+	require.Contains(t, string(out), "/Count 1 >>")
+	requireValidXref(t, out)
+}
+
+func TestPDFWriterPages(t *testing.T) {
+	var lines []db.StatementLine
+	for i := 0; i < 200; i++ {
+		line := sampleLines()[i%3]
+		line.ID = int64(i + 1)
+		line.CreatedAt = line.CreatedAt.Add(time.Duration(i) * time.Minute)
+		lines = append(lines, line)
+	}
+
+	out := writeStatement(t, FormatPDF, randomHeader(), lines)
+
+	pages := regexp.MustCompile(`/Count (\d+) >>`).FindSubmatch(out)
+	require.NotNil(t, pages)
+	count, err := strconv.Atoi(string(pages[1]))
+	require.NoError(t, err)
+	require.Greater(t, count, 3)
+	require.Equal(t, count, bytes.Count(out, []byte("/Type /Page /Parent")))
+	requireValidXref(t, out)
+}
+
+func TestPDFString(t *testing.T) {
+	require.Equal(t, `(a \(b\) \\ c)`, pdfString(`a (b) \ c`))
+	require.Equal(t, "(caf\xe9 ?)", pdfString("café €"))
+}
+
+// requireValidXref checks every object in the xref table starts where the table says it does
+func requireValidXref(t *testing.T, out []byte) {
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(out)
+	require.NotNil(t, startxref)
+	xref, err := strconv.Atoi(string(startxref[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(out[xref:], []byte("xref\n")))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n \n`).FindAllSubmatch(out[xref:], -1)
+	require.NotEmpty(t, entries)
+	for i, entry := range entries {
+		offset, err := strconv.Atoi(string(entry[1]))
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(out[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))))
+	}
+}
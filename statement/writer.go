@@ -0,0 +1,137 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// Branding printed on exported statements
+const (
+	BankName    = "Maima Bank"
+	BankWebsite = "maimabank.com"
+	// identifies the bank to accounting tools importing OFX files
+	BankID = "MAIMABANK"
+	// identifies the bank in camt.053 and MT940 statements
+	BankBIC = "MAIMKENA"
+)
+
+// Formats statements can be exported in
+const (
+	FormatCSV = "csv"
+	FormatOFX = "ofx"
+	FormatPDF = "pdf"
+	// ISO 20022 bank to customer statement
+	FormatCamt053 = "camt053"
+	// SWIFT customer statement message
+	FormatMT940 = "mt940"
+)
+
+// Header is everything on a statement apart from its lines
+type Header struct {
+	Statement   db.AccountStatement
+	HolderName  string
+	HolderEmail string
+	GeneratedAt time.Time
+}
+
+// Writer writes a statement as it is read: the header first, then its lines one at a time,
+// and End once there are no more lines. Nothing but the current page of a PDF is held in memory.
+type Writer interface {
+	Begin(header Header) error
+	Line(line db.StatementLine) error
+	End() error
+}
+
+// NewWriter creates a writer for the format
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	case FormatOFX:
+		return NewOFXWriter(w), nil
+	case FormatPDF:
+		return NewPDFWriter(w), nil
+	case FormatCamt053:
+		return NewCamt053Writer(w), nil
+	case FormatMT940:
+		return NewMT940Writer(w), nil
+	}
+	return nil, fmt.Errorf("unsupported statement format %q", format)
+}
+
+// ContentType is the MIME type of a format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatOFX:
+		return "application/x-ofx"
+	case FormatPDF:
+		return "application/pdf"
+	case FormatCamt053:
+		return "application/xml"
+	case FormatMT940:
+		return "text/plain; charset=us-ascii"
+	}
+	return "application/octet-stream"
+}
+
+// FileExtension is the extension files of a format are saved with
+func FileExtension(format string) string {
+	switch format {
+	case FormatCamt053:
+		return "xml"
+	case FormatMT940:
+		return "sta"
+	}
+	return format
+}
+
+// Describe is what a statement line shows the account holder: their own description of the
+// transfer if they gave one, then its memo, then what kind of line it is
+func Describe(line db.StatementLine) string {
+	switch {
+	case line.Description != "":
+		return line.Description
+	case line.Memo != "":
+		return line.Memo
+	}
+
+	switch line.LineType {
+	case util.StatementLineFee:
+		return "Transfer fee"
+	case util.StatementLinePot:
+		if line.Amount < 0 {
+			return "Moved to pot"
+		}
+		return "Moved from pot"
+	case util.JournalKindInterest:
+		return "Interest"
+	case util.JournalKindAdjustment:
+		return "Adjustment"
+	}
+
+	if line.CounterpartyAccountID != 0 {
+		if line.Amount < 0 {
+			return fmt.Sprintf("%s to account %d", kindName(line.LineType), line.CounterpartyAccountID)
+		}
+		return fmt.Sprintf("%s from account %d", kindName(line.LineType), line.CounterpartyAccountID)
+	}
+	return kindName(line.LineType)
+}
+
+func kindName(kind string) string {
+	switch kind {
+	case util.TransferKindReversal:
+		return "Reversal"
+	case util.TransferKindRefund:
+		return "Refund"
+	case util.TransferKindTransfer:
+		return "Transfer"
+	}
+	return "Entry"
+}
@@ -0,0 +1,111 @@
+package statement
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// writeStatement writes a statement with the lines in format and returns the output
+func writeStatement(t *testing.T, format string, header Header, lines []db.StatementLine) []byte {
+	var buf bytes.Buffer
+	writer, err := NewWriter(format, &buf)
+	require.NoError(t, err)
+
+	require.NoError(t, writer.Begin(header))
+	for _, line := range lines {
+		require.NoError(t, writer.Line(line))
+	}
+	require.NoError(t, writer.End())
+	return buf.Bytes()
+}
+
+func randomHeader() Header {
+	return Header{
+		Statement: db.AccountStatement{
+			AccountID:      util.RandomInt(1, 1000),
+			Currency:       util.USD,
+			Since:          time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			Until:          time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			OpeningBalance: 10000,
+			TotalCredits:   2500,
+			TotalDebits:    1050,
+			ClosingBalance: 11450,
+		},
+		HolderName:  util.RandomOwner(),
+		HolderEmail: util.RandomEmail(),
+		GeneratedAt: time.Now(),
+	}
+}
+
+func sampleLines() []db.StatementLine {
+	createdAt := time.Date(2024, 3, 5, 10, 30, 0, 0, time.UTC)
+	return []db.StatementLine{
+		{
+			ListStatementEntriesRow: db.ListStatementEntriesRow{
+				ID:                    1,
+				Amount:                2500,
+				CreatedAt:             createdAt,
+				TransferID:            sql.NullInt64{Int64: 7, Valid: true},
+				LineType:              util.TransferKindTransfer,
+				CounterpartyAccountID: 12,
+				Memo:                  "Salary, March",
+				Reference:             "REF-1",
+			},
+			Balance: 12500,
+		},
+		{
+			ListStatementEntriesRow: db.ListStatementEntriesRow{
+				ID:                    2,
+				Amount:                -1000,
+				CreatedAt:             createdAt.Add(time.Hour),
+				TransferID:            sql.NullInt64{Int64: 8, Valid: true},
+				LineType:              util.TransferKindTransfer,
+				CounterpartyAccountID: 13,
+				Description:           "Rent <March> & bills",
+			},
+			Balance: 11500,
+		},
+		{
+			ListStatementEntriesRow: db.ListStatementEntriesRow{
+				ID:         3,
+				Amount:     -50,
+				CreatedAt:  createdAt.Add(time.Hour),
+				TransferID: sql.NullInt64{Int64: 8, Valid: true},
+				LineType:   util.StatementLineFee,
+			},
+			Balance: 11450,
+		},
+	}
+}
+
+func TestNewWriterUnsupportedFormat(t *testing.T) {
+	_, err := NewWriter("xls", &bytes.Buffer{})
+	require.Error(t, err)
+}
+
+func TestDescribe(t *testing.T) {
+	lines := sampleLines()
+	require.Equal(t, "Salary, March", Describe(lines[0]))
+	require.Equal(t, "Rent <March> & bills", Describe(lines[1]))
+	require.Equal(t, "Transfer fee", Describe(lines[2]))
+
+	line := db.StatementLine{ListStatementEntriesRow: db.ListStatementEntriesRow{
+		Amount:                -10,
+		LineType:              util.TransferKindTransfer,
+		CounterpartyAccountID: 5,
+	}}
+	require.Equal(t, "Transfer to account 5", Describe(line))
+
+	line.Amount = 10
+	line.LineType = util.TransferKindRefund
+	require.Equal(t, "Refund from account 5", Describe(line))
+
+	line.LineType = util.StatementLinePot
+	require.Equal(t, "Moved from pot", Describe(line))
+}
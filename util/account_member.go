@@ -0,0 +1,40 @@
+package util
+
+// Roles a user can have on an account. The owner is whoever accounts.owner names,
+// everyone else is invited with one of the other roles.
+const (
+	AccountRoleOwner   = "owner"
+	AccountRoleCoOwner = "co_owner"
+	AccountRoleViewer  = "viewer"
+	AccountRolePayer   = "payer"
+)
+
+// What a role can do with an account
+const (
+	AccountPermissionView   = "view"
+	AccountPermissionPay    = "pay"
+	AccountPermissionManage = "manage"
+)
+
+// Check if a role can be given to an invited account member
+func IsSupportedAccountRole(role string) bool {
+	switch role {
+	case AccountRoleCoOwner, AccountRoleViewer, AccountRolePayer:
+		return true
+	}
+	return false
+}
+
+// AccountRoleAllows reports whether role grants permission, owners and co-owners can do everything,
+// payers can view and pay and viewers can only view
+func AccountRoleAllows(role string, permission string) bool {
+	switch role {
+	case AccountRoleOwner, AccountRoleCoOwner:
+		return true
+	case AccountRolePayer:
+		return permission == AccountPermissionView || permission == AccountPermissionPay
+	case AccountRoleViewer:
+		return permission == AccountPermissionView
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountRoleAllows(t *testing.T) {
+	testCases := []struct {
+		role   string
+		view   bool
+		pay    bool
+		manage bool
+	}{
+		{role: AccountRoleOwner, view: true, pay: true, manage: true},
+		{role: AccountRoleCoOwner, view: true, pay: true, manage: true},
+		{role: AccountRolePayer, view: true, pay: true},
+		{role: AccountRoleViewer, view: true},
+		{role: ""},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.view, AccountRoleAllows(tc.role, AccountPermissionView), tc.role)
+		require.Equal(t, tc.pay, AccountRoleAllows(tc.role, AccountPermissionPay), tc.role)
+		require.Equal(t, tc.manage, AccountRoleAllows(tc.role, AccountPermissionManage), tc.role)
+	}
+
+	require.False(t, IsSupportedAccountRole(AccountRoleOwner))
+	require.True(t, IsSupportedAccountRole(AccountRolePayer))
+}
@@ -0,0 +1,30 @@
+package util
+
+// Account types, customer accounts are personal and the bank's own accounts
+// make up the rest of its chart of accounts, one of each type per currency
+const (
+	PersonalAccount        = "personal"
+	FeeIncomeAccount       = "fee_income"
+	FXPositionAccount      = "fx_position"
+	InterestExpenseAccount = "interest_expense"
+	SuspenseAccount        = "suspense"
+	PotHoldingAccount      = "pot_holding"
+)
+
+// Check if an account type is known to our banking service
+func IsSupportedAccountType(accountType string) bool {
+	switch accountType {
+	case PersonalAccount:
+		return true
+	}
+	return IsInternalAccountType(accountType)
+}
+
+// IsInternalAccountType reports whether accounts of the type are the bank's own general ledger accounts
+func IsInternalAccountType(accountType string) bool {
+	switch accountType {
+	case FeeIncomeAccount, FXPositionAccount, InterestExpenseAccount, SuspenseAccount, PotHoldingAccount:
+		return true
+	}
+	return false
+}
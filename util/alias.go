@@ -0,0 +1,67 @@
+package util
+
+import (
+	"errors"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Kinds of alias a customer can be paid by instead of an account ID
+const (
+	AliasTypeUsername = "username"
+	AliasTypeEmail    = "email"
+	AliasTypePhone    = "phone"
+)
+
+var (
+	ErrInvalidAlias = errors.New("alias must be a username, an email address or a phone number starting with +")
+	phonePattern    = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+)
+
+// ParseAlias works out what kind of alias value is and normalizes it: emails are lower cased
+// and phone numbers are stripped down to E.164, e.g. "+254 712-345 678" becomes "+254712345678"
+func ParseAlias(value string) (aliasType string, normalized string, err error) {
+	value = strings.TrimSpace(value)
+
+	switch {
+	case strings.Contains(value, "@"):
+		address, err := mail.ParseAddress(value)
+		if err != nil || address.Address != value {
+			return "", "", ErrInvalidAlias
+		}
+		return AliasTypeEmail, strings.ToLower(value), nil
+	case strings.HasPrefix(value, "+"):
+		phone := strings.Map(func(r rune) rune {
+			if r == ' ' || r == '-' || r == '(' || r == ')' {
+				return -1
+			}
+			return r
+		}, value)
+		if !phonePattern.MatchString(phone) {
+			return "", "", ErrInvalidAlias
+		}
+		return AliasTypePhone, phone, nil
+	case usernamePattern.MatchString(value):
+		return AliasTypeUsername, value, nil
+	}
+
+	return "", "", ErrInvalidAlias
+}
+
+// MaskName hides all but the first letter of each part of a name, e.g. "John Doe" becomes "J*** D**",
+// enough for a payer to recognise who they are paying without giving the name away
+func MaskName(name string) string {
+	parts := strings.Fields(name)
+	for i, part := range parts {
+		runes := []rune(part)
+		masked := []rune{unicode.ToUpper(runes[0])}
+		for range runes[1:] {
+			masked = append(masked, '*')
+		}
+		parts[i] = string(masked)
+	}
+	return strings.Join(parts, " ")
+}
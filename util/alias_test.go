@@ -0,0 +1,45 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAlias(t *testing.T) {
+	testCases := []struct {
+		value      string
+		aliasType  string
+		normalized string
+		valid      bool
+	}{
+		{"Jane.Doe@Example.com", AliasTypeEmail, "jane.doe@example.com", true},
+		{"+254 712-345 678", AliasTypePhone, "+254712345678", true},
+		{"+1 (415) 555-0100", AliasTypePhone, "+14155550100", true},
+		{" janedoe ", AliasTypeUsername, "janedoe", true},
+		{"Jane Doe <jane@example.com>", "", "", false},
+		{"0712345678@", "", "", false},
+		{"+0712345678", "", "", false},
+		{"+12", "", "", false},
+		{"jane_doe", "", "", false},
+		{"", "", "", false},
+	}
+
+	for _, tc := range testCases {
+		aliasType, normalized, err := ParseAlias(tc.value)
+		if !tc.valid {
+			require.ErrorIs(t, err, ErrInvalidAlias, tc.value)
+			continue
+		}
+		require.NoError(t, err, tc.value)
+		require.Equal(t, tc.aliasType, aliasType)
+		require.Equal(t, tc.normalized, normalized)
+	}
+}
+
+func TestMaskName(t *testing.T) {
+	require.Equal(t, "J*** D**", MaskName("John Doe"))
+	require.Equal(t, "M****** M****", MaskName("malcolm  maima"))
+	require.Equal(t, "Z**", MaskName("Zoë"))
+	require.Equal(t, "", MaskName(""))
+}
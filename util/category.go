@@ -0,0 +1,28 @@
+package util
+
+// Transfer categories customers can file their transfers under
+const (
+	CategoryGroceries     = "groceries"
+	CategoryBills         = "bills"
+	CategoryTransport     = "transport"
+	CategoryShopping      = "shopping"
+	CategoryEntertainment = "entertainment"
+	CategoryHealth        = "health"
+	CategoryEducation     = "education"
+	CategoryRent          = "rent"
+	CategorySalary        = "salary"
+	CategorySavings       = "savings"
+	CategoryTransfers     = "transfers"
+	CategoryOther         = "other"
+)
+
+// Check if a transfer category is known to our banking service
+func IsSupportedCategory(category string) bool {
+	switch category {
+	case CategoryGroceries, CategoryBills, CategoryTransport, CategoryShopping, CategoryEntertainment,
+		CategoryHealth, CategoryEducation, CategoryRent, CategorySalary, CategorySavings,
+		CategoryTransfers, CategoryOther:
+		return true
+	}
+	return false
+}
@@ -13,6 +13,20 @@ type Config struct {
 	TokenSymmetricKey string `mapstructure:"TOKEN_SYMMETRIC_KEY"`
 	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
 	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	HoldDuration time.Duration `mapstructure:"HOLD_DURATION"`
+	HoldSweepInterval time.Duration `mapstructure:"HOLD_SWEEP_INTERVAL"`
+	ScheduledTransferInterval time.Duration `mapstructure:"SCHEDULED_TRANSFER_INTERVAL"`
+	TransferBatchInterval time.Duration `mapstructure:"TRANSFER_BATCH_INTERVAL"`
+	AliasCodeDuration time.Duration `mapstructure:"ALIAS_CODE_DURATION"`
+	BeneficiaryCoolingOff time.Duration `mapstructure:"BENEFICIARY_COOLING_OFF"`
+	TransferConfirmationDuration time.Duration `mapstructure:"TRANSFER_CONFIRMATION_DURATION"`
+	PaymentRequestDuration time.Duration `mapstructure:"PAYMENT_REQUEST_DURATION"`
+	PaymentRequestSweepInterval time.Duration `mapstructure:"PAYMENT_REQUEST_SWEEP_INTERVAL"`
+	LedgerSigningKey string `mapstructure:"LEDGER_SIGNING_KEY"`
+	LedgerSealInterval time.Duration `mapstructure:"LEDGER_SEAL_INTERVAL"`
+	BalanceSnapshotInterval time.Duration `mapstructure:"BALANCE_SNAPSHOT_INTERVAL"`
+	OutboxRelayInterval time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"`
+	EventLogFile string `mapstructure:"EVENT_LOG_FILE"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -1,5 +1,7 @@
 package util
 
+import "fmt"
+
 // All supported currencies in our banking service
 const (
 	USD = "USD"
@@ -15,4 +17,15 @@ func IsSupportedCurrency(currency string) bool {
 		return true
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// FormatAmount formats an amount in minor units as a decimal, e.g. -1234 as -12.34.
+// Every supported currency has 2 decimal places.
+func FormatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
@@ -0,0 +1,15 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatAmount(t *testing.T) {
+	require.Equal(t, "0.00", FormatAmount(0))
+	require.Equal(t, "0.05", FormatAmount(5))
+	require.Equal(t, "12.34", FormatAmount(1234))
+	require.Equal(t, "-12.34", FormatAmount(-1234))
+	require.Equal(t, "-0.50", FormatAmount(-50))
+}
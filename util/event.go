@@ -0,0 +1,17 @@
+package util
+
+// Types of domain event written to the outbox
+const (
+	EventTransferCompleted = "TransferCompleted"
+	EventAccountCreated    = "AccountCreated"
+	EventUserCreated       = "UserCreated"
+	EventRateUpdated       = "RateUpdated"
+)
+
+// Aggregates a domain event can be about
+const (
+	AggregateTransfer     = "transfer"
+	AggregateAccount      = "account"
+	AggregateUser         = "user"
+	AggregateExchangeRate = "exchange_rate"
+)
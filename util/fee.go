@@ -0,0 +1,30 @@
+package util
+
+// Transfer types a fee schedule can apply to
+const (
+	TransferTypeTransfer = "transfer"
+	TransferTypeFX       = "fx"
+)
+
+// Check if a transfer type is one we charge fees on
+func IsSupportedTransferType(transferType string) bool {
+	switch transferType {
+	case TransferTypeTransfer, TransferTypeFX:
+		return true
+	}
+	return false
+}
+
+// CalculateFee returns the fee for amount as flatFee plus percentageBps basis points of amount,
+// clamped to minFee and, when it is above zero, to maxFee.
+func CalculateFee(amount, flatFee, percentageBps, minFee, maxFee int64) int64 {
+	fee := flatFee + amount*percentageBps/10000
+
+	if fee < minFee {
+		fee = minFee
+	}
+	if maxFee > 0 && fee > maxFee {
+		fee = maxFee
+	}
+	return fee
+}
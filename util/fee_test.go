@@ -0,0 +1,37 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateFee(t *testing.T) {
+	testCases := []struct {
+		name          string
+		amount        int64
+		flatFee       int64
+		percentageBps int64
+		minFee        int64
+		maxFee        int64
+		fee           int64
+	}{
+		{name: "NoFee", amount: 1000},
+		{name: "Flat", amount: 1000, flatFee: 25, fee: 25},
+		{name: "Percentage", amount: 1000, percentageBps: 150, fee: 15},
+		{name: "FlatAndPercentage", amount: 1000, flatFee: 10, percentageBps: 100, fee: 20},
+		{name: "RoundsDown", amount: 999, percentageBps: 100, fee: 9},
+		{name: "MinFee", amount: 100, percentageBps: 100, minFee: 5, fee: 5},
+		{name: "MaxFee", amount: 1000000, percentageBps: 100, maxFee: 500, fee: 500},
+		{name: "ZeroMaxFeeIsUncapped", amount: 1000000, percentageBps: 100, fee: 10000},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			fee := CalculateFee(tc.amount, tc.flatFee, tc.percentageBps, tc.minFee, tc.maxFee)
+			require.Equal(t, tc.fee, fee)
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package util
+
+// Statuses of a hold placed by a two-phase transfer
+const (
+	HoldStatusPending           = "pending"
+	HoldStatusCaptured          = "captured"
+	HoldStatusPartiallyCaptured = "partially_captured"
+	HoldStatusVoided            = "voided"
+	HoldStatusExpired           = "expired"
+)
@@ -0,0 +1,17 @@
+package util
+
+// Kinds of journal, a transfer's journal takes the kind of the transfer
+const (
+	JournalKindPot        = "pot"
+	JournalKindInterest   = "interest"
+	JournalKindAdjustment = "adjustment"
+)
+
+// IsManualJournalKind reports whether ops staff can post journals of the kind by hand
+func IsManualJournalKind(kind string) bool {
+	switch kind {
+	case JournalKindInterest, JournalKindAdjustment:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package util
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"fmt"
+)
+
+// MerkleRoot computes the root of a Merkle tree over the leaves in order. Leaves and inner nodes
+// are hashed with different prefixes so a leaf can never pass for a node (as in RFC 6962), and
+// the last node of an odd level is carried up as is. The root of no leaves is the hash of nothing.
+func MerkleRoot(leaves [][]byte) []byte {
+	if len(leaves) == 0 {
+		root := sha256.Sum256(nil)
+		return root[:]
+	}
+
+	level := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = merkleHash(0x00, leaf)
+	}
+
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+				continue
+			}
+			next = append(next, merkleHash(0x01, level[i], level[i+1]))
+		}
+		level = next
+	}
+
+	return level[0]
+}
+
+func merkleHash(prefix byte, parts ...[]byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{prefix})
+	for _, part := range parts {
+		h.Write(part)
+	}
+	return h.Sum(nil)
+}
+
+// ParseLedgerSigningKey turns the configured seed into the key ledger seals are signed with.
+// An empty seed means seals aren't signed.
+func ParseLedgerSigningKey(seed string) (ed25519.PrivateKey, error) {
+	if seed == "" {
+		return nil, nil
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid ledger signing key size: must be exactly %d characters", ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed([]byte(seed)), nil
+}
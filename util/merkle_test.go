@@ -0,0 +1,46 @@
+package util
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerkleRoot(t *testing.T) {
+	leaves := [][]byte{[]byte("a"), []byte("b"), []byte("c")}
+
+	leaf := func(data []byte) []byte {
+		return merkleHash(0x00, data)
+	}
+	expected := merkleHash(0x01, merkleHash(0x01, leaf(leaves[0]), leaf(leaves[1])), leaf(leaves[2]))
+	require.Equal(t, expected, MerkleRoot(leaves))
+
+	// a single leaf is still hashed as a leaf
+	require.Equal(t, leaf(leaves[0]), MerkleRoot(leaves[:1]))
+
+	empty := sha256.Sum256(nil)
+	require.Equal(t, empty[:], MerkleRoot(nil))
+
+	// changing, dropping or reordering a leaf changes the root
+	require.NotEqual(t, expected, MerkleRoot([][]byte{[]byte("a"), []byte("b"), []byte("d")}))
+	require.NotEqual(t, expected, MerkleRoot(leaves[:2]))
+	require.NotEqual(t, expected, MerkleRoot([][]byte{[]byte("b"), []byte("a"), []byte("c")}))
+}
+
+func TestParseLedgerSigningKey(t *testing.T) {
+	key, err := ParseLedgerSigningKey("")
+	require.NoError(t, err)
+	require.Nil(t, key)
+
+	_, err = ParseLedgerSigningKey("too short")
+	require.Error(t, err)
+
+	key, err = ParseLedgerSigningKey(RandomString(ed25519.SeedSize))
+	require.NoError(t, err)
+
+	root := MerkleRoot([][]byte{[]byte("a")})
+	signature := ed25519.Sign(key, root)
+	require.True(t, ed25519.Verify(key.Public().(ed25519.PublicKey), root, signature))
+}
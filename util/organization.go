@@ -0,0 +1,46 @@
+package util
+
+// Roles a user can have in an organization. Admins manage members and accounts,
+// approvers sign off transfers others initiated and initiators can only start transfers.
+const (
+	OrganizationRoleAdmin     = "admin"
+	OrganizationRoleApprover  = "approver"
+	OrganizationRoleInitiator = "initiator"
+)
+
+// What a role can do in an organization
+const (
+	OrganizationPermissionInitiate = "initiate"
+	OrganizationPermissionApprove  = "approve"
+	OrganizationPermissionManage   = "manage"
+)
+
+// Statuses of an organization transfer
+const (
+	OrganizationTransferStatusPending  = "pending_approval"
+	OrganizationTransferStatusExecuted = "executed"
+	OrganizationTransferStatusRejected = "rejected"
+)
+
+// Check if a role is supported for organization members
+func IsSupportedOrganizationRole(role string) bool {
+	switch role {
+	case OrganizationRoleAdmin, OrganizationRoleApprover, OrganizationRoleInitiator:
+		return true
+	}
+	return false
+}
+
+// OrganizationRoleAllows reports whether role grants permission, admins can do everything,
+// approvers can initiate and approve and initiators can only initiate
+func OrganizationRoleAllows(role string, permission string) bool {
+	switch role {
+	case OrganizationRoleAdmin:
+		return true
+	case OrganizationRoleApprover:
+		return permission == OrganizationPermissionInitiate || permission == OrganizationPermissionApprove
+	case OrganizationRoleInitiator:
+		return permission == OrganizationPermissionInitiate
+	}
+	return false
+}
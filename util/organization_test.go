@@ -0,0 +1,30 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationRoleAllows(t *testing.T) {
+	testCases := []struct {
+		role     string
+		initiate bool
+		approve  bool
+		manage   bool
+	}{
+		{role: OrganizationRoleAdmin, initiate: true, approve: true, manage: true},
+		{role: OrganizationRoleApprover, initiate: true, approve: true},
+		{role: OrganizationRoleInitiator, initiate: true},
+		{role: ""},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.initiate, OrganizationRoleAllows(tc.role, OrganizationPermissionInitiate), tc.role)
+		require.Equal(t, tc.approve, OrganizationRoleAllows(tc.role, OrganizationPermissionApprove), tc.role)
+		require.Equal(t, tc.manage, OrganizationRoleAllows(tc.role, OrganizationPermissionManage), tc.role)
+	}
+
+	require.True(t, IsSupportedOrganizationRole(OrganizationRoleApprover))
+	require.False(t, IsSupportedOrganizationRole(AccountRoleCoOwner))
+}
@@ -0,0 +1,13 @@
+package util
+
+// RoundUpUnit is the amount, in minor units, outgoing transfers are rounded up to
+const RoundUpUnit = 100
+
+// RoundUp returns what it takes to bring amount up to the next whole RoundUpUnit,
+// 0 if it already is one
+func RoundUp(amount int64) int64 {
+	if amount <= 0 {
+		return 0
+	}
+	return (RoundUpUnit - amount%RoundUpUnit) % RoundUpUnit
+}
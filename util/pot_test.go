@@ -0,0 +1,24 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundUp(t *testing.T) {
+	testCases := []struct {
+		amount  int64
+		roundUp int64
+	}{
+		{amount: 1, roundUp: 99},
+		{amount: 250, roundUp: 50},
+		{amount: 399, roundUp: 1},
+		{amount: 400, roundUp: 0},
+		{amount: 0, roundUp: 0},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.roundUp, RoundUp(tc.amount), tc.amount)
+	}
+}
@@ -0,0 +1,16 @@
+package util
+
+// User roles, ops staff can reverse customers' transfers
+const (
+	CustomerRole = "customer"
+	OpsRole      = "ops"
+)
+
+// Check if a user role is known to our banking service
+func IsSupportedRole(role string) bool {
+	switch role {
+	case CustomerRole, OpsRole:
+		return true
+	}
+	return false
+}
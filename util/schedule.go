@@ -0,0 +1,74 @@
+package util
+
+import "time"
+
+// How often a scheduled transfer runs
+const (
+	FrequencyOnce       = "once"
+	FrequencyDaily      = "daily"
+	FrequencyWeekly     = "weekly"
+	FrequencyMonthly    = "monthly"
+	FrequencyEndOfMonth = "end_of_month"
+)
+
+// Statuses of a scheduled transfer
+const (
+	ScheduleStatusActive    = "active"
+	ScheduleStatusPaused    = "paused"
+	ScheduleStatusCompleted = "completed"
+	ScheduleStatusFailed    = "failed"
+)
+
+// Check if a schedule frequency is supported by our banking service
+func IsSupportedFrequency(frequency string) bool {
+	switch frequency {
+	case FrequencyOnce, FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyEndOfMonth:
+		return true
+	}
+	return false
+}
+
+// NextRun returns when a schedule runs after the run scheduled for prev, keeping the time of day.
+// Monthly schedules run on dayOfMonth, or the last day of shorter months.
+// The second return value is false for one-off schedules, which never run again.
+func NextRun(frequency string, dayOfMonth int, prev time.Time) (time.Time, bool) {
+	switch frequency {
+	case FrequencyDaily:
+		return prev.AddDate(0, 0, 1), true
+	case FrequencyWeekly:
+		return prev.AddDate(0, 0, 7), true
+	case FrequencyMonthly:
+		year, month, _ := prev.Date()
+		return dateInMonth(year, month+1, dayOfMonth, prev), true
+	case FrequencyEndOfMonth:
+		year, month, _ := prev.Date()
+		return dateInMonth(year, month+1, 31, prev), true
+	}
+	return time.Time{}, false
+}
+
+// FirstRun moves start onto the day a schedule runs, start itself for anything but monthly schedules
+func FirstRun(frequency string, dayOfMonth int, start time.Time) time.Time {
+	year, month, day := start.Date()
+	switch frequency {
+	case FrequencyMonthly:
+		first := dateInMonth(year, month, dayOfMonth, start)
+		if first.Day() < day {
+			return dateInMonth(year, month+1, dayOfMonth, start)
+		}
+		return first
+	case FrequencyEndOfMonth:
+		return dateInMonth(year, month, 31, start)
+	}
+	return start
+}
+
+// dateInMonth is day of month, clamped to the month's last day, at the time of day of clock
+func dateInMonth(year int, month time.Month, day int, clock time.Time) time.Time {
+	// day 0 of the following month is the last day of this one
+	lastDay := time.Date(year, month+1, 0, 0, 0, 0, 0, clock.Location()).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(year, month, day, clock.Hour(), clock.Minute(), clock.Second(), clock.Nanosecond(), clock.Location())
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextRun(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		name       string
+		frequency  string
+		dayOfMonth int
+		prev       time.Time
+		next       time.Time
+		ok         bool
+	}{
+		{name: "Once", frequency: FrequencyOnce, prev: date(2023, 1, 15)},
+		{name: "Daily", frequency: FrequencyDaily, prev: date(2023, 2, 28), next: date(2023, 3, 1), ok: true},
+		{name: "Weekly", frequency: FrequencyWeekly, prev: date(2023, 12, 29), next: date(2024, 1, 5), ok: true},
+		{name: "Monthly", frequency: FrequencyMonthly, dayOfMonth: 15, prev: date(2023, 1, 15), next: date(2023, 2, 15), ok: true},
+		{name: "MonthlyClamped", frequency: FrequencyMonthly, dayOfMonth: 31, prev: date(2023, 1, 31), next: date(2023, 2, 28), ok: true},
+		{name: "MonthlyAfterClamp", frequency: FrequencyMonthly, dayOfMonth: 31, prev: date(2023, 2, 28), next: date(2023, 3, 31), ok: true},
+		{name: "MonthlyLeapYear", frequency: FrequencyMonthly, dayOfMonth: 30, prev: date(2024, 1, 30), next: date(2024, 2, 29), ok: true},
+		{name: "EndOfMonth", frequency: FrequencyEndOfMonth, prev: date(2023, 3, 31), next: date(2023, 4, 30), ok: true},
+		{name: "EndOfYear", frequency: FrequencyEndOfMonth, prev: date(2023, 12, 31), next: date(2024, 1, 31), ok: true},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			next, ok := NextRun(tc.frequency, tc.dayOfMonth, tc.prev)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.next, next)
+		})
+	}
+}
+
+func TestFirstRun(t *testing.T) {
+	start := time.Date(2023, 1, 20, 8, 0, 0, 0, time.UTC)
+
+	require.Equal(t, start, FirstRun(FrequencyDaily, 0, start))
+	require.Equal(t, time.Date(2023, 1, 25, 8, 0, 0, 0, time.UTC), FirstRun(FrequencyMonthly, 25, start))
+	require.Equal(t, time.Date(2023, 2, 5, 8, 0, 0, 0, time.UTC), FirstRun(FrequencyMonthly, 5, start))
+	require.Equal(t, time.Date(2023, 1, 31, 8, 0, 0, 0, time.UTC), FirstRun(FrequencyEndOfMonth, 0, start))
+}
@@ -0,0 +1,9 @@
+package util
+
+// Types of statement line besides the transfer and journal kinds
+const (
+	StatementLineFee = "fee"
+	StatementLinePot = "pot"
+	// entries from before journals that aren't part of a transfer
+	StatementLineEntry = "entry"
+)
@@ -0,0 +1,17 @@
+package util
+
+// User tiers, transfer limits are configured per tier
+const (
+	StandardTier = "standard"
+	PremiumTier  = "premium"
+	BusinessTier = "business"
+)
+
+// Check if a user tier is known to our banking service
+func IsSupportedTier(tier string) bool {
+	switch tier {
+	case StandardTier, PremiumTier, BusinessTier:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package util
+
+// Kinds of transfer, reversals and refunds compensate an earlier transfer
+const (
+	TransferKindTransfer = "transfer"
+	TransferKindReversal = "reversal"
+	TransferKindRefund   = "refund"
+)
+
+// Statuses of a transfer as it gets reversed or refunded
+const (
+	TransferStatusCompleted         = "completed"
+	TransferStatusPartiallyReversed = "partially_reversed"
+	TransferStatusReversed          = "reversed"
+	TransferStatusPartiallyRefunded = "partially_refunded"
+	TransferStatusRefunded          = "refunded"
+)
+
+// ReversedTransferStatus is the status of a transfer once reversed of its amount
+// has been sent back by a reversal or refund
+func ReversedTransferStatus(kind string, amount int64, reversed int64) string {
+	if kind == TransferKindRefund {
+		if reversed < amount {
+			return TransferStatusPartiallyRefunded
+		}
+		return TransferStatusRefunded
+	}
+	if reversed < amount {
+		return TransferStatusPartiallyReversed
+	}
+	return TransferStatusReversed
+}
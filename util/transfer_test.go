@@ -0,0 +1,14 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReversedTransferStatus(t *testing.T) {
+	require.Equal(t, TransferStatusPartiallyReversed, ReversedTransferStatus(TransferKindReversal, 10, 4))
+	require.Equal(t, TransferStatusReversed, ReversedTransferStatus(TransferKindReversal, 10, 10))
+	require.Equal(t, TransferStatusPartiallyRefunded, ReversedTransferStatus(TransferKindRefund, 10, 1))
+	require.Equal(t, TransferStatusRefunded, ReversedTransferStatus(TransferKindRefund, 10, 10))
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+)
+
+// entries are only snapshotted once they are this old, so a transaction still in flight can't
+// commit an entry behind a snapshot
+const balanceSnapshotDelay = time.Minute
+
+// BalanceSnapshotter periodically snapshots the balance of every account with new entries,
+// so balances at a point in time only have to add up the entries since the last snapshot
+type BalanceSnapshotter struct {
+	store    db.Store
+	interval time.Duration
+}
+
+// NewBalanceSnapshotter creates a new balance snapshotter
+func NewBalanceSnapshotter(store db.Store, interval time.Duration) *BalanceSnapshotter {
+	return &BalanceSnapshotter{
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Start snapshots every interval until ctx is cancelled
+func (snapshotter *BalanceSnapshotter) Start(ctx context.Context) {
+	if snapshotter.interval <= 0 {
+		log.Println("balance snapshotter disabled, no snapshot interval configured")
+		return
+	}
+
+	ticker := time.NewTicker(snapshotter.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := snapshotter.Snapshot(ctx, time.Now()); err != nil {
+				log.Println("cannot snapshot balances: ", err)
+			}
+		}
+	}
+}
+
+// Snapshot snapshots the accounts with entries since their last snapshot and returns how many it snapshotted
+func (snapshotter *BalanceSnapshotter) Snapshot(ctx context.Context, now time.Time) (int64, error) {
+	return snapshotter.store.CreateBalanceSnapshots(ctx, now.Add(-balanceSnapshotDelay))
+}
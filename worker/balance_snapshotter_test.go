@@ -0,0 +1,26 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/malcolmmaima/maimabank/db/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceSnapshotterSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CreateBalanceSnapshots(gomock.Any(), gomock.Eq(now.Add(-balanceSnapshotDelay))).Times(1).Return(int64(3), nil)
+
+	snapshotter := NewBalanceSnapshotter(store, time.Hour)
+	snapshotted, err := snapshotter.Snapshot(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), snapshotted)
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+)
+
+// number of expired holds released per sweep
+const holdSweepBatchSize = 100
+
+// HoldSweeper periodically releases holds that were never captured or voided
+type HoldSweeper struct {
+	store    db.Store
+	interval time.Duration
+}
+
+// NewHoldSweeper creates a new hold sweeper
+func NewHoldSweeper(store db.Store, interval time.Duration) *HoldSweeper {
+	return &HoldSweeper{
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Start sweeps every interval until ctx is cancelled
+func (sweeper *HoldSweeper) Start(ctx context.Context) {
+	if sweeper.interval <= 0 {
+		log.Println("hold sweeper disabled, no sweep interval configured")
+		return
+	}
+
+	ticker := time.NewTicker(sweeper.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := sweeper.Sweep(ctx); err != nil {
+				log.Println("cannot expire holds: ", err)
+			}
+		}
+	}
+}
+
+// Sweep expires holds in batches until there are none left and returns how many were expired
+func (sweeper *HoldSweeper) Sweep(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		expired, err := sweeper.store.ExpireHolds(ctx, holdSweepBatchSize)
+		total += expired
+		if err != nil {
+			return total, err
+		}
+		if expired < holdSweepBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/malcolmmaima/maimabank/db/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHoldSweeperSweep(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().ExpireHolds(gomock.Any(), gomock.Eq(int32(holdSweepBatchSize))).Times(1).Return(holdSweepBatchSize, nil),
+		store.EXPECT().ExpireHolds(gomock.Any(), gomock.Eq(int32(holdSweepBatchSize))).Times(1).Return(3, nil),
+	)
+
+	sweeper := NewHoldSweeper(store, time.Minute)
+	expired, err := sweeper.Sweep(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, holdSweepBatchSize+3, expired)
+}
+
+func TestHoldSweeperSweepError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ExpireHolds(gomock.Any(), gomock.Any()).Times(1).Return(2, sql.ErrConnDone)
+
+	sweeper := NewHoldSweeper(store, time.Minute)
+	expired, err := sweeper.Sweep(context.Background())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	require.Equal(t, 2, expired)
+}
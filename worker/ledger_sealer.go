@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+)
+
+// a day is only sealed once it has been over for this long, so transactions that started
+// just before midnight have committed
+const ledgerSealDelay = time.Hour
+
+// LedgerSealer periodically seals every finished day of the ledger that isn't sealed yet
+type LedgerSealer struct {
+	store    db.Store
+	key      ed25519.PrivateKey
+	interval time.Duration
+}
+
+// NewLedgerSealer creates a new ledger sealer, seals are left unsigned if key is nil
+func NewLedgerSealer(store db.Store, key ed25519.PrivateKey, interval time.Duration) *LedgerSealer {
+	return &LedgerSealer{
+		store:    store,
+		key:      key,
+		interval: interval,
+	}
+}
+
+// Start seals every interval until ctx is cancelled
+func (sealer *LedgerSealer) Start(ctx context.Context) {
+	if sealer.interval <= 0 {
+		log.Println("ledger sealer disabled, no seal interval configured")
+		return
+	}
+
+	ticker := time.NewTicker(sealer.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := sealer.Seal(ctx, time.Now()); err != nil {
+				log.Println("cannot seal ledger: ", err)
+			}
+		}
+	}
+}
+
+// Seal seals the days after the last seal that were over by now and returns how many were sealed.
+// Sealing starts from the day of the first hashed entry.
+func (sealer *LedgerSealer) Seal(ctx context.Context, now time.Time) (int, error) {
+	var day time.Time
+	last, err := sealer.store.GetLastLedgerSeal(ctx)
+	switch {
+	case err == nil:
+		day = last.Day.AddDate(0, 0, 1)
+	case errors.Is(err, sql.ErrNoRows):
+		first, err := sealer.store.GetFirstHashedEntryTime(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		day = db.LedgerDay(first)
+	default:
+		return 0, err
+	}
+
+	sealed := 0
+	until := db.LedgerDay(now.Add(-ledgerSealDelay))
+	for day = db.LedgerDay(day); day.Before(until); day = day.AddDate(0, 0, 1) {
+		if _, err := sealer.store.SealLedgerDay(ctx, day, sealer.key); err != nil {
+			return sealed, err
+		}
+		sealed++
+	}
+	return sealed, nil
+}
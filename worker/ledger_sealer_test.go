@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/malcolmmaima/maimabank/db/mock"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerSealerSeal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	lastDay := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 3, 4, 0, 30, 0, 0, time.UTC)
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetLastLedgerSeal(gomock.Any()).Times(1).Return(db.LedgerSeal{Day: lastDay}, nil)
+	store.EXPECT().GetFirstHashedEntryTime(gomock.Any()).Times(0)
+	// the 3rd of March ended less than an hour ago, so only the 2nd is sealed
+	store.EXPECT().SealLedgerDay(gomock.Any(), gomock.Eq(lastDay.AddDate(0, 0, 1)), gomock.Nil()).Times(1).
+		Return(db.LedgerSeal{}, nil)
+
+	sealer := NewLedgerSealer(store, nil, time.Hour)
+	sealed, err := sealer.Seal(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, 1, sealed)
+}
+
+func TestLedgerSealerFirstSeal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	first := time.Date(2024, 3, 1, 15, 4, 5, 0, time.UTC)
+	now := time.Date(2024, 3, 3, 12, 0, 0, 0, time.UTC)
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetLastLedgerSeal(gomock.Any()).Times(1).Return(db.LedgerSeal{}, sql.ErrNoRows)
+	store.EXPECT().GetFirstHashedEntryTime(gomock.Any()).Times(1).Return(first, nil)
+	gomock.InOrder(
+		store.EXPECT().SealLedgerDay(gomock.Any(), gomock.Eq(db.LedgerDay(first)), gomock.Any()).Times(1).
+			Return(db.LedgerSeal{}, nil),
+		store.EXPECT().SealLedgerDay(gomock.Any(), gomock.Eq(db.LedgerDay(first).AddDate(0, 0, 1)), gomock.Any()).Times(1).
+			Return(db.LedgerSeal{}, nil),
+	)
+
+	sealer := NewLedgerSealer(store, nil, time.Hour)
+	sealed, err := sealer.Seal(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, 2, sealed)
+}
+
+func TestLedgerSealerNothingToSeal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetLastLedgerSeal(gomock.Any()).Times(1).Return(db.LedgerSeal{}, sql.ErrNoRows)
+	store.EXPECT().GetFirstHashedEntryTime(gomock.Any()).Times(1).Return(time.Time{}, sql.ErrNoRows)
+	store.EXPECT().SealLedgerDay(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	sealer := NewLedgerSealer(store, nil, time.Hour)
+	sealed, err := sealer.Seal(context.Background(), time.Now())
+	require.NoError(t, err)
+	require.Zero(t, sealed)
+}
@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/event"
+)
+
+const (
+	// number of outbox events published per transaction
+	outboxRelayBatchSize = 100
+	// an event that fails to publish this many times is given up on
+	maxOutboxEventAttempts = 10
+	// wait before a failed event is published again, multiplied by the number of failed attempts
+	outboxRetryDelay = time.Minute
+)
+
+// OutboxRelay periodically publishes the domain events written to the outbox
+type OutboxRelay struct {
+	store     db.Store
+	publisher event.EventPublisher
+	interval  time.Duration
+}
+
+// NewOutboxRelay creates a new outbox relay
+func NewOutboxRelay(store db.Store, publisher event.EventPublisher, interval time.Duration) *OutboxRelay {
+	return &OutboxRelay{
+		store:     store,
+		publisher: publisher,
+		interval:  interval,
+	}
+}
+
+// Start relays every interval until ctx is cancelled
+func (relay *OutboxRelay) Start(ctx context.Context) {
+	if relay.interval <= 0 {
+		log.Println("outbox relay disabled, no relay interval configured")
+		return
+	}
+
+	ticker := time.NewTicker(relay.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := relay.Relay(ctx); err != nil {
+				log.Println("cannot publish outbox events: ", err)
+			}
+		}
+	}
+}
+
+// Relay publishes pending events in batches until there are none left and returns how many
+// were published. An event that can't be published is retried on a later relay, after a delay.
+func (relay *OutboxRelay) Relay(ctx context.Context) (int, error) {
+	arg := db.PublishOutboxEventsParams{
+		Limit:       outboxRelayBatchSize,
+		MaxAttempts: maxOutboxEventAttempts,
+		RetryDelay:  outboxRetryDelay,
+	}
+
+	total := 0
+	for {
+		result, err := relay.store.PublishOutboxEvents(ctx, arg, func(outboxEvent db.OutboxEvent) error {
+			err := relay.publisher.Publish(ctx, event.Event{
+				ID:            outboxEvent.ID,
+				Type:          outboxEvent.EventType,
+				AggregateType: outboxEvent.AggregateType,
+				AggregateID:   outboxEvent.AggregateID,
+				Payload:       outboxEvent.Payload,
+				OccurredAt:    outboxEvent.CreatedAt,
+			})
+			if err != nil {
+				log.Printf("cannot publish outbox event %d: %v", outboxEvent.ID, err)
+			}
+			return err
+		})
+		if err != nil {
+			return total, err
+		}
+		total += result.Published
+		if result.Published+result.Failed < outboxRelayBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/malcolmmaima/maimabank/db/mock"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/event"
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// publishEvents stands in for the store, handing every event to publish and counting the failures
+func publishEvents(events []db.OutboxEvent) func(ctx context.Context, arg db.PublishOutboxEventsParams, publish func(db.OutboxEvent) error) (db.PublishOutboxEventsResult, error) {
+	return func(ctx context.Context, arg db.PublishOutboxEventsParams, publish func(db.OutboxEvent) error) (db.PublishOutboxEventsResult, error) {
+		var result db.PublishOutboxEventsResult
+		for _, outboxEvent := range events {
+			if err := publish(outboxEvent); err != nil {
+				result.Failed++
+				continue
+			}
+			result.Published++
+		}
+		return result, nil
+	}
+}
+
+var relayParams = db.PublishOutboxEventsParams{
+	Limit:       outboxRelayBatchSize,
+	MaxAttempts: maxOutboxEventAttempts,
+	RetryDelay:  outboxRetryDelay,
+}
+
+func randomOutboxEvent(id int64) db.OutboxEvent {
+	return db.OutboxEvent{
+		ID:            id,
+		EventType:     util.EventAccountCreated,
+		AggregateType: util.AggregateAccount,
+		AggregateID:   "1",
+		Payload:       json.RawMessage(`{"account_id":1}`),
+		CreatedAt:     time.Now().Truncate(time.Second),
+	}
+}
+
+func TestOutboxRelayRelay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batch := make([]db.OutboxEvent, outboxRelayBatchSize)
+	for i := range batch {
+		batch[i] = randomOutboxEvent(int64(i + 1))
+	}
+	last := randomOutboxEvent(outboxRelayBatchSize + 1)
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().PublishOutboxEvents(gomock.Any(), gomock.Eq(relayParams), gomock.Any()).Times(1).
+			DoAndReturn(publishEvents(batch)),
+		store.EXPECT().PublishOutboxEvents(gomock.Any(), gomock.Eq(relayParams), gomock.Any()).Times(1).
+			DoAndReturn(publishEvents([]db.OutboxEvent{last})),
+	)
+
+	publisher := event.NewMemoryPublisher()
+	relay := NewOutboxRelay(store, publisher, time.Minute)
+	published, err := relay.Relay(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, outboxRelayBatchSize+1, published)
+
+	events := publisher.Events()
+	require.Len(t, events, outboxRelayBatchSize+1)
+	require.Equal(t, event.Event{
+		ID:            last.ID,
+		Type:          last.EventType,
+		AggregateType: last.AggregateType,
+		AggregateID:   last.AggregateID,
+		Payload:       last.Payload,
+		OccurredAt:    last.CreatedAt,
+	}, events[outboxRelayBatchSize])
+}
+
+// failingPublisher fails to publish one event
+type failingPublisher struct {
+	event.MemoryPublisher
+	failID int64
+}
+
+func (publisher *failingPublisher) Publish(ctx context.Context, e event.Event) error {
+	if e.ID == publisher.failID {
+		return errors.New("broker unavailable")
+	}
+	return publisher.MemoryPublisher.Publish(ctx, e)
+}
+
+func TestOutboxRelayRelayFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	events := []db.OutboxEvent{randomOutboxEvent(1), randomOutboxEvent(2), randomOutboxEvent(3)}
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().PublishOutboxEvents(gomock.Any(), gomock.Eq(relayParams), gomock.Any()).Times(1).
+		DoAndReturn(publishEvents(events))
+
+	publisher := &failingPublisher{failID: 2}
+	relay := NewOutboxRelay(store, publisher, time.Minute)
+	published, err := relay.Relay(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, published)
+
+	// the failed event doesn't hold up the rest, it is retried by a later relay
+	require.Len(t, publisher.Events(), 2)
+	require.Equal(t, int64(1), publisher.Events()[0].ID)
+	require.Equal(t, int64(3), publisher.Events()[1].ID)
+}
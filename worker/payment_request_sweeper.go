@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+)
+
+// PaymentRequestSweeper periodically marks payment requests that were never answered as expired
+type PaymentRequestSweeper struct {
+	store    db.Store
+	interval time.Duration
+}
+
+// NewPaymentRequestSweeper creates a new payment request sweeper
+func NewPaymentRequestSweeper(store db.Store, interval time.Duration) *PaymentRequestSweeper {
+	return &PaymentRequestSweeper{
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Start sweeps every interval until ctx is cancelled
+func (sweeper *PaymentRequestSweeper) Start(ctx context.Context) {
+	if sweeper.interval <= 0 {
+		log.Println("payment request sweeper disabled, no sweep interval configured")
+		return
+	}
+
+	ticker := time.NewTicker(sweeper.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := sweeper.Sweep(ctx); err != nil {
+				log.Println("cannot expire payment requests: ", err)
+			}
+		}
+	}
+}
+
+// Sweep expires every pending request past its expiry and returns how many were expired
+func (sweeper *PaymentRequestSweeper) Sweep(ctx context.Context) (int64, error) {
+	return sweeper.store.ExpirePaymentRequests(ctx)
+}
@@ -0,0 +1,24 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/malcolmmaima/maimabank/db/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentRequestSweeperSweep(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ExpirePaymentRequests(gomock.Any()).Times(1).Return(int64(4), nil)
+
+	sweeper := NewPaymentRequestSweeper(store, time.Minute)
+	expired, err := sweeper.Sweep(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, int64(4), expired)
+}
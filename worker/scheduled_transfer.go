@@ -0,0 +1,210 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/notify"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+const (
+	// number of due scheduled transfers run per tick
+	scheduledTransferBatchSize = 100
+	// a run that fails this many times in a row is given up on and the owner is notified
+	maxScheduledTransferAttempts = 3
+	// wait between retries, multiplied by the number of failed attempts
+	scheduledTransferRetryDelay = 15 * time.Minute
+)
+
+// ScheduledTransferRunner periodically executes scheduled transfers that are due.
+// It assumes a single server process runs it.
+type ScheduledTransferRunner struct {
+	store    db.Store
+	notifier notify.Notifier
+	interval time.Duration
+}
+
+// NewScheduledTransferRunner creates a new scheduled transfer runner
+func NewScheduledTransferRunner(store db.Store, notifier notify.Notifier, interval time.Duration) *ScheduledTransferRunner {
+	return &ScheduledTransferRunner{
+		store:    store,
+		notifier: notifier,
+		interval: interval,
+	}
+}
+
+// Start runs due scheduled transfers every interval until ctx is cancelled
+func (runner *ScheduledTransferRunner) Start(ctx context.Context) {
+	if runner.interval <= 0 {
+		log.Println("scheduled transfer runner disabled, no interval configured")
+		return
+	}
+
+	ticker := time.NewTicker(runner.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := runner.RunDue(ctx); err != nil {
+				log.Println("cannot run scheduled transfers: ", err)
+			}
+		}
+	}
+}
+
+// RunDue runs the scheduled transfers that are due and returns how many were attempted
+func (runner *ScheduledTransferRunner) RunDue(ctx context.Context) (int, error) {
+	scheduledTransfers, err := runner.store.ListDueScheduledTransfers(ctx, scheduledTransferBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, scheduledTransfer := range scheduledTransfers {
+		if err := runner.run(ctx, scheduledTransfer, time.Now()); err != nil {
+			return i, err
+		}
+	}
+
+	return len(scheduledTransfers), nil
+}
+
+// run executes a scheduled transfer and moves its schedule on, or records the failure for a retry
+func (runner *ScheduledTransferRunner) run(ctx context.Context, scheduledTransfer db.ScheduledTransfer, now time.Time) error {
+	arg := db.UpdateScheduledTransferRunParams{
+		ID:             scheduledTransfer.ID,
+		NextRunAt:      scheduledTransfer.NextRunAt,
+		Status:         util.ScheduleStatusActive,
+		LastRunAt:      sql.NullTime{Time: now, Valid: true},
+		LastTransferID: scheduledTransfer.LastTransferID,
+	}
+
+	transferArg, err := runner.transferParams(ctx, scheduledTransfer)
+	if err == nil {
+		run := arg
+		runner.advance(&run, scheduledTransfer, now)
+		_, err = runner.store.ExecuteScheduledTransferTx(ctx, db.ExecuteScheduledTransferTxParams{
+			ScheduledTransfer: scheduledTransfer,
+			Transfer:          transferArg,
+			Run:               run,
+		})
+		// a schedule already run, or paused or changed since it was listed, is left as it is
+		if err == nil || errors.Is(err, db.ErrScheduledTransferNotDue) {
+			return nil
+		}
+	}
+
+	arg.LastError = err.Error()
+	arg.FailedAttempts = scheduledTransfer.FailedAttempts + 1
+	if arg.FailedAttempts < maxScheduledTransferAttempts {
+		retryAt := now.Add(time.Duration(arg.FailedAttempts) * scheduledTransferRetryDelay)
+		arg.RetryAt = sql.NullTime{Time: retryAt, Valid: true}
+		_, err = runner.store.UpdateScheduledTransferRun(ctx, arg)
+		return err
+	}
+
+	// out of retries, skip this run and let the owner know
+	arg.FailedAttempts = 0
+	runner.advance(&arg, scheduledTransfer, now)
+	if arg.Status == util.ScheduleStatusCompleted {
+		arg.Status = util.ScheduleStatusFailed
+	}
+
+	message := fmt.Sprintf("scheduled transfer %d of %d from account %d to account %d failed: %s",
+		scheduledTransfer.ID, scheduledTransfer.Amount, scheduledTransfer.FromAccountID, scheduledTransfer.ToAccountID, arg.LastError)
+	if err := runner.notifier.Notify(ctx, scheduledTransfer.Owner, "Scheduled transfer failed", message); err != nil {
+		log.Println("cannot notify scheduled transfer failure: ", err)
+	}
+
+	_, err = runner.store.UpdateScheduledTransferRun(ctx, arg)
+	return err
+}
+
+// advance moves the schedule to its next run after now, or completes a schedule that doesn't repeat.
+// Runs missed while the server was down are skipped rather than all made at once.
+func (runner *ScheduledTransferRunner) advance(arg *db.UpdateScheduledTransferRunParams, scheduledTransfer db.ScheduledTransfer, now time.Time) {
+	next, ok := util.NextRun(scheduledTransfer.Frequency, int(scheduledTransfer.DayOfMonth), scheduledTransfer.NextRunAt)
+	for ok && !next.After(now) {
+		next, ok = util.NextRun(scheduledTransfer.Frequency, int(scheduledTransfer.DayOfMonth), next)
+	}
+
+	arg.RetryAt = sql.NullTime{}
+	if !ok {
+		arg.Status = util.ScheduleStatusCompleted
+		return
+	}
+	arg.NextRunAt = next
+}
+
+// transferParams works out the transfer to make with the same checks as a transfer made through
+// the api, the limits and available balance are checked when it is made
+func (runner *ScheduledTransferRunner) transferParams(ctx context.Context, scheduledTransfer db.ScheduledTransfer) (db.TransferTxParams, error) {
+	var result db.TransferTxParams
+
+	fromAccount, err := runner.store.GetAccount(ctx, scheduledTransfer.FromAccountID)
+	if err != nil {
+		return result, err
+	}
+	if err := runner.authorize(ctx, fromAccount, scheduledTransfer.Owner, scheduledTransfer.Amount); err != nil {
+		return result, err
+	}
+
+	toAccount, err := runner.store.GetAccount(ctx, scheduledTransfer.ToAccountID)
+	if err != nil {
+		return result, err
+	}
+	if toAccount.Currency != fromAccount.Currency {
+		return result, fmt.Errorf("account [%d] currency mismatch, expecting %s instead of %s", toAccount.ID, toAccount.Currency, fromAccount.Currency)
+	}
+
+	quote, err := runner.store.QuoteFee(ctx, db.QuoteFeeParams{
+		AccountType:  fromAccount.AccountType,
+		TransferType: util.TransferTypeTransfer,
+		Currency:     fromAccount.Currency,
+		Amount:       scheduledTransfer.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	return db.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        scheduledTransfer.Amount,
+		Fee:           quote.Fee,
+		FeeAccountID:  quote.FeeAccountID,
+		LimitUsername: scheduledTransfer.Owner,
+	}, nil
+}
+
+// authorize makes sure username can still pay amount out of account the way the api checks it,
+// as its owner or as a member of a joint account whose role and payment limit allow it
+func (runner *ScheduledTransferRunner) authorize(ctx context.Context, account db.Account, username string, amount int64) error {
+	member, err := db.AccountMembership(ctx, runner.store, account, username)
+	if err != nil {
+		return err
+	}
+
+	if member.Role == "" {
+		return fmt.Errorf("account [%d] no longer belongs to %s", account.ID, username)
+	}
+	if !util.AccountRoleAllows(member.Role, util.AccountPermissionPay) {
+		return fmt.Errorf("a %s of account [%d] doesn't have %s access", member.Role, account.ID, util.AccountPermissionPay)
+	}
+	if account.OrganizationID.Valid {
+		return fmt.Errorf("account [%d] is a business account, its payments go through the organization", account.ID)
+	}
+	if member.PaymentLimit > 0 && amount > member.PaymentLimit {
+		return fmt.Errorf("amount exceeds the payment limit of %d %s on account [%d]", member.PaymentLimit, account.Currency, account.ID)
+	}
+
+	return nil
+}
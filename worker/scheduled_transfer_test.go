@@ -0,0 +1,238 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/malcolmmaima/maimabank/db/mock"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingNotifier struct {
+	usernames []string
+}
+
+func (notifier *recordingNotifier) Notify(ctx context.Context, username string, subject string, message string) error {
+	notifier.usernames = append(notifier.usernames, username)
+	return nil
+}
+
+func randomScheduledTransfer(frequency string, nextRunAt time.Time) (db.ScheduledTransfer, db.Account, db.Account) {
+	owner := util.RandomOwner()
+	fromAccount := db.Account{ID: 1, Owner: owner, Currency: util.USD, Balance: 1000, AvailableBalance: 1000}
+	toAccount := db.Account{ID: 2, Owner: util.RandomOwner(), Currency: util.USD}
+
+	scheduledTransfer := db.ScheduledTransfer{
+		ID:            util.RandomInt(1, 1000),
+		Owner:         owner,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        100,
+		Frequency:     frequency,
+		DayOfMonth:    int32(nextRunAt.Day()),
+		NextRunAt:     nextRunAt,
+		Status:        util.ScheduleStatusActive,
+	}
+	return scheduledTransfer, fromAccount, toAccount
+}
+
+func TestScheduledTransferRunnerSuccess(t *testing.T) {
+	now := time.Now()
+	scheduledTransfer, fromAccount, toAccount := randomScheduledTransfer(util.FrequencyMonthly, now.Add(-time.Minute))
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	store.EXPECT().QuoteFee(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeQuote{}, nil)
+
+	transferArg := db.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        scheduledTransfer.Amount,
+		LimitUsername: scheduledTransfer.Owner,
+	}
+
+	// the transfer and the move to the next run are made together
+	next, _ := util.NextRun(util.FrequencyMonthly, int(scheduledTransfer.DayOfMonth), scheduledTransfer.NextRunAt)
+	arg := db.ExecuteScheduledTransferTxParams{
+		ScheduledTransfer: scheduledTransfer,
+		Transfer:          transferArg,
+		Run: db.UpdateScheduledTransferRunParams{
+			ID:        scheduledTransfer.ID,
+			NextRunAt: next,
+			Status:    util.ScheduleStatusActive,
+			LastRunAt: sql.NullTime{Time: now, Valid: true},
+		},
+	}
+	store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+	store.EXPECT().UpdateScheduledTransferRun(gomock.Any(), gomock.Any()).Times(0)
+
+	runner := NewScheduledTransferRunner(store, &recordingNotifier{}, time.Minute)
+	err := runner.run(context.Background(), scheduledTransfer, now)
+	require.NoError(t, err)
+}
+
+func TestScheduledTransferRunnerRetry(t *testing.T) {
+	now := time.Now()
+	scheduledTransfer, fromAccount, toAccount := randomScheduledTransfer(util.FrequencyOnce, now.Add(-time.Minute))
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	store.EXPECT().QuoteFee(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeQuote{}, nil)
+	store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).Times(1).
+		Return(db.ExecuteScheduledTransferTxResult{}, db.ErrInsufficientAvailableBalance)
+
+	arg := db.UpdateScheduledTransferRunParams{
+		ID:             scheduledTransfer.ID,
+		NextRunAt:      scheduledTransfer.NextRunAt,
+		RetryAt:        sql.NullTime{Time: now.Add(scheduledTransferRetryDelay), Valid: true},
+		Status:         util.ScheduleStatusActive,
+		FailedAttempts: 1,
+		LastError:      db.ErrInsufficientAvailableBalance.Error(),
+		LastRunAt:      sql.NullTime{Time: now, Valid: true},
+	}
+	store.EXPECT().UpdateScheduledTransferRun(gomock.Any(), gomock.Eq(arg)).Times(1)
+
+	notifier := &recordingNotifier{}
+	runner := NewScheduledTransferRunner(store, notifier, time.Minute)
+	err := runner.run(context.Background(), scheduledTransfer, now)
+	require.NoError(t, err)
+	require.Empty(t, notifier.usernames)
+}
+
+func TestScheduledTransferRunnerGivesUp(t *testing.T) {
+	now := time.Now()
+	scheduledTransfer, fromAccount, _ := randomScheduledTransfer(util.FrequencyOnce, now.Add(-time.Hour))
+	scheduledTransfer.FailedAttempts = maxScheduledTransferAttempts - 1
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+
+	arg := db.UpdateScheduledTransferRunParams{
+		ID:        scheduledTransfer.ID,
+		NextRunAt: scheduledTransfer.NextRunAt,
+		Status:    util.ScheduleStatusFailed,
+		LastError: sql.ErrNoRows.Error(),
+		LastRunAt: sql.NullTime{Time: now, Valid: true},
+	}
+	store.EXPECT().UpdateScheduledTransferRun(gomock.Any(), gomock.Eq(arg)).Times(1)
+
+	notifier := &recordingNotifier{}
+	runner := NewScheduledTransferRunner(store, notifier, time.Minute)
+	err := runner.run(context.Background(), scheduledTransfer, now)
+	require.NoError(t, err)
+	require.Equal(t, []string{scheduledTransfer.Owner}, notifier.usernames)
+}
+
+func TestScheduledTransferRunnerJointAccountMember(t *testing.T) {
+	now := time.Now()
+	scheduledTransfer, fromAccount, toAccount := randomScheduledTransfer(util.FrequencyOnce, now.Add(-time.Minute))
+	// the schedule was made by a payer of someone else's account
+	fromAccount.Owner = util.RandomOwner()
+	member := db.AccountMember{
+		AccountID:    fromAccount.ID,
+		Username:     scheduledTransfer.Owner,
+		Role:         util.AccountRolePayer,
+		PaymentLimit: scheduledTransfer.Amount,
+		AcceptedAt:   sql.NullTime{Time: now.Add(-time.Hour), Valid: true},
+	}
+
+	testCases := []struct {
+		name      string
+		role      string
+		transfers int
+		lastError string
+	}{
+		{
+			name:      "Payer",
+			role:      util.AccountRolePayer,
+			transfers: 1,
+		},
+		{
+			name:      "Viewer",
+			role:      util.AccountRoleViewer,
+			lastError: "a viewer of account [1] doesn't have pay access",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			member.Role = tc.role
+			memberArg := db.GetAccountMemberParams{
+				AccountID: fromAccount.ID,
+				Username:  scheduledTransfer.Owner,
+			}
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(memberArg)).Times(1).Return(member, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(tc.transfers).Return(toAccount, nil)
+			store.EXPECT().QuoteFee(gomock.Any(), gomock.Any()).Times(tc.transfers).Return(db.FeeQuote{}, nil)
+
+			transferArg := db.TransferTxParams{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        scheduledTransfer.Amount,
+				LimitUsername: scheduledTransfer.Owner,
+			}
+			store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).Times(tc.transfers).
+				DoAndReturn(func(_ interface{}, arg db.ExecuteScheduledTransferTxParams) (db.ExecuteScheduledTransferTxResult, error) {
+					require.Equal(t, transferArg, arg.Transfer)
+					return db.ExecuteScheduledTransferTxResult{}, nil
+				})
+			store.EXPECT().UpdateScheduledTransferRun(gomock.Any(), gomock.Any()).Times(1 - tc.transfers).
+				DoAndReturn(func(_ interface{}, arg db.UpdateScheduledTransferRunParams) (db.ScheduledTransfer, error) {
+					require.Equal(t, tc.lastError, arg.LastError)
+					return db.ScheduledTransfer{}, nil
+				})
+
+			runner := NewScheduledTransferRunner(store, &recordingNotifier{}, time.Minute)
+			err := runner.run(context.Background(), scheduledTransfer, now)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestScheduledTransferRunnerAlreadyRun(t *testing.T) {
+	now := time.Now()
+	scheduledTransfer, fromAccount, toAccount := randomScheduledTransfer(util.FrequencyOnce, now.Add(-time.Minute))
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	store.EXPECT().QuoteFee(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeQuote{}, nil)
+	store.EXPECT().ExecuteScheduledTransferTx(gomock.Any(), gomock.Any()).Times(1).
+		Return(db.ExecuteScheduledTransferTxResult{}, db.ErrScheduledTransferNotDue)
+
+	// the run that got there first has already moved the schedule on
+	store.EXPECT().UpdateScheduledTransferRun(gomock.Any(), gomock.Any()).Times(0)
+
+	notifier := &recordingNotifier{}
+	runner := NewScheduledTransferRunner(store, notifier, time.Minute)
+	err := runner.run(context.Background(), scheduledTransfer, now)
+	require.NoError(t, err)
+	require.Empty(t, notifier.usernames)
+}
@@ -0,0 +1,250 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/notify"
+	"github.com/malcolmmaima/maimabank/util"
+)
+
+// a batch left processing this long, by a processor that stopped or crashed, is claimed again
+const transferBatchClaimTimeout = 10 * time.Minute
+
+// TransferBatchProcessor periodically executes transfer batches waiting to be processed
+type TransferBatchProcessor struct {
+	store    db.Store
+	notifier notify.Notifier
+	interval time.Duration
+}
+
+// NewTransferBatchProcessor creates a new transfer batch processor
+func NewTransferBatchProcessor(store db.Store, notifier notify.Notifier, interval time.Duration) *TransferBatchProcessor {
+	return &TransferBatchProcessor{
+		store:    store,
+		notifier: notifier,
+		interval: interval,
+	}
+}
+
+// Start processes pending batches every interval until ctx is cancelled
+func (processor *TransferBatchProcessor) Start(ctx context.Context) {
+	if processor.interval <= 0 {
+		log.Println("transfer batch processor disabled, no interval configured")
+		return
+	}
+
+	ticker := time.NewTicker(processor.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := processor.ProcessPending(ctx); err != nil {
+				log.Println("cannot process transfer batches: ", err)
+			}
+		}
+	}
+}
+
+// ProcessPending claims and processes batches one at a time until none are pending
+// and returns how many were processed
+func (processor *TransferBatchProcessor) ProcessPending(ctx context.Context) (int, error) {
+	processed := 0
+	for {
+		batch, err := processor.store.ClaimTransferBatch(ctx, time.Now().Add(-transferBatchClaimTimeout))
+		if errors.Is(err, sql.ErrNoRows) {
+			return processed, nil
+		}
+		if err != nil {
+			return processed, err
+		}
+
+		batch, err = processor.process(ctx, batch)
+		if errors.Is(err, db.ErrTransferBatchReclaimed) {
+			log.Printf("transfer batch %d was claimed by another processor", batch.ID)
+			continue
+		}
+		if err != nil {
+			return processed, err
+		}
+		processed++
+
+		message := fmt.Sprintf("transfer batch %d is %s: %d succeeded, %d failed",
+			batch.ID, batch.Status, batch.SucceededCount, batch.FailedCount)
+		if err := processor.notifier.Notify(ctx, batch.Owner, "Transfer batch finished", message); err != nil {
+			log.Println("cannot notify transfer batch result: ", err)
+		}
+	}
+}
+
+func (processor *TransferBatchProcessor) process(ctx context.Context, batch db.TransferBatch) (db.TransferBatch, error) {
+	items, err := processor.store.ListTransferBatchItems(ctx, batch.ID)
+	if err != nil {
+		return batch, err
+	}
+
+	fromAccount, err := processor.store.GetAccount(ctx, batch.FromAccountID)
+	if err != nil {
+		return batch, err
+	}
+
+	if batch.Mode == util.BatchModeAtomic {
+		return processor.processAtomic(ctx, batch, fromAccount, items)
+	}
+	return processor.processBestEffort(ctx, batch, fromAccount, items)
+}
+
+// processAtomic makes every transfer of the batch in one transaction, or fails the whole batch
+func (processor *TransferBatchProcessor) processAtomic(ctx context.Context, batch db.TransferBatch, fromAccount db.Account, items []db.TransferBatchItem) (db.TransferBatch, error) {
+	transfers := make([]db.BatchTransfer, 0, len(items))
+	for _, item := range items {
+		arg, err := processor.transferParams(ctx, batch.Owner, fromAccount, item)
+		if err != nil {
+			return processor.failAtomic(ctx, batch, &db.TransferBatchItemError{ItemID: item.ID, Err: err})
+		}
+		transfers = append(transfers, db.BatchTransfer{ItemID: item.ID, Transfer: arg})
+	}
+
+	finished, err := processor.store.ExecuteAtomicTransferBatchTx(ctx, batch, transfers)
+	if errors.Is(err, db.ErrTransferBatchReclaimed) {
+		return batch, err
+	}
+	if err != nil {
+		return processor.failAtomic(ctx, batch, err)
+	}
+	return finished, nil
+}
+
+// failAtomic marks an atomic batch as failed, and the item that stopped it if there was one
+func (processor *TransferBatchProcessor) failAtomic(ctx context.Context, batch db.TransferBatch, cause error) (db.TransferBatch, error) {
+	failedCount := int32(0)
+
+	var itemErr *db.TransferBatchItemError
+	if errors.As(cause, &itemErr) {
+		_, err := processor.store.UpdateTransferBatchItem(ctx, db.UpdateTransferBatchItemParams{
+			ID:     itemErr.ItemID,
+			Status: util.BatchItemStatusFailed,
+			Error:  itemErr.Err.Error(),
+		})
+		if err != nil {
+			return batch, err
+		}
+		failedCount = 1
+	}
+
+	return processor.finish(ctx, batch, db.FinishTransferBatchParams{
+		ID:          batch.ID,
+		Status:      util.BatchStatusFailed,
+		FailedCount: failedCount,
+		Error:       cause.Error(),
+	})
+}
+
+// processBestEffort makes each transfer on its own and records which ones failed. Items already
+// done by a processor that held the batch before are counted as they are.
+func (processor *TransferBatchProcessor) processBestEffort(ctx context.Context, batch db.TransferBatch, fromAccount db.Account, items []db.TransferBatchItem) (db.TransferBatch, error) {
+	var succeeded, failed int32
+
+	for _, item := range items {
+		switch item.Status {
+		case util.BatchItemStatusSucceeded:
+			succeeded++
+			continue
+		case util.BatchItemStatusFailed:
+			failed++
+			continue
+		}
+
+		err := processor.transfer(ctx, batch, fromAccount, item)
+		if errors.Is(err, db.ErrTransferBatchReclaimed) {
+			return batch, err
+		}
+		if err == nil {
+			succeeded++
+			continue
+		}
+
+		_, err = processor.store.UpdateTransferBatchItem(ctx, db.UpdateTransferBatchItemParams{
+			ID:     item.ID,
+			Status: util.BatchItemStatusFailed,
+			Error:  err.Error(),
+		})
+		if err != nil {
+			return batch, err
+		}
+		failed++
+	}
+
+	status := util.BatchStatusPartiallyCompleted
+	switch {
+	case failed == 0:
+		status = util.BatchStatusCompleted
+	case succeeded == 0:
+		status = util.BatchStatusFailed
+	}
+
+	return processor.finish(ctx, batch, db.FinishTransferBatchParams{
+		ID:             batch.ID,
+		Status:         status,
+		SucceededCount: succeeded,
+		FailedCount:    failed,
+	})
+}
+
+// transfer makes the transfer for one item of a best effort batch, with the balance checked
+// and the item marked succeeded in the same transaction
+func (processor *TransferBatchProcessor) transfer(ctx context.Context, batch db.TransferBatch, fromAccount db.Account, item db.TransferBatchItem) error {
+	arg, err := processor.transferParams(ctx, batch.Owner, fromAccount, item)
+	if err != nil {
+		return err
+	}
+
+	_, err = processor.store.ExecuteTransferBatchItemTx(ctx, batch, db.BatchTransfer{ItemID: item.ID, Transfer: arg})
+	var itemErr *db.TransferBatchItemError
+	if errors.As(err, &itemErr) {
+		return itemErr.Err
+	}
+	return err
+}
+
+// finish records the outcome of a batch, as long as it is still held under this processor's claim
+func (processor *TransferBatchProcessor) finish(ctx context.Context, batch db.TransferBatch, arg db.FinishTransferBatchParams) (db.TransferBatch, error) {
+	arg.ClaimedAt = batch.ClaimedAt
+	finished, err := processor.store.FinishTransferBatch(ctx, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return batch, db.ErrTransferBatchReclaimed
+	}
+	return finished, err
+}
+
+// transferParams quotes the fee of a batch item and works out the transfer to make for it,
+// counted against the limits of the batch's owner
+func (processor *TransferBatchProcessor) transferParams(ctx context.Context, owner string, fromAccount db.Account, item db.TransferBatchItem) (db.TransferTxParams, error) {
+	quote, err := processor.store.QuoteFee(ctx, db.QuoteFeeParams{
+		AccountType:  fromAccount.AccountType,
+		TransferType: util.TransferTypeTransfer,
+		Currency:     fromAccount.Currency,
+		Amount:       item.Amount,
+	})
+	if err != nil {
+		return db.TransferTxParams{}, err
+	}
+
+	return db.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   item.ToAccountID,
+		Amount:        item.Amount,
+		Fee:           quote.Fee,
+		FeeAccountID:  quote.FeeAccountID,
+		Reference:     item.Reference,
+		LimitUsername: owner,
+	}, nil
+}
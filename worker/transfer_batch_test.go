@@ -0,0 +1,143 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/malcolmmaima/maimabank/db/mock"
+	db "github.com/malcolmmaima/maimabank/db/sqlc"
+	"github.com/malcolmmaima/maimabank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomTransferBatch(mode string) (db.TransferBatch, db.Account, []db.TransferBatchItem) {
+	fromAccount := db.Account{ID: 1, Owner: util.RandomOwner(), Currency: util.USD, Balance: 120, AvailableBalance: 120}
+	batch := db.TransferBatch{
+		ID:            util.RandomInt(1, 1000),
+		Owner:         fromAccount.Owner,
+		FromAccountID: fromAccount.ID,
+		Mode:          mode,
+		Status:        util.BatchStatusProcessing,
+		ItemCount:     2,
+		ClaimedAt:     sql.NullTime{Time: time.Now(), Valid: true},
+	}
+	items := []db.TransferBatchItem{
+		{ID: 11, BatchID: batch.ID, LineNumber: 1, ToAccountID: 2, Amount: 100, Currency: util.USD, Status: util.BatchItemStatusPending},
+		{ID: 12, BatchID: batch.ID, LineNumber: 2, ToAccountID: 3, Amount: 50, Currency: util.USD, Status: util.BatchItemStatusPending},
+	}
+	return batch, fromAccount, items
+}
+
+func TestTransferBatchProcessorBestEffort(t *testing.T) {
+	batch, fromAccount, items := randomTransferBatch(util.BatchModeBestEffort)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().ClaimTransferBatch(gomock.Any(), gomock.Any()).Times(1).Return(batch, nil),
+		store.EXPECT().ClaimTransferBatch(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferBatch{}, sql.ErrNoRows),
+	)
+	store.EXPECT().ListTransferBatchItems(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(items, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().QuoteFee(gomock.Any(), gomock.Any()).Times(2).Return(db.FeeQuote{}, nil)
+
+	// the first transfer leaves too little for the second one
+	store.EXPECT().ExecuteTransferBatchItemTx(gomock.Any(), gomock.Eq(batch), gomock.Eq(db.BatchTransfer{
+		ItemID:   11,
+		Transfer: db.TransferTxParams{FromAccountID: 1, ToAccountID: 2, Amount: 100, LimitUsername: batch.Owner},
+	})).Times(1).Return(db.TransferTxResult{Transfer: db.Transfer{ID: 7}}, nil)
+	store.EXPECT().ExecuteTransferBatchItemTx(gomock.Any(), gomock.Eq(batch), gomock.Eq(db.BatchTransfer{
+		ItemID:   12,
+		Transfer: db.TransferTxParams{FromAccountID: 1, ToAccountID: 3, Amount: 50, LimitUsername: batch.Owner},
+	})).Times(1).Return(db.TransferTxResult{}, &db.TransferBatchItemError{ItemID: 12, Err: db.ErrInsufficientAvailableBalance})
+	store.EXPECT().UpdateTransferBatchItem(gomock.Any(), gomock.Eq(db.UpdateTransferBatchItemParams{
+		ID:     12,
+		Status: util.BatchItemStatusFailed,
+		Error:  db.ErrInsufficientAvailableBalance.Error(),
+	})).Times(1)
+
+	finished := batch
+	finished.Status = util.BatchStatusPartiallyCompleted
+	store.EXPECT().FinishTransferBatch(gomock.Any(), gomock.Eq(db.FinishTransferBatchParams{
+		ID:             batch.ID,
+		Status:         util.BatchStatusPartiallyCompleted,
+		SucceededCount: 1,
+		FailedCount:    1,
+		ClaimedAt:      batch.ClaimedAt,
+	})).Times(1).Return(finished, nil)
+
+	notifier := &recordingNotifier{}
+	processor := NewTransferBatchProcessor(store, notifier, time.Minute)
+	processed, err := processor.ProcessPending(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, processed)
+	require.Equal(t, []string{batch.Owner}, notifier.usernames)
+}
+
+func TestTransferBatchProcessorAtomicFailure(t *testing.T) {
+	batch, fromAccount, items := randomTransferBatch(util.BatchModeAtomic)
+	fromAccount.AvailableBalance = 1000
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListTransferBatchItems(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(items, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().QuoteFee(gomock.Any(), gomock.Any()).Times(2).Return(db.FeeQuote{}, nil)
+
+	itemErr := &db.TransferBatchItemError{ItemID: 12, Err: sql.ErrConnDone}
+	store.EXPECT().ExecuteAtomicTransferBatchTx(gomock.Any(), gomock.Eq(batch), gomock.Len(2)).Times(1).Return(db.TransferBatch{}, itemErr)
+	store.EXPECT().UpdateTransferBatchItem(gomock.Any(), gomock.Eq(db.UpdateTransferBatchItemParams{
+		ID:     12,
+		Status: util.BatchItemStatusFailed,
+		Error:  sql.ErrConnDone.Error(),
+	})).Times(1)
+	store.EXPECT().FinishTransferBatch(gomock.Any(), gomock.Eq(db.FinishTransferBatchParams{
+		ID:          batch.ID,
+		Status:      util.BatchStatusFailed,
+		FailedCount: 1,
+		Error:       itemErr.Error(),
+		ClaimedAt:   batch.ClaimedAt,
+	})).Times(1)
+
+	processor := NewTransferBatchProcessor(store, &recordingNotifier{}, time.Minute)
+	_, err := processor.process(context.Background(), batch)
+	require.NoError(t, err)
+}
+
+func TestTransferBatchProcessorReclaimed(t *testing.T) {
+	batch, fromAccount, items := randomTransferBatch(util.BatchModeBestEffort)
+	// the processor that held the batch before made the first transfer
+	items[0].Status = util.BatchItemStatusSucceeded
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().ClaimTransferBatch(gomock.Any(), gomock.Any()).Times(1).Return(batch, nil),
+		store.EXPECT().ClaimTransferBatch(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferBatch{}, sql.ErrNoRows),
+	)
+	store.EXPECT().ListTransferBatchItems(gomock.Any(), gomock.Eq(batch.ID)).Times(1).Return(items, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().QuoteFee(gomock.Any(), gomock.Any()).Times(1).Return(db.FeeQuote{}, nil)
+
+	// another processor claimed the batch while this one was working on it
+	store.EXPECT().ExecuteTransferBatchItemTx(gomock.Any(), gomock.Eq(batch), gomock.Any()).Times(1).
+		Return(db.TransferTxResult{}, db.ErrTransferBatchReclaimed)
+	store.EXPECT().UpdateTransferBatchItem(gomock.Any(), gomock.Any()).Times(0)
+	store.EXPECT().FinishTransferBatch(gomock.Any(), gomock.Any()).Times(0)
+
+	notifier := &recordingNotifier{}
+	processor := NewTransferBatchProcessor(store, notifier, time.Minute)
+	processed, err := processor.ProcessPending(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, processed)
+	require.Empty(t, notifier.usernames)
+}